@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/config"
+)
+
+// vencerAutoevaluacionesInactivas se ejecuta en background y, cada hora, avisa por email a
+// las bodegas con autoevaluaciones pendientes sin actividad y cancela las abandonadas
+func vencerAutoevaluacionesInactivas(autoevaluacionService *service.AutoevaluacionService, cfg config.AutoevaluacionesConfig) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		resultado, err := autoevaluacionService.VencerInactivas(context.Background(), time.Now(),
+			cfg.DiasAvisoInactividad, cfg.DiasCancelacionInactividad, sendAvisoInactividadEmail)
+		if err != nil {
+			log.Printf("Error procesando autoevaluaciones inactivas: %v", err)
+			continue
+		}
+		if resultado.Avisadas > 0 || resultado.Canceladas > 0 {
+			log.Printf("Autoevaluaciones inactivas: %d avisadas, %d canceladas", resultado.Avisadas, resultado.Canceladas)
+		}
+	}
+}
+
+func sendAvisoInactividadEmail(ctx context.Context, auto *domain.AutoevaluacionInactiva, cancelacion time.Time) error {
+	frontendURL := getEnvDefault("FRONTEND_URL", "http://localhost:3000")
+
+	body := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+			<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+				<h2>Autoevaluación pendiente sin actividad</h2>
+				<p>La autoevaluación de <strong>%s</strong> iniciada el %s no registra cambios desde el %s.</p>
+				<p>Si no se continúa, se cancelará automáticamente el <strong>%s</strong> y deberá iniciarse una nueva.</p>
+				<p><a href="%s">Continuar la autoevaluación</a></p>
+			</div>
+		</body>
+		</html>
+	`, html.EscapeString(auto.Bodega), auto.FechaInicio.Format("02/01/2006"), auto.UltimaActividad.Format("02/01/2006"),
+		cancelacion.Format("02/01/2006"), frontendURL)
+
+	if err := sendEmail(auto.Email, "Autoevaluación pendiente sin actividad", body); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Aviso de inactividad enviado a %s (autoevaluación %d)", auto.Email, auto.ID)
+	return nil
+}
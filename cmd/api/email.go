@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"net/smtp"
+	"os"
+)
+
+// sendEmail envía un email HTML con la cuenta SMTP configurada
+func sendEmail(to, subject, htmlBody string) error {
+	smtpHost := getEnvDefault("SMTP_HOST", "smtp.gmail.com")
+	smtpUser := os.Getenv("SMTP_USER")
+	smtpPass := os.Getenv("SMTP_PASSWORD")
+	smtpPort := getEnvDefault("SMTP_PORT", "587")
+
+	if smtpUser == "" || smtpPass == "" {
+		return fmt.Errorf("configuración SMTP incompleta: SMTP_USER y SMTP_PASSWORD son requeridos")
+	}
+
+	header := "Subject: " + subject + "\r\n"
+	mime := "MIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n"
+
+	message := []byte(header + mime + htmlBody)
+	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
+	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
+
+	if err := smtp.SendMail(addr, auth, smtpUser, []string{to}, message); err != nil {
+		return fmt.Errorf("error enviando email: %v", err)
+	}
+	return nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 
+	"coviar_backend/internal/domain"
 	"coviar_backend/internal/handler"
 	"coviar_backend/internal/middleware"
 	"coviar_backend/internal/repository/postgres"
@@ -43,28 +45,59 @@ func main() {
 	respuestaRepo := postgres.NewRespuestaRepository(db.DB)
 	txManager := postgres.NewTransactionManager(db.DB)
 	evidenciaRepo := postgres.NewEvidenciaRepository(db.DB)
+	resultadoCapituloRepo := postgres.NewResultadoCapituloRepository(db.DB)
+	guiaVersionRepo := postgres.NewGuiaVersionRepository(db.DB)
+	enmiendaRepo := postgres.NewEnmiendaRepository(db.DB)
+	auditorRepo := postgres.NewAuditorRepository(db.DB)
+	revisionRepo := postgres.NewRevisionRepository(db.DB)
+	accionMejoraRepo := postgres.NewAccionMejoraRepository(db.DB)
 
 	log.Println("✓ Repositorios inicializados")
 
 	// 4. Inicializar servicios
 	registroService := service.NewRegistroService(bodegaRepo, cuentaRepo, responsableRepo, txManager)
 	ubicacionService := service.NewUbicacionService(ubicacionRepo)
-	cuentaService := service.NewCuentaService(cuentaRepo, bodegaRepo)
-	bodegaService := service.NewBodegaService(bodegaRepo)
+	importacionUbicacionesService := service.NewImportacionUbicacionesService(ubicacionRepo, txManager, ubicacionService)
+	cuentaService := service.NewCuentaService(cuentaRepo, bodegaRepo, ubicacionService)
 	responsableService := service.NewResponsableService(responsableRepo, cuentaRepo, autoevaluacionRepo)
-	autoevaluacionService := service.NewAutoevaluacionService(autoevaluacionRepo, segmentoRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, respuestaRepo, evidenciaRepo)
+	estructuraCache := service.NewEstructuraCache()
+	politicaVigencia := service.PoliticaVigencia{MesesPorDefecto: cfg.Autoevaluaciones.MesesVigencia, DiasRecordatorio: cfg.Autoevaluaciones.DiasRecordatorioVigencia}
+	autoevaluacionService := service.NewAutoevaluacionService(autoevaluacionRepo, segmentoRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, respuestaRepo, evidenciaRepo, resultadoCapituloRepo, guiaVersionRepo, enmiendaRepo, txManager, estructuraCache, politicaVigencia)
+	bodegaService := service.NewBodegaService(bodegaRepo, ubicacionService, autoevaluacionService)
+	guiaVersionService := service.NewGuiaVersionService(guiaVersionRepo, capituloRepo, txManager)
+	guiaEdicionService := service.NewGuiaEdicionService(guiaVersionRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, segmentoRepo, txManager, estructuraCache)
+	guiaDocumentoService := service.NewGuiaDocumentoService(guiaEdicionService, guiaVersionRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, segmentoRepo, txManager)
+	reporteService := service.NewReporteService(autoevaluacionService, bodegaService)
 	evidenciaService := service.NewEvidenciaService(evidenciaRepo, respuestaRepo, autoevaluacionRepo, bodegaRepo, indicadorRepo)
+	auditorService := service.NewAuditorService(cuentaRepo, auditorRepo, bodegaRepo, txManager)
+	revisionService := service.NewRevisionService(revisionRepo, auditorRepo, autoevaluacionRepo, respuestaRepo, resultadoCapituloRepo, segmentoRepo, guiaVersionRepo)
+	planAccionService := service.NewPlanAccionService(accionMejoraRepo, bodegaRepo, indicadorRepo, nivelRespuestaRepo, responsableRepo, cuentaRepo, autoevaluacionRepo, autoevaluacionService)
 
 	log.Println("✓ Servicios inicializados")
 
+	// Precargar el catálogo de ubicaciones (si falla, se reintenta en la primera consulta)
+	if err := ubicacionService.Recargar(context.Background()); err != nil {
+		log.Printf("⚠️  No se pudo precargar el catálogo de ubicaciones: %v", err)
+	} else {
+		log.Println("✓ Catálogo de ubicaciones en memoria")
+	}
+
 	// 5. Inicializar handlers (con JWT secret para autenticación)
 	registroHandler := handler.NewRegistroHandler(registroService)
 	ubicacionHandler := handler.NewUbicacionHandler(ubicacionService)
+	importacionUbicacionesHandler := handler.NewImportacionUbicacionesHandler(importacionUbicacionesService, ubicacionService)
 	cuentaHandler := handler.NewCuentaHandler(cuentaService, cfg.JWT.Secret)
 	bodegaHandler := handler.NewBodegaHandler(bodegaService)
 	responsableHandler := handler.NewResponsableHandler(responsableService)
 	autoevaluacionHandler := handler.NewAutoevaluacionHandler(autoevaluacionService)
 	evidenciaHandler := handler.NewEvidenciaHandler(evidenciaService)
+	reporteHandler := handler.NewReporteHandler(reporteService)
+	guiaVersionHandler := handler.NewGuiaVersionHandler(guiaVersionService)
+	guiaEdicionHandler := handler.NewGuiaEdicionHandler(guiaEdicionService)
+	guiaDocumentoHandler := handler.NewGuiaDocumentoHandler(guiaDocumentoService)
+	auditorHandler := handler.NewAuditorHandler(auditorService)
+	revisionHandler := handler.NewRevisionHandler(revisionService)
+	planAccionHandler := handler.NewPlanAccionHandler(planAccionService)
 
 	log.Println("✓ Handlers inicializados")
 
@@ -116,6 +149,12 @@ func main() {
 	r.GET("/api/provincias", ubicacionHandler.GetProvincias)
 	r.GET("/api/departamentos", ubicacionHandler.GetDepartamentos)
 	r.GET("/api/localidades", ubicacionHandler.GetLocalidades)
+	r.GET("/api/localidades/buscar", ubicacionHandler.BuscarLocalidades)
+	r.GET("/api/provincias/{id}", ubicacionHandler.GetProvinciaByID)
+	r.GET("/api/provincias/{id}/departamentos", ubicacionHandler.GetDepartamentosByProvincia)
+	r.GET("/api/departamentos/{id}", ubicacionHandler.GetDepartamentoByID)
+	r.GET("/api/departamentos/{id}/localidades", ubicacionHandler.GetLocalidadesByDepartamento)
+	r.GET("/api/localidades/{id}", ubicacionHandler.GetLocalidadByID)
 
 	// Recuperación de contraseña (públicas)
 	r.POST("/api/recuperar-password", RequestPasswordReset(db.DB))
@@ -124,6 +163,12 @@ func main() {
 	// Iniciar limpieza de tokens expirados en background
 	go cleanExpiredTokens(db.DB)
 
+	// Avisar y cancelar autoevaluaciones pendientes abandonadas
+	go vencerAutoevaluacionesInactivas(autoevaluacionService, cfg.Autoevaluaciones)
+
+	// Recordar a las bodegas que reevalúen antes de que venza su nivel
+	go recordarVigencias(autoevaluacionService)
+
 	// Health check
 	r.GET("/health", func(w http.ResponseWriter, r *http.Request) {
 		httputil.RespondJSON(w, http.StatusOK, map[string]string{
@@ -149,6 +194,10 @@ func main() {
 	// Bodegas (protegidas)
 	r.GET("/api/bodegas/{id}", protect(bodegaHandler.GetByID))
 	r.PUT("/api/bodegas/{id}", protect(bodegaHandler.Update))
+	r.GET("/api/bodegas/{id}/autoevaluaciones", protect(autoevaluacionHandler.GetHistorial))
+	r.GET("/api/bodegas/{id}/plan-accion", protect(planAccionHandler.Listar))
+	r.POST("/api/bodegas/{id}/plan-accion", protect(planAccionHandler.Crear))
+	r.PUT("/api/bodegas/{id}/plan-accion/{id_accion}", protect(planAccionHandler.Modificar))
 
 	// Responsables (protegidas)
 	r.GET("/api/responsables/{id}", protect(responsableHandler.GetByID))
@@ -159,6 +208,14 @@ func main() {
 
 	// Autoevaluaciones (protegidas)
 	r.POST("/api/autoevaluaciones", protect(autoevaluacionHandler.CreateAutoevaluacion))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}", protect(autoevaluacionHandler.GetDetalle))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/comparar/{otro_id}", protect(autoevaluacionHandler.Comparar))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/resultados", protect(autoevaluacionHandler.GetResultados))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/recomendaciones", protect(autoevaluacionHandler.GetRecomendaciones))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/progreso", protect(autoevaluacionHandler.GetProgreso))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/plan-accion", protect(planAccionHandler.GetSeguimiento))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/reporte.pdf", protect(reporteHandler.DescargarReporte))
+	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/certificado.pdf", protect(reporteHandler.DescargarCertificado))
 	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/segmentos", protect(autoevaluacionHandler.GetSegmentos))
 	r.PUT("/api/autoevaluaciones/{id_autoevaluacion}/segmento", protect(autoevaluacionHandler.SeleccionarSegmento))
 	r.GET("/api/autoevaluaciones/{id_autoevaluacion}/estructura", protect(autoevaluacionHandler.GetEstructura))
@@ -173,6 +230,73 @@ func main() {
 	r.DELETE("/api/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/evidencia", protect(evidenciaHandler.EliminarEvidencia))
 	r.PUT("/api/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/evidencia", protect(evidenciaHandler.CambiarEvidencia))
 
+	// ===== RUTAS DE ADMINISTRACIÓN =====
+
+	requireAdmin := middleware.RequireTipoCuenta(domain.TipoCuentaAdministradorApp)
+
+	// Helper para rutas que requieren cuenta de administrador
+	protectAdmin := func(handler http.HandlerFunc) http.HandlerFunc {
+		return authMiddleware(requireAdmin(handler)).ServeHTTP
+	}
+
+	// Catálogo de ubicaciones
+	r.POST("/api/admin/ubicaciones/importar", protectAdmin(importacionUbicacionesHandler.Importar))
+	r.POST("/api/admin/ubicaciones/recargar", protectAdmin(importacionUbicacionesHandler.Recargar))
+
+	// Corrección de autoevaluaciones completadas
+	r.POST("/api/admin/autoevaluaciones/{id_autoevaluacion}/reabrir", protectAdmin(autoevaluacionHandler.ReabrirAutoevaluacion))
+
+	// Bodegas que deben reevaluarse
+	r.GET("/api/admin/bodegas/reevaluacion", protectAdmin(autoevaluacionHandler.GetReevaluaciones))
+
+	// Auditores y bodegas asignadas
+	r.GET("/api/admin/auditores", protectAdmin(auditorHandler.GetAuditores))
+	r.POST("/api/admin/auditores", protectAdmin(auditorHandler.CrearAuditor))
+	r.PUT("/api/admin/auditores/{id}/bodegas", protectAdmin(auditorHandler.AsignarBodegas))
+
+	// Versiones de la guía de autoevaluación
+	r.GET("/api/admin/guia/versiones", protectAdmin(guiaVersionHandler.GetVersiones))
+	r.POST("/api/admin/guia/versiones", protectAdmin(guiaVersionHandler.CrearBorrador))
+	r.POST("/api/admin/guia/versiones/{id}/publicar", protectAdmin(guiaVersionHandler.Publicar))
+	r.GET("/api/admin/guia/versiones/{id}", protectAdmin(guiaEdicionHandler.GetContenido))
+	r.DELETE("/api/admin/guia/versiones/{id}", protectAdmin(guiaVersionHandler.DescartarBorrador))
+	r.GET("/api/admin/guia/versiones/{id}/exportar", protectAdmin(guiaDocumentoHandler.Exportar))
+	r.POST("/api/admin/guia/importar", protectAdmin(guiaDocumentoHandler.Importar))
+
+	// Edición del cuestionario (solo versiones en borrador; las rutas /orden van antes que las de {id_...})
+	r.POST("/api/admin/guia/versiones/{id}/capitulos", protectAdmin(guiaEdicionHandler.CrearCapitulo))
+	r.PUT("/api/admin/guia/versiones/{id}/capitulos/orden", protectAdmin(guiaEdicionHandler.ReordenarCapitulos))
+	r.PUT("/api/admin/guia/versiones/{id}/capitulos/{id_capitulo}", protectAdmin(guiaEdicionHandler.ModificarCapitulo))
+	r.DELETE("/api/admin/guia/versiones/{id}/capitulos/{id_capitulo}", protectAdmin(guiaEdicionHandler.RetirarCapitulo))
+	r.POST("/api/admin/guia/versiones/{id}/capitulos/{id_capitulo}/indicadores", protectAdmin(guiaEdicionHandler.CrearIndicador))
+	r.PUT("/api/admin/guia/versiones/{id}/capitulos/{id_capitulo}/indicadores/orden", protectAdmin(guiaEdicionHandler.ReordenarIndicadores))
+	r.PUT("/api/admin/guia/versiones/{id}/indicadores/{id_indicador}", protectAdmin(guiaEdicionHandler.ModificarIndicador))
+	r.DELETE("/api/admin/guia/versiones/{id}/indicadores/{id_indicador}", protectAdmin(guiaEdicionHandler.RetirarIndicador))
+	r.PUT("/api/admin/guia/versiones/{id}/indicadores/{id_indicador}/segmentos", protectAdmin(guiaEdicionHandler.AsignarSegmentos))
+	r.POST("/api/admin/guia/versiones/{id}/indicadores/{id_indicador}/niveles-respuesta", protectAdmin(guiaEdicionHandler.CrearNivelRespuesta))
+	r.PUT("/api/admin/guia/versiones/{id}/indicadores/{id_indicador}/niveles-respuesta/orden", protectAdmin(guiaEdicionHandler.ReordenarNivelesRespuesta))
+	r.PUT("/api/admin/guia/versiones/{id}/niveles-respuesta/{id_nivel_respuesta}", protectAdmin(guiaEdicionHandler.ModificarNivelRespuesta))
+	r.DELETE("/api/admin/guia/versiones/{id}/niveles-respuesta/{id_nivel_respuesta}", protectAdmin(guiaEdicionHandler.RetirarNivelRespuesta))
+	r.PUT("/api/admin/guia/versiones/{id}/segmentos/{id_segmento}/niveles-sostenibilidad", protectAdmin(guiaEdicionHandler.ReemplazarNivelesSostenibilidad))
+	r.PUT("/api/admin/guia/versiones/{id}/estrategia-puntaje", protectAdmin(guiaEdicionHandler.CambiarEstrategiaPuntaje))
+
+	// ===== RUTAS DE AUDITORES =====
+
+	requireAuditor := middleware.RequireTipoCuenta(domain.TipoCuentaAuditor)
+
+	// Helper para rutas que requieren cuenta de auditor
+	protectAuditor := func(handler http.HandlerFunc) http.HandlerFunc {
+		return authMiddleware(requireAuditor(handler)).ServeHTTP
+	}
+
+	// Revisión de autoevaluaciones de las bodegas asignadas
+	r.GET("/api/auditor/autoevaluaciones", protectAuditor(revisionHandler.GetAsignadas))
+	r.GET("/api/auditor/autoevaluaciones/{id_autoevaluacion}/revision", protectAuditor(revisionHandler.GetRevision))
+	r.POST("/api/auditor/autoevaluaciones/{id_autoevaluacion}/revision", protectAuditor(revisionHandler.IniciarRevision))
+	r.POST("/api/auditor/autoevaluaciones/{id_autoevaluacion}/revision/finalizar", protectAuditor(revisionHandler.FinalizarRevision))
+	r.PUT("/api/auditor/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/revision", protectAuditor(revisionHandler.RevisarRespuesta))
+	r.PUT("/api/auditor/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/evidencia/revision", protectAuditor(revisionHandler.RevisarEvidencia))
+
 	// 7. Iniciar servidor
 	addr := cfg.Server.Host + ":" + cfg.Server.Port
 	log.Printf("🚀 Servidor iniciando en http://%s", addr)
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"net/smtp"
 	"os"
 	"time"
 
@@ -39,21 +38,9 @@ func generateResetToken() (string, error) {
 }
 
 func sendResetEmail(email, token string) error {
-	smtpHost := getEnvDefault("SMTP_HOST", "smtp.gmail.com")
-	smtpUser := os.Getenv("SMTP_USER")
-	smtpPass := os.Getenv("SMTP_PASSWORD")
-	smtpPort := getEnvDefault("SMTP_PORT", "587")
 	frontendURL := getEnvDefault("FRONTEND_URL", "http://localhost:3000")
-
-	if smtpUser == "" || smtpPass == "" {
-		return fmt.Errorf("configuración SMTP incompleta: SMTP_USER y SMTP_PASSWORD son requeridos")
-	}
-
 	resetURL := fmt.Sprintf("%s/actualizar-contrasena?token=%s", frontendURL, token)
 
-	subject := "Subject: Recuperación de Contraseña\r\n"
-	mime := "MIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n"
-
 	body := fmt.Sprintf(`
 		<!DOCTYPE html>
 		<html>
@@ -90,12 +77,8 @@ func sendResetEmail(email, token string) error {
 		</html>
 	`, resetURL, resetURL)
 
-	message := []byte(subject + mime + body)
-	auth := smtp.PlainAuth("", smtpUser, smtpPass, smtpHost)
-	addr := fmt.Sprintf("%s:%s", smtpHost, smtpPort)
-
-	if err := smtp.SendMail(addr, auth, smtpUser, []string{email}, message); err != nil {
-		return fmt.Errorf("error enviando email: %v", err)
+	if err := sendEmail(email, "Recuperación de Contraseña", body); err != nil {
+		return err
 	}
 
 	log.Printf("✅ Email de recuperación enviado a %s", email)
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+)
+
+// recordarVigencias se ejecuta en background y, cada hora, envía los recordatorios de
+// reevaluación a las bodegas cuyo nivel de sostenibilidad está por vencer
+func recordarVigencias(autoevaluacionService *service.AutoevaluacionService) {
+	ticker := time.NewTicker(1 * time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		enviados, err := autoevaluacionService.RecordarVigencias(context.Background(), time.Now(), sendRecordatorioVigenciaEmail)
+		if err != nil {
+			log.Printf("Error enviando recordatorios de vigencia: %v", err)
+			continue
+		}
+		if enviados > 0 {
+			log.Printf("Recordatorios de vigencia enviados: %d", enviados)
+		}
+	}
+}
+
+func sendRecordatorioVigenciaEmail(ctx context.Context, vigencia *domain.VigenciaBodega, email string) error {
+	frontendURL := getEnvDefault("FRONTEND_URL", "http://localhost:3000")
+
+	nivel := ""
+	if vigencia.NivelSostenibilidad != nil {
+		nivel = " <strong>" + html.EscapeString(*vigencia.NivelSostenibilidad) + "</strong>"
+	}
+	vencimiento := vigencia.FechaVencimiento.Format("02/01/2006")
+	estado := fmt.Sprintf("vence el <strong>%s</strong>", vencimiento)
+	if vigencia.Estado == domain.VigenciaVencida {
+		estado = fmt.Sprintf("venció el <strong>%s</strong>", vencimiento)
+	}
+
+	body := fmt.Sprintf(`
+		<!DOCTYPE html>
+		<html>
+		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+			<div style="max-width: 600px; margin: 0 auto; padding: 20px;">
+				<h2>Es momento de reevaluar su bodega</h2>
+				<p>El nivel de sostenibilidad%s obtenido por <strong>%s</strong> en la autoevaluación del %s %s.</p>
+				<p>Para mantenerlo vigente, complete una nueva autoevaluación.</p>
+				<p><a href="%s">Iniciar la autoevaluación</a></p>
+			</div>
+		</body>
+		</html>
+	`, nivel, html.EscapeString(vigencia.Bodega), vigencia.FechaFin.Format("02/01/2006"), estado, frontendURL)
+
+	if err := sendEmail(email, "Recordatorio de reevaluación", body); err != nil {
+		return err
+	}
+
+	log.Printf("✅ Recordatorio de vigencia enviado a %s (bodega %d)", email, vigencia.IDBodega)
+	return nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository/postgres"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/database"
+	"coviar_backend/pkg/guia"
+)
+
+// exportarGuia escribe el cuestionario de una versión de la guía en un archivo JSON o YAML
+func exportarGuia(args []string) {
+	fs := flag.NewFlagSet("exportar-guia", flag.ExitOnError)
+	version := fs.Int("version", 0, "ID de la versión a exportar (por defecto, la publicada)")
+	salida := fs.String("salida", "", "archivo de salida (.json, .yaml o .yml)")
+	fs.Parse(args)
+
+	if *salida == "" {
+		fmt.Println("Uso: cli exportar-guia -salida <archivo> [-version <id>]")
+		os.Exit(1)
+	}
+	formato, err := guia.FormatoDeArchivo(*salida)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(1)
+	}
+
+	db := conectarDB()
+	defer db.Close()
+
+	doc, err := nuevoGuiaDocumentoService(db).Exportar(context.Background(), *version)
+	if err != nil {
+		fmt.Printf("❌ Error exportando la guía: %v\n", err)
+		os.Exit(1)
+	}
+
+	f, err := os.Create(*salida)
+	if err != nil {
+		fmt.Printf("❌ Error creando archivo: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	if err := guia.Escribir(f, doc, formato); err != nil {
+		fmt.Printf("❌ Error escribiendo archivo: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Guía versión %d exportada a %s (%d capítulos)\n", doc.Version, *salida, len(doc.Capitulos))
+}
+
+// importarGuia compara un documento de la guía con la versión publicada.
+// Con -aplicar lo guarda como una nueva versión en borrador.
+func importarGuia(args []string) {
+	fs := flag.NewFlagSet("importar-guia", flag.ExitOnError)
+	archivo := fs.String("archivo", "", "documento de la guía (.json, .yaml o .yml)")
+	descripcion := fs.String("descripcion", "", "descripción de la nueva versión (por defecto, la del documento)")
+	aplicar := fs.Bool("aplicar", false, "guarda el documento como borrador (sin este flag solo se muestran las diferencias)")
+	fs.Parse(args)
+
+	if *archivo == "" {
+		fmt.Println("Uso: cli importar-guia -archivo <archivo> [-descripcion <texto>] [-aplicar]")
+		os.Exit(1)
+	}
+
+	fmt.Println("=== Importación de la guía de autoevaluación ===")
+	doc, err := guia.LeerArchivo(*archivo)
+	if err != nil {
+		fmt.Printf("❌ Error leyendo archivo: %v\n", err)
+		os.Exit(1)
+	}
+
+	db := conectarDB()
+	defer db.Close()
+
+	reporte, err := nuevoGuiaDocumentoService(db).Importar(context.Background(), doc, *descripcion, *aplicar)
+	if err != nil {
+		fmt.Printf("❌ Error importando la guía: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Comparado con la versión publicada N° %d\n", reporte.VersionComparada.Numero)
+	imprimirCambiosGuia("Agregados", reporte.Agregados)
+	imprimirCambiosGuia("Modificados", reporte.Modificados)
+	imprimirCambiosGuia("Retirados", reporte.Retirados)
+	fmt.Printf("\nSin cambios: %d\n", reporte.SinCambios)
+
+	if reporte.Aplicado {
+		fmt.Printf("\n✅ Documento guardado como borrador N° %d (ID %d). Revíselo y publíquelo desde la administración.\n",
+			reporte.Borrador.Numero, reporte.Borrador.ID)
+	} else {
+		fmt.Println("\nℹ️  Vista previa: no se modificó la base de datos. Use -aplicar para guardar el borrador.")
+	}
+}
+
+func nuevoGuiaDocumentoService(db *database.DB) *service.GuiaDocumentoService {
+	guiaVersionRepo := postgres.NewGuiaVersionRepository(db.DB)
+	capituloRepo := postgres.NewCapituloRepository(db.DB)
+	indicadorRepo := postgres.NewIndicadorRepository(db.DB)
+	nivelRespuestaRepo := postgres.NewNivelRespuestaRepository(db.DB)
+	segmentoRepo := postgres.NewSegmentoRepository(db.DB)
+	txManager := postgres.NewTransactionManager(db.DB)
+
+	edicionService := service.NewGuiaEdicionService(guiaVersionRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, segmentoRepo, txManager, service.NewEstructuraCache())
+	return service.NewGuiaDocumentoService(edicionService, guiaVersionRepo, capituloRepo, indicadorRepo, nivelRespuestaRepo, segmentoRepo, txManager)
+}
+
+func imprimirCambiosGuia(titulo string, cambios []domain.CambioGuia) {
+	fmt.Printf("\n%s: %d\n", titulo, len(cambios))
+	for _, c := range cambios {
+		clave := c.Clave
+		if clave == "" {
+			clave = "nuevo"
+		}
+		fmt.Printf("  [%s] %s - %s\n", c.Tipo, clave, c.Nombre)
+		for _, d := range c.Detalle {
+			fmt.Printf("      %s\n", d)
+		}
+	}
+}
@@ -17,6 +17,32 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "importar-ubicaciones":
+			importarUbicaciones(os.Args[2:])
+			return
+		case "exportar-guia":
+			exportarGuia(os.Args[2:])
+			return
+		case "importar-guia":
+			importarGuia(os.Args[2:])
+			return
+		case "-h", "--help", "ayuda":
+			fmt.Println("Uso:")
+			fmt.Println("  cli                          Registra un ADMINISTRADOR_APP (interactivo)")
+			fmt.Println("  cli importar-ubicaciones     Importa el catálogo oficial de ubicaciones (INDEC/Georef)")
+			fmt.Println("  cli exportar-guia            Exporta el cuestionario de la guía a JSON o YAML")
+			fmt.Println("  cli importar-guia            Importa un documento de la guía como nueva versión borrador")
+			return
+		}
+	}
+
+	registrarAdministrador()
+}
+
+// registrarAdministrador registra una cuenta ADMINISTRADOR_APP con su responsable
+func registrarAdministrador() {
 	fmt.Println("=== Registro de ADMINISTRADOR_APP ===")
 	ctx := context.Background()
 	reader := bufio.NewReader(os.Stdin)
@@ -63,17 +89,7 @@ func main() {
 	}
 
 	// Inicializar DB y repositorios
-	fmt.Println("\nConectando a la base de datos...")
-	cfg, err := config.Load()
-	if err != nil {
-		fmt.Printf("❌ Error cargando configuración: %v\n", err)
-		os.Exit(1)
-	}
-	db, err := database.ConnectSupabase(cfg.Supabase.URL, cfg.Supabase.Key, cfg.Supabase.DBPassword)
-	if err != nil {
-		fmt.Printf("❌ Error conectando a la base de datos: %v\n", err)
-		os.Exit(1)
-	}
+	db := conectarDB()
 	defer db.Close()
 
 	cuentaRepo := postgres.NewCuentaRepository(db.DB)
@@ -124,6 +140,22 @@ func main() {
 	fmt.Printf("   Responsable: %s %s\n", nombre, apellido)
 }
 
+// conectarDB carga la configuración y abre la conexión a la base de datos
+func conectarDB() *database.DB {
+	fmt.Println("\nConectando a la base de datos...")
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("❌ Error cargando configuración: %v\n", err)
+		os.Exit(1)
+	}
+	db, err := database.ConnectSupabase(cfg.Supabase.URL, cfg.Supabase.Key, cfg.Supabase.DBPassword)
+	if err != nil {
+		fmt.Printf("❌ Error conectando a la base de datos: %v\n", err)
+		os.Exit(1)
+	}
+	return db
+}
+
 func prompt(reader *bufio.Reader, label string) string {
 	fmt.Print(label)
 	text, _ := reader.ReadString('\n')
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository/postgres"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/georef"
+)
+
+// importarUbicaciones importa los archivos oficiales de provincias, departamentos y localidades.
+// Por defecto solo muestra los cambios; con -aplicar los guarda en la base de datos.
+func importarUbicaciones(args []string) {
+	fs := flag.NewFlagSet("importar-ubicaciones", flag.ExitOnError)
+	provincias := fs.String("provincias", "", "archivo de provincias (.json o .csv)")
+	departamentos := fs.String("departamentos", "", "archivo de departamentos (.json o .csv)")
+	localidades := fs.String("localidades", "", "archivo de localidades (.json o .csv)")
+	aplicar := fs.Bool("aplicar", false, "aplica los cambios (sin este flag solo se muestra el reporte)")
+	fs.Parse(args)
+
+	if *provincias == "" || *departamentos == "" || *localidades == "" {
+		fmt.Println("Uso: cli importar-ubicaciones -provincias <archivo> -departamentos <archivo> -localidades <archivo> [-aplicar]")
+		os.Exit(1)
+	}
+
+	fmt.Println("=== Importación del catálogo oficial de ubicaciones ===")
+	catalogo, err := georef.LeerCatalogo(*provincias, *departamentos, *localidades)
+	if err != nil {
+		fmt.Printf("❌ Error leyendo archivos: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Archivos leídos: %d provincias, %d departamentos, %d localidades\n",
+		len(catalogo.Provincias), len(catalogo.Departamentos), len(catalogo.Localidades))
+
+	db := conectarDB()
+	defer db.Close()
+
+	importacionService := service.NewImportacionUbicacionesService(
+		postgres.NewUbicacionRepository(db.DB),
+		postgres.NewTransactionManager(db.DB),
+		nil,
+	)
+
+	reporte, err := importacionService.ImportarGeoref(context.Background(), catalogo, *aplicar)
+	if err != nil {
+		fmt.Printf("❌ Error importando ubicaciones: %v\n", err)
+		os.Exit(1)
+	}
+
+	imprimirCambios("Agregadas", reporte.Agregadas)
+	imprimirCambios("Renombradas", reporte.Renombradas)
+	imprimirCambios("Eliminadas", reporte.Eliminadas)
+	imprimirCambios("Conservadas (en uso)", reporte.Conservadas)
+	imprimirCambios("Omitidas (registros inválidos)", reporte.Omitidas)
+
+	if reporte.Aplicado {
+		fmt.Println("\n✅ Cambios aplicados. Recuerde recargar el catálogo del servidor (POST /api/admin/ubicaciones/recargar).")
+	} else {
+		fmt.Println("\nℹ️  Vista previa: no se modificó la base de datos. Use -aplicar para guardar los cambios.")
+	}
+}
+
+func imprimirCambios(titulo string, cambios []domain.CambioUbicacion) {
+	fmt.Printf("\n%s: %d\n", titulo, len(cambios))
+	for _, c := range cambios {
+		linea := fmt.Sprintf("  [%s] %s", c.Tipo, c.Nombre)
+		if c.IDGeoref != "" {
+			linea += fmt.Sprintf(" (georef %s)", c.IDGeoref)
+		}
+		if c.NombreAnterior != "" {
+			linea += fmt.Sprintf(" - antes: %s", c.NombreAnterior)
+		}
+		if c.Motivo != "" {
+			linea += fmt.Sprintf(" - %s", c.Motivo)
+		}
+		fmt.Println(linea)
+	}
+}
@@ -3,8 +3,10 @@ module coviar_backend
 go 1.25.5
 
 require (
+	github.com/golang-jwt/jwt/v5 v5.3.0
 	github.com/joho/godotenv v1.5.1
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/lib/pq v1.10.9
 	golang.org/x/crypto v0.47.0
-	github.com/golang-jwt/jwt/v5 v5.3.0
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -1,6 +1,10 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var (
 	ErrNotFound                   = errors.New("recurso no encontrado")
@@ -12,4 +16,61 @@ var (
 	ErrValidation                 = errors.New("error de validación")
 	ErrAutoevaluacionesPendientes = errors.New("no se puede dar de baja: existen autoevaluaciones pendientes")
 	ErrResponsableYaDadoDeBaja    = errors.New("el responsable ya está dado de baja")
+	ErrAutoevaluacionNoCompletada = errors.New("la autoevaluación no está completada")
+	ErrGuiaBorradorExistente      = errors.New("ya existe una versión borrador de la guía")
+	ErrGuiaVersionNoEditable      = errors.New("solo se puede modificar una versión de la guía en borrador")
+	ErrAutoevaluacionNoPendiente  = errors.New("la autoevaluación no está pendiente")
+	ErrBodegaConPendiente         = errors.New("la bodega ya tiene una autoevaluación pendiente")
+	ErrAuditorNoAsignado          = errors.New("el auditor no está asignado a la bodega")
+	ErrRevisionNoDisponible       = errors.New("la autoevaluación no está disponible para revisión")
+	ErrRevisionNoEnCurso          = errors.New("la autoevaluación no está en revisión")
+	ErrRevisionDeOtroAuditor      = errors.New("la revisión está a cargo de otro auditor")
+	ErrAutoevaluacionNoReabrible  = errors.New("solo se puede reabrir una autoevaluación completada que no esté en revisión ni validada")
+	ErrVersionRequerida           = errors.New("debe enviar la versión de la autoevaluación en el encabezado If-Match")
+	ErrVersionDesactualizada      = errors.New("la autoevaluación fue modificada por otra sesión")
 )
+
+// ErrorRespuesta indica por qué no se puede guardar una de las respuestas enviadas;
+// Indice es su posición en la lista recibida
+type ErrorRespuesta struct {
+	Indice           int    `json:"indice"`
+	IDIndicador      int    `json:"id_indicador"`
+	IDNivelRespuesta int    `json:"id_nivel_respuesta"`
+	Mensaje          string `json:"mensaje"`
+}
+
+// ErroresRespuestas agrupa los errores de todas las respuestas rechazadas de un guardado
+type ErroresRespuestas []ErrorRespuesta
+
+func (e ErroresRespuestas) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, fmt.Sprintf("respuestas[%d]: %s", err.Indice, err.Mensaje))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// CambioSegmentoSinConfirmar indica que el cambio de segmento dejaría respuestas fuera del
+// cuestionario; Cambio describe qué se eliminaría si se confirma
+type CambioSegmentoSinConfirmar struct {
+	Cambio *CambioSegmento
+}
+
+func (e *CambioSegmentoSinConfirmar) Error() string {
+	return fmt.Sprintf("el cambio de segmento eliminaría %d respuestas y %d evidencias: debe confirmarse",
+		len(e.Cambio.RespuestasFueraDeSegmento), e.Cambio.EvidenciasEliminadas)
+}
+
+// AutoevaluacionDesactualizada indica que la versión enviada no es la actual; Actual es el
+// estado vigente de la autoevaluación, para que el cliente pueda reconciliar sus cambios
+type AutoevaluacionDesactualizada struct {
+	Actual *AutoevaluacionDetalle
+}
+
+func (e *AutoevaluacionDesactualizada) Error() string {
+	return fmt.Sprintf("%s: la versión actual es %d", ErrVersionDesactualizada, e.Actual.Version)
+}
+
+func (e *AutoevaluacionDesactualizada) Unwrap() error {
+	return ErrVersionDesactualizada
+}
@@ -19,6 +19,11 @@ type Bodega struct {
 	Telefono           string    `json:"telefono"`            // check: ^[0-9]+$
 	EmailInstitucional string    `json:"email_institucional"` // check: like '%@%'
 	FechaRegistro      time.Time `json:"fecha_registro,omitempty"`
+
+	// Datos derivados de id_localidad, completados al responder (no se persisten)
+	Ubicacion *LocalidadConUbicacion `json:"ubicacion,omitempty"`
+	Direccion string                 `json:"direccion,omitempty"` // "Calle Numeración, Localidad, Departamento, Provincia"
+	Vigencia  *VigenciaBodega        `json:"vigencia,omitempty"`  // vigencia del nivel de la última autoevaluación completada
 }
 
 type BodegaRequest struct {
@@ -49,6 +54,7 @@ type TipoCuenta string
 const (
 	TipoCuentaBodega           TipoCuenta = "BODEGA"
 	TipoCuentaAdministradorApp TipoCuenta = "ADMINISTRADOR_APP"
+	TipoCuentaAuditor          TipoCuenta = "AUDITOR"
 )
 
 type Cuenta struct {
@@ -140,20 +146,65 @@ func (u *Usuario) ToPublic() *Usuario {
 // ============================================
 
 type Provincia struct {
-	ID     int    `json:"id_provincia,omitempty"`
-	Nombre string `json:"nombre"`
+	ID       int     `json:"id_provincia,omitempty"`
+	Nombre   string  `json:"nombre"`
+	IDGeoref *string `json:"id_georef,omitempty"` // ID oficial INDEC/Georef
 }
 
 type Departamento struct {
-	ID          int    `json:"id_departamento,omitempty"`
-	IDProvincia int    `json:"id_provincia"`
-	Nombre      string `json:"nombre"`
+	ID          int     `json:"id_departamento,omitempty"`
+	IDProvincia int     `json:"id_provincia"`
+	Nombre      string  `json:"nombre"`
+	IDGeoref    *string `json:"id_georef,omitempty"`
 }
 
 type Localidad struct {
-	ID             int    `json:"id_localidad,omitempty"`
+	ID             int     `json:"id_localidad,omitempty"`
+	IDDepartamento int     `json:"id_departamento"`
+	Nombre         string  `json:"nombre"`
+	IDGeoref       *string `json:"id_georef,omitempty"`
+}
+
+// LocalidadConUbicacion es una localidad con los nombres de su departamento y provincia,
+// usada por el autocompletado de ubicaciones
+type LocalidadConUbicacion struct {
+	IDLocalidad    int    `json:"id_localidad"`
+	Localidad      string `json:"localidad"`
 	IDDepartamento int    `json:"id_departamento"`
+	Departamento   string `json:"departamento"`
+	IDProvincia    int    `json:"id_provincia"`
+	Provincia      string `json:"provincia"`
+	NombreCompleto string `json:"nombre_completo"` // "Localidad, Departamento, Provincia"
+}
+
+// LocalidadDetalle es una localidad con su departamento y provincia completos
+type LocalidadDetalle struct {
+	ID           int           `json:"id_localidad"`
+	Nombre       string        `json:"nombre"`
+	IDGeoref     *string       `json:"id_georef,omitempty"`
+	Departamento *Departamento `json:"departamento"`
+	Provincia    *Provincia    `json:"provincia"`
+}
+
+// CambioUbicacion describe una provincia, departamento o localidad afectada por
+// una importación del catálogo oficial
+type CambioUbicacion struct {
+	Tipo           string `json:"tipo"` // PROVINCIA, DEPARTAMENTO o LOCALIDAD
+	ID             int    `json:"id,omitempty"`
+	IDGeoref       string `json:"id_georef,omitempty"`
 	Nombre         string `json:"nombre"`
+	NombreAnterior string `json:"nombre_anterior,omitempty"`
+	Motivo         string `json:"motivo,omitempty"`
+}
+
+// ReporteImportacionUbicaciones resume el resultado de importar el catálogo oficial
+type ReporteImportacionUbicaciones struct {
+	Aplicado    bool              `json:"aplicado"`
+	Agregadas   []CambioUbicacion `json:"agregadas"`
+	Renombradas []CambioUbicacion `json:"renombradas"`
+	Eliminadas  []CambioUbicacion `json:"eliminadas"`
+	Conservadas []CambioUbicacion `json:"conservadas"` // no eliminadas por estar en uso
+	Omitidas    []CambioUbicacion `json:"omitidas"`    // registros inválidos del archivo
 }
 
 // ============================================
@@ -193,26 +244,40 @@ type Segmento struct {
 }
 
 type NivelSostenibilidad struct {
-	ID         int    `json:"id_nivel_sostenibilidad"`
-	IDSegmento int    `json:"id_segmento"`
-	Nombre     string `json:"nombre"`
-	MinPuntaje int    `json:"min_puntaje"`
-	MaxPuntaje int    `json:"max_puntaje"`
+	ID            int                  `json:"id_nivel_sostenibilidad"`
+	IDSegmento    int                  `json:"id_segmento"`
+	IDGuiaVersion int                  `json:"id_guia_version"`
+	Nombre        string               `json:"nombre"`
+	MinPuntaje    int                  `json:"min_puntaje"`
+	MaxPuntaje    int                  `json:"max_puntaje"`
+	MesesVigencia *int                 `json:"meses_vigencia,omitempty"` // nil: se usa la vigencia por defecto
+	Requisitos    []*RequisitoCapitulo `json:"requisitos"`               // porcentajes mínimos por capítulo para alcanzar el nivel
+}
+
+// RequisitoCapitulo exige un porcentaje mínimo en un capítulo para alcanzar un nivel de sostenibilidad
+type RequisitoCapitulo struct {
+	IDCapitulo    int     `json:"id_capitulo"`
+	MinPorcentaje float64 `json:"min_porcentaje"`
 }
 
 type Capitulo struct {
-	ID          int    `json:"id_capitulo"`
-	Nombre      string `json:"nombre"`
-	Descripcion string `json:"descripcion"`
-	Orden       int    `json:"orden"`
+	ID            int     `json:"id_capitulo"`
+	IDGuiaVersion int     `json:"id_guia_version"`
+	Clave         string  `json:"clave"` // identifica al capítulo entre versiones de la guía
+	Nombre        string  `json:"nombre"`
+	Descripcion   string  `json:"descripcion"`
+	Orden         int     `json:"orden"`
+	Peso          float64 `json:"peso"` // solo se usa con la estrategia de puntaje PONDERADA
 }
 
 type Indicador struct {
-	ID          int    `json:"id_indicador"`
-	IDCapitulo  int    `json:"id_capitulo"`
-	Nombre      string `json:"nombre"`
-	Descripcion string `json:"descripcion"`
-	Orden       int    `json:"orden"`
+	ID          int     `json:"id_indicador"`
+	IDCapitulo  int     `json:"id_capitulo"`
+	Clave       string  `json:"clave"` // identifica al indicador entre versiones de la guía
+	Nombre      string  `json:"nombre"`
+	Descripcion string  `json:"descripcion"`
+	Orden       int     `json:"orden"`
+	Peso        float64 `json:"peso"` // solo se usa con la estrategia de puntaje PONDERADA
 }
 
 type IndicadorConHabilitacion struct {
@@ -240,15 +305,21 @@ type Autoevaluacion struct {
 	PuntajeFinal          *int                 `json:"puntaje_final,omitempty"`
 	IDNivelSostenibilidad *int                 `json:"id_nivel_sostenibilidad,omitempty"`
 	EstadoEvidencia       *EstadoEvidencia     `json:"estado_evidencia,omitempty"` // ← NUEVA LÍNEA
+	IDGuiaVersion         int                  `json:"id_guia_version"`            // versión de la guía con la que se inició
+	Version               int                  `json:"version"`                    // aumenta con cada modificación; se expone como ETag
 }
 
 type Respuesta struct {
-	ID               int `json:"id_respuesta"`
-	IDNivelRespuesta int `json:"id_nivel_respuesta"`
-	IDIndicador      int `json:"id_indicador"`
-	IDAutoevaluacion int `json:"id_autoevaluacion"`
+	ID               int     `json:"id_respuesta"`
+	IDNivelRespuesta int     `json:"id_nivel_respuesta"`
+	IDIndicador      int     `json:"id_indicador"`
+	IDAutoevaluacion int     `json:"id_autoevaluacion"`
+	Nota             *string `json:"nota,omitempty"` // justificación opcional de la bodega
 }
 
+// LongitudMaximaNota es la cantidad máxima de caracteres de la nota de una respuesta
+const LongitudMaximaNota = 1000
+
 type EstructuraAutoevaluacion struct {
 	Capitulos []*CapituloEstructura `json:"capitulos"`
 }
@@ -263,13 +334,31 @@ type CreateAutoevaluacionRequest struct {
 	IDBodega int `json:"id_bodega"`
 }
 
+// SeleccionarSegmentoRequest elige el segmento de la autoevaluación. Si el cambio deja
+// respuestas fuera del cuestionario, debe reenviarse con Confirmar para eliminarlas.
 type SeleccionarSegmentoRequest struct {
-	IDSegmento int `json:"id_segmento"`
+	IDSegmento int  `json:"id_segmento"`
+	Confirmar  bool `json:"confirmar"`
+}
+
+// CambioSegmento describe las respuestas (y sus evidencias) que quedan fuera del
+// cuestionario al cambiar el segmento de una autoevaluación pendiente
+type CambioSegmento struct {
+	IDSegmentoAnterior        *int                `json:"id_segmento_anterior,omitempty"`
+	IDSegmento                int                 `json:"id_segmento"`
+	Segmento                  string              `json:"segmento"`
+	RespuestasFueraDeSegmento []*RespuestaDetalle `json:"respuestas_fuera_de_segmento"`
+	EvidenciasEliminadas      int                 `json:"evidencias_eliminadas"`
+	Aplicado                  bool                `json:"aplicado"`
+	Version                   int                 `json:"version,omitempty"` // versión de la autoevaluación una vez aplicado
 }
 
+// GuardarRespuestaRequest es una respuesta a guardar. Si Nota se omite se conserva la nota
+// anterior; una nota vacía la borra.
 type GuardarRespuestaRequest struct {
-	IDIndicador      int `json:"id_indicador"`
-	IDNivelRespuesta int `json:"id_nivel_respuesta"`
+	IDIndicador      int     `json:"id_indicador"`
+	IDNivelRespuesta int     `json:"id_nivel_respuesta"`
+	Nota             *string `json:"nota,omitempty"`
 }
 
 type GuardarRespuestasRequest struct {
@@ -282,6 +371,416 @@ type AutoevaluacionPendienteResponse struct {
 	Mensaje                 string                    `json:"mensaje"`
 }
 
+// FiltroAutoevaluaciones define los criterios del historial de autoevaluaciones de una bodega
+type FiltroAutoevaluaciones struct {
+	IDBodega  int
+	Estado    *EstadoAutoevaluacion
+	Desde     *time.Time // fecha_inicio >= Desde
+	Hasta     *time.Time // fecha_inicio < Hasta
+	Pagina    int
+	PorPagina int
+}
+
+// AutoevaluacionResumen es una autoevaluación con los nombres de su segmento y nivel
+type AutoevaluacionResumen struct {
+	ID                    int                  `json:"id_autoevaluacion"`
+	FechaInicio           time.Time            `json:"fecha_inicio"`
+	FechaFin              *time.Time           `json:"fecha_fin,omitempty"`
+	Estado                EstadoAutoevaluacion `json:"estado"`
+	IDBodega              int                  `json:"id_bodega"`
+	IDGuiaVersion         int                  `json:"id_guia_version"`
+	IDSegmento            *int                 `json:"id_segmento,omitempty"`
+	Segmento              *string              `json:"segmento,omitempty"`
+	PuntajeFinal          *int                 `json:"puntaje_final,omitempty"`
+	IDNivelSostenibilidad *int                 `json:"id_nivel_sostenibilidad,omitempty"`
+	NivelSostenibilidad   *string              `json:"nivel_sostenibilidad,omitempty"`
+	EstadoEvidencia       *EstadoEvidencia     `json:"estado_evidencia,omitempty"`
+	Version               int                  `json:"version"`
+
+	// Revisión del auditor; el puntaje y nivel validados excluyen las respuestas rechazadas
+	EstadoRevision                *EstadoRevisionAutoevaluacion `json:"estado_revision,omitempty"`
+	IDCuentaRevision              *int                          `json:"id_cuenta_revision,omitempty"` // auditor que la tomó
+	ComentarioRevision            *string                       `json:"comentario_revision,omitempty"`
+	PuntajeValidado               *int                          `json:"puntaje_validado,omitempty"`
+	IDNivelSostenibilidadValidado *int                          `json:"id_nivel_sostenibilidad_validado,omitempty"`
+	NivelSostenibilidadValidado   *string                       `json:"nivel_sostenibilidad_validado,omitempty"`
+}
+
+type HistorialAutoevaluacionesResponse struct {
+	Autoevaluaciones []*AutoevaluacionResumen `json:"autoevaluaciones"`
+	Total            int                      `json:"total"`
+	Pagina           int                      `json:"pagina"`
+	PorPagina        int                      `json:"por_pagina"`
+}
+
+// RespuestaDetalle es una respuesta con los datos del indicador y del nivel elegido
+type RespuestaDetalle struct {
+	ID               int     `json:"id_respuesta"`
+	IDCapitulo       int     `json:"id_capitulo"`
+	ClaveCapitulo    string  `json:"clave_capitulo"`
+	Capitulo         string  `json:"capitulo"`
+	IDIndicador      int     `json:"id_indicador"`
+	ClaveIndicador   string  `json:"clave_indicador"`
+	Indicador        string  `json:"indicador"`
+	IDNivelRespuesta int     `json:"id_nivel_respuesta"`
+	NivelRespuesta   string  `json:"nivel_respuesta"`
+	Puntos           int     `json:"puntos"`
+	TieneEvidencia   bool    `json:"tiene_evidencia"`
+	Nota             *string `json:"nota,omitempty"`
+	PosicionNivel    int     `json:"-"`
+
+	EstadoRevision              EstadoRevision  `json:"estado_revision"`
+	ComentarioRevision          *string         `json:"comentario_revision,omitempty"`
+	EstadoRevisionEvidencia     *EstadoRevision `json:"estado_revision_evidencia,omitempty"` // nil si no tiene evidencia
+	ComentarioRevisionEvidencia *string         `json:"comentario_revision_evidencia,omitempty"`
+}
+
+// AutoevaluacionDetalle es el resumen de una autoevaluación junto con todas sus respuestas
+// y las reaperturas que tuvo
+type AutoevaluacionDetalle struct {
+	*AutoevaluacionResumen
+	Respuestas []*RespuestaDetalle       `json:"respuestas"`
+	Enmiendas  []*EnmiendaAutoevaluacion `json:"enmiendas"`
+}
+
+// EnmiendaAutoevaluacion registra la reapertura de una autoevaluación completada por un
+// administrador y, una vez que se vuelve a completar, el resultado nuevo
+type EnmiendaAutoevaluacion struct {
+	ID                         int                     `json:"id_enmienda"`
+	IDAutoevaluacion           int                     `json:"id_autoevaluacion"`
+	IDCuenta                   int                     `json:"id_cuenta"` // administrador que la reabrió
+	Motivo                     string                  `json:"motivo"`
+	FechaReapertura            time.Time               `json:"fecha_reapertura"`
+	Anterior                   *SnapshotAutoevaluacion `json:"anterior"`
+	FechaRecompletado          *time.Time              `json:"fecha_recompletado,omitempty"`
+	PuntajeNuevo               *int                    `json:"puntaje_nuevo,omitempty"`
+	IDNivelSostenibilidadNuevo *int                    `json:"id_nivel_sostenibilidad_nuevo,omitempty"`
+}
+
+// SnapshotAutoevaluacion conserva las respuestas y el resultado de una autoevaluación
+// tal como estaban al reabrirla
+type SnapshotAutoevaluacion struct {
+	FechaFin              *time.Time           `json:"fecha_fin,omitempty"`
+	PuntajeFinal          *int                 `json:"puntaje_final,omitempty"`
+	IDNivelSostenibilidad *int                 `json:"id_nivel_sostenibilidad,omitempty"`
+	NivelSostenibilidad   *string              `json:"nivel_sostenibilidad,omitempty"`
+	Capitulos             []*ResultadoCapitulo `json:"capitulos"`
+	Respuestas            []*RespuestaDetalle  `json:"respuestas"`
+
+	// Revisión observada que se descarta al reabrir
+	EstadoRevision                *EstadoRevisionAutoevaluacion `json:"estado_revision,omitempty"`
+	ComentarioRevision            *string                       `json:"comentario_revision,omitempty"`
+	PuntajeValidado               *int                          `json:"puntaje_validado,omitempty"`
+	IDNivelSostenibilidadValidado *int                          `json:"id_nivel_sostenibilidad_validado,omitempty"`
+}
+
+type ReabrirAutoevaluacionRequest struct {
+	Motivo string `json:"motivo"`
+}
+
+// AutoevaluacionInactiva es una autoevaluación pendiente sin actividad reciente, con los
+// datos de contacto de su bodega para avisarle antes de cancelarla
+type AutoevaluacionInactiva struct {
+	ID               int        `json:"id_autoevaluacion"`
+	IDBodega         int        `json:"id_bodega"`
+	Bodega           string     `json:"bodega"`
+	Email            string     `json:"email"`
+	FechaInicio      time.Time  `json:"fecha_inicio"`
+	UltimaActividad  time.Time  `json:"ultima_actividad"`
+	AvisoInactividad *time.Time `json:"aviso_inactividad,omitempty"`
+}
+
+// ResultadoVencimiento resume una pasada del proceso de vencimiento de autoevaluaciones
+type ResultadoVencimiento struct {
+	Avisadas   int
+	Canceladas int
+}
+
+// EstadoVigencia indica si el nivel obtenido en la última autoevaluación completada
+// sigue siendo válido
+type EstadoVigencia string
+
+const (
+	VigenciaVigente       EstadoVigencia = "VIGENTE"
+	VigenciaPorVencer     EstadoVigencia = "POR_VENCER"
+	VigenciaVencida       EstadoVigencia = "VENCIDA"
+	VigenciaSinEvaluacion EstadoVigencia = "SIN_EVALUACION"
+)
+
+// UltimaEvaluacionBodega es la última autoevaluación completada de una bodega (nil si
+// no completó ninguna), con los datos necesarios para calcular su vigencia
+type UltimaEvaluacionBodega struct {
+	IDBodega              int
+	Bodega                string
+	Email                 string
+	TienePendiente        bool
+	IDAutoevaluacion      *int
+	FechaFin              *time.Time
+	IDNivelSostenibilidad *int
+	NivelSostenibilidad   *string
+	MesesVigencia         *int // vigencia propia del nivel, si la define
+	RecordatorioVigencia  *int // días antes del vencimiento del último recordatorio enviado
+}
+
+// VigenciaBodega es el estado de reevaluación de una bodega
+type VigenciaBodega struct {
+	IDBodega              int            `json:"id_bodega"`
+	Bodega                string         `json:"bodega"`
+	Estado                EstadoVigencia `json:"estado"`
+	IDAutoevaluacion      *int           `json:"id_autoevaluacion,omitempty"`
+	FechaFin              *time.Time     `json:"fecha_fin,omitempty"`
+	IDNivelSostenibilidad *int           `json:"id_nivel_sostenibilidad,omitempty"`
+	NivelSostenibilidad   *string        `json:"nivel_sostenibilidad,omitempty"`
+	MesesVigencia         int            `json:"meses_vigencia,omitempty"`
+	FechaVencimiento      *time.Time     `json:"fecha_vencimiento,omitempty"`
+	DiasRestantes         *int           `json:"dias_restantes,omitempty"` // negativo si ya venció
+	TienePendiente        bool           `json:"tiene_pendiente"`          // ya inició una nueva autoevaluación
+}
+
+// Tipos de cambio al comparar dos autoevaluaciones
+const (
+	CambioSube            = "SUBE"
+	CambioBaja            = "BAJA"
+	CambioIgual           = "IGUAL"
+	CambioSoloEnBase      = "SOLO_EN_BASE"
+	CambioSoloEnComparada = "SOLO_EN_COMPARADA"
+)
+
+// ComparacionIndicador compara la respuesta a un indicador en ambas autoevaluaciones
+type ComparacionIndicador struct {
+	ClaveIndicador  string  `json:"clave_indicador"`
+	IDIndicador     int     `json:"id_indicador"` // de la comparada si existe en ambas
+	Indicador       string  `json:"indicador"`
+	IDCapitulo      int     `json:"id_capitulo"`
+	NivelBase       *string `json:"nivel_base,omitempty"`
+	PuntosBase      *int    `json:"puntos_base,omitempty"`
+	NivelComparada  *string `json:"nivel_comparada,omitempty"`
+	PuntosComparada *int    `json:"puntos_comparada,omitempty"`
+	DeltaPuntos     *int    `json:"delta_puntos,omitempty"` // solo si el indicador está en ambas
+	Cambio          string  `json:"cambio"`
+}
+
+// ComparacionCapitulo compara los subtotales de un capítulo
+type ComparacionCapitulo struct {
+	ClaveCapitulo     string `json:"clave_capitulo"`
+	IDCapitulo        int    `json:"id_capitulo"`
+	Capitulo          string `json:"capitulo"`
+	SubtotalBase      int    `json:"subtotal_base"`
+	SubtotalComparada int    `json:"subtotal_comparada"`
+	Delta             int    `json:"delta"`
+}
+
+// ComparacionAutoevaluaciones es el resultado de comparar una autoevaluación (comparada)
+// contra otra anterior (base)
+type ComparacionAutoevaluaciones struct {
+	Base                     *AutoevaluacionResumen  `json:"base"`
+	Comparada                *AutoevaluacionResumen  `json:"comparada"`
+	MismoSegmento            bool                    `json:"mismo_segmento"`
+	MismaGuiaVersion         bool                    `json:"misma_guia_version"`
+	DeltaPuntaje             *int                    `json:"delta_puntaje,omitempty"`
+	CambioNivel              string                  `json:"cambio_nivel,omitempty"`
+	Capitulos                []*ComparacionCapitulo  `json:"capitulos"`
+	Indicadores              []*ComparacionIndicador `json:"indicadores"`
+	IndicadoresNoComparables int                     `json:"indicadores_no_comparables"`
+}
+
+// ResultadoCapitulo es el puntaje obtenido en un capítulo frente al máximo alcanzable
+// con los indicadores habilitados para el segmento
+type ResultadoCapitulo struct {
+	IDCapitulo      int     `json:"id_capitulo"`
+	Capitulo        string  `json:"capitulo"`
+	PuntosObtenidos int     `json:"puntos_obtenidos"`
+	PuntosMaximos   int     `json:"puntos_maximos"`
+	Porcentaje      float64 `json:"porcentaje"`
+}
+
+// ResultadosAutoevaluacion reúne el puntaje total y el desglose por capítulo
+type ResultadosAutoevaluacion struct {
+	Autoevaluacion *AutoevaluacionResumen `json:"autoevaluacion"`
+	PuntajeTotal   int                    `json:"puntaje_total"`
+	PuntajeMaximo  int                    `json:"puntaje_maximo"`
+	Porcentaje     float64                `json:"porcentaje"`
+	Capitulos      []*ResultadoCapitulo   `json:"capitulos"`
+}
+
+// PuntajeIndicador son los datos de un indicador habilitado para el segmento con los que
+// la estrategia de puntaje calcula los resultados
+type PuntajeIndicador struct {
+	IDCapitulo    int     `json:"id_capitulo"`
+	Capitulo      string  `json:"capitulo"`
+	PesoCapitulo  float64 `json:"peso_capitulo"`
+	IDIndicador   int     `json:"id_indicador"`
+	PesoIndicador float64 `json:"peso_indicador"`
+	Puntos        int     `json:"puntos"` // 0 si no fue respondido
+	PuntosMaximos int     `json:"puntos_maximos"`
+	Rechazada     bool    `json:"rechazada"` // la respuesta fue rechazada en la revisión del auditor
+}
+
+// Recomendacion sugiere pasar un indicador al nivel de respuesta siguiente
+type Recomendacion struct {
+	IDCapitulo      int     `json:"id_capitulo"`
+	Capitulo        string  `json:"capitulo"`
+	IDIndicador     int     `json:"id_indicador"`
+	Indicador       string  `json:"indicador"`
+	NivelActual     *string `json:"nivel_actual,omitempty"` // nil si el indicador no fue respondido
+	PuntosActuales  int     `json:"puntos_actuales"`
+	IDNivelSugerido int     `json:"id_nivel_sugerido"`
+	NivelSugerido   string  `json:"nivel_sugerido"`
+	ProximoPaso     string  `json:"proximo_paso"` // descripción del nivel sugerido
+	Ganancia        int     `json:"ganancia"`     // puntos que se sumarían al puntaje según la estrategia de la guía
+}
+
+type RecomendacionesResponse struct {
+	IDAutoevaluacion  int              `json:"id_autoevaluacion"`
+	PuntajeActual     int              `json:"puntaje_actual"`
+	Top               int              `json:"top"`
+	PuntajeProyectado int              `json:"puntaje_proyectado"` // aplicando las primeras Top recomendaciones
+	Recomendaciones   []*Recomendacion `json:"recomendaciones"`
+}
+
+// ProgresoCapitulo cuenta los indicadores habilitados respondidos de un capítulo
+type ProgresoCapitulo struct {
+	IDCapitulo  int     `json:"id_capitulo"`
+	Capitulo    string  `json:"capitulo"`
+	Respondidos int     `json:"respondidos"`
+	Requeridos  int     `json:"requeridos"`
+	Porcentaje  float64 `json:"porcentaje"`
+}
+
+// IndicadorFaltante es un indicador habilitado al que le falta la respuesta o la evidencia
+type IndicadorFaltante struct {
+	IDCapitulo  int    `json:"id_capitulo"`
+	Capitulo    string `json:"capitulo"`
+	IDIndicador int    `json:"id_indicador"`
+	Clave       string `json:"clave"`
+	Indicador   string `json:"indicador"`
+}
+
+type ProgresoAutoevaluacion struct {
+	IDAutoevaluacion int                  `json:"id_autoevaluacion"`
+	Respondidos      int                  `json:"respondidos"`
+	Requeridos       int                  `json:"requeridos"`
+	Porcentaje       float64              `json:"porcentaje"`
+	Completa         bool                 `json:"completa"` // se puede completar la autoevaluación
+	Capitulos        []*ProgresoCapitulo  `json:"capitulos"`
+	SinResponder     []*IndicadorFaltante `json:"sin_responder"`
+	SinEvidencia     []*IndicadorFaltante `json:"sin_evidencia"` // respondidos sin evidencia cargada
+}
+
+// ============================================
+// VERSIONES DE LA GUÍA
+// ============================================
+
+type EstadoGuiaVersion string
+
+const (
+	GuiaVersionBorrador  EstadoGuiaVersion = "BORRADOR"
+	GuiaVersionPublicada EstadoGuiaVersion = "PUBLICADA"
+	GuiaVersionArchivada EstadoGuiaVersion = "ARCHIVADA"
+)
+
+// GuiaVersion es una versión inmutable (una vez publicada) del cuestionario:
+// capítulos, indicadores, niveles de respuesta, indicadores por segmento y niveles de sostenibilidad
+type GuiaVersion struct {
+	ID                int               `json:"id_guia_version"`
+	Numero            int               `json:"numero"`
+	Estado            EstadoGuiaVersion `json:"estado"`
+	Descripcion       string            `json:"descripcion"`
+	EstrategiaPuntaje EstrategiaPuntaje `json:"estrategia_puntaje"`
+	IDVersionOrigen   *int              `json:"id_version_origen,omitempty"`
+	FechaCreacion     time.Time         `json:"fecha_creacion"`
+	FechaPublicacion  *time.Time        `json:"fecha_publicacion,omitempty"`
+}
+
+// EstrategiaPuntaje indica cómo se calcula el puntaje de las autoevaluaciones de una versión
+type EstrategiaPuntaje string
+
+const (
+	EstrategiaSuma      EstrategiaPuntaje = "SUMA"      // suma de los puntos de las respuestas
+	EstrategiaPonderada EstrategiaPuntaje = "PONDERADA" // puntos por peso del indicador y del capítulo
+)
+
+type EstrategiaPuntajeRequest struct {
+	EstrategiaPuntaje EstrategiaPuntaje `json:"estrategia_puntaje"`
+}
+
+type CrearBorradorGuiaRequest struct {
+	Descripcion string `json:"descripcion"`
+}
+
+// GuiaVersionContenido es el cuestionario completo de una versión de la guía
+type GuiaVersionContenido struct {
+	Version               *GuiaVersion           `json:"version"`
+	Capitulos             []*CapituloContenido   `json:"capitulos"`
+	NivelesSostenibilidad []*NivelSostenibilidad `json:"niveles_sostenibilidad"`
+}
+
+type CapituloContenido struct {
+	Capitulo    *Capitulo             `json:"capitulo"`
+	Indicadores []*IndicadorContenido `json:"indicadores"`
+}
+
+type IndicadorContenido struct {
+	Indicador        *Indicador        `json:"indicador"`
+	NivelesRespuesta []*NivelRespuesta `json:"niveles_respuesta"`
+	Segmentos        []int             `json:"segmentos"` // segmentos para los que aplica el indicador
+}
+
+// DTOs de edición de la guía (solo sobre versiones en borrador)
+type CapituloRequest struct {
+	Clave       string   `json:"clave"` // opcional al crear; no se puede modificar
+	Nombre      string   `json:"nombre"`
+	Descripcion string   `json:"descripcion"`
+	Peso        *float64 `json:"peso,omitempty"` // por defecto 1 al crear; sin cambios al modificar
+}
+
+type IndicadorRequest struct {
+	IDCapitulo  int      `json:"id_capitulo"` // solo en modificación, para mover el indicador de capítulo
+	Clave       string   `json:"clave"`       // opcional al crear; no se puede modificar
+	Nombre      string   `json:"nombre"`
+	Descripcion string   `json:"descripcion"`
+	Peso        *float64 `json:"peso,omitempty"` // por defecto 1 al crear; sin cambios al modificar
+}
+
+type NivelRespuestaRequest struct {
+	Nombre      string `json:"nombre"`
+	Descripcion string `json:"descripcion"`
+	Puntos      int    `json:"puntos"`
+}
+
+// OrdenRequest lista los IDs en el orden deseado; debe incluir a todos los elementos
+type OrdenRequest struct {
+	IDs []int `json:"ids"`
+}
+
+type SegmentosIndicadorRequest struct {
+	Segmentos []int `json:"segmentos"`
+}
+
+type NivelesSostenibilidadRequest struct {
+	Niveles []*NivelSostenibilidad `json:"niveles"`
+}
+
+// CambioGuia describe una diferencia entre un documento de la guía y la versión publicada
+type CambioGuia struct {
+	Tipo    string   `json:"tipo"`  // CAPITULO, INDICADOR o NIVELES_SOSTENIBILIDAD
+	Clave   string   `json:"clave"` // clave del capítulo/indicador, o nombre del segmento
+	Nombre  string   `json:"nombre"`
+	Detalle []string `json:"detalle,omitempty"` // qué cambió, en los modificados
+}
+
+// ReporteImportacionGuia resume la importación de un documento de la guía.
+// Al aplicarse, el documento se guarda como una nueva versión en borrador.
+type ReporteImportacionGuia struct {
+	Aplicado         bool         `json:"aplicado"`
+	VersionComparada *GuiaVersion `json:"version_comparada"`
+	Borrador         *GuiaVersion `json:"borrador,omitempty"`
+	Agregados        []CambioGuia `json:"agregados"`
+	Modificados      []CambioGuia `json:"modificados"`
+	Retirados        []CambioGuia `json:"retirados"`
+	SinCambios       int          `json:"sin_cambios"`
+}
+
 // ============================================
 // MODELOS DE EVIDENCIA
 // ============================================
@@ -310,3 +809,158 @@ type ObtenerEvidenciaResponse struct {
 	Evidencia *Evidencia `json:"evidencia,omitempty"`
 	Mensaje   string     `json:"mensaje,omitempty"`
 }
+
+// ============================================
+// MODELOS DE REVISIÓN DE AUDITORES
+// ============================================
+
+// EstadoRevision es el resultado de la revisión de una respuesta o evidencia
+type EstadoRevision string
+
+const (
+	RevisionPendiente EstadoRevision = "PENDIENTE_REVISION"
+	RevisionAprobada  EstadoRevision = "APROBADA"
+	RevisionObservada EstadoRevision = "OBSERVADA"
+	RevisionRechazada EstadoRevision = "RECHAZADA"
+)
+
+// EstadoRevisionAutoevaluacion es la etapa de la revisión de una autoevaluación completada
+type EstadoRevisionAutoevaluacion string
+
+const (
+	RevisionEnCurso                 EstadoRevisionAutoevaluacion = "EN_REVISION"
+	RevisionValidada                EstadoRevisionAutoevaluacion = "VALIDADA"
+	RevisionAutoevaluacionObservada EstadoRevisionAutoevaluacion = "OBSERVADA"
+)
+
+// Auditor es una cuenta AUDITOR con las bodegas que tiene asignadas
+type Auditor struct {
+	ID            int               `json:"id_cuenta"`
+	EmailLogin    string            `json:"email_login"`
+	FechaRegistro time.Time         `json:"fecha_registro"`
+	Bodegas       []*BodegaAsignada `json:"bodegas"`
+}
+
+type BodegaAsignada struct {
+	IDBodega        int       `json:"id_bodega"`
+	NombreFantasia  string    `json:"nombre_fantasia"`
+	FechaAsignacion time.Time `json:"fecha_asignacion"`
+}
+
+type AsignarBodegasRequest struct {
+	IDsBodega []int `json:"ids_bodega"`
+}
+
+// RevisarItemRequest es la revisión de una respuesta o de su evidencia; el comentario es
+// obligatorio si se observa o rechaza
+type RevisarItemRequest struct {
+	Estado     EstadoRevision `json:"estado"`
+	Comentario string         `json:"comentario"`
+}
+
+// FinalizarRevisionRequest cierra la revisión como VALIDADA u OBSERVADA; el comentario es
+// obligatorio si se observa
+type FinalizarRevisionRequest struct {
+	Resultado  EstadoRevisionAutoevaluacion `json:"resultado"`
+	Comentario string                       `json:"comentario"`
+}
+
+// RevisionAutoevaluacion es el estado de la revisión con el puntaje que resultaría de
+// excluir las respuestas rechazadas hasta el momento
+type RevisionAutoevaluacion struct {
+	Autoevaluacion        *AutoevaluacionResumen `json:"autoevaluacion"`
+	Respuestas            []*RespuestaDetalle    `json:"respuestas"`
+	Pendientes            int                    `json:"pendientes"` // respuestas y evidencias sin revisar
+	Capitulos             []*ResultadoCapitulo   `json:"capitulos"`
+	PuntajeValidado       int                    `json:"puntaje_validado"`
+	IDNivelSostenibilidad *int                   `json:"id_nivel_sostenibilidad_validado,omitempty"`
+	NivelSostenibilidad   *string                `json:"nivel_sostenibilidad_validado,omitempty"`
+}
+
+// ============================================
+// PLAN DE ACCIÓN DE MEJORA
+// ============================================
+
+type EstadoAccionMejora string
+
+const (
+	AccionPendiente  EstadoAccionMejora = "PENDIENTE"
+	AccionEnCurso    EstadoAccionMejora = "EN_CURSO"
+	AccionCompletada EstadoAccionMejora = "COMPLETADA"
+	AccionDescartada EstadoAccionMejora = "DESCARTADA"
+)
+
+// LongitudMaximaDescripcionAccion es la cantidad máxima de caracteres de la descripción de
+// una acción de mejora
+const LongitudMaximaDescripcionAccion = 1000
+
+// AccionMejora es una acción del plan de la bodega para llevar un indicador al nivel de
+// respuesta objetivo antes de la fecha límite
+type AccionMejora struct {
+	ID                     int                `json:"id_accion"`
+	IDBodega               int                `json:"id_bodega"`
+	IDIndicador            int                `json:"id_indicador"`
+	ClaveIndicador         string             `json:"clave_indicador"`
+	Indicador              string             `json:"indicador"`
+	IDNivelObjetivo        int                `json:"id_nivel_objetivo"`
+	NivelObjetivo          string             `json:"nivel_objetivo"`
+	PosicionObjetivo       int                `json:"-"`
+	IDResponsable          *int               `json:"id_responsable,omitempty"`
+	Responsable            *string            `json:"responsable,omitempty"` // nombre y apellido
+	IDAutoevaluacionOrigen *int               `json:"id_autoevaluacion_origen,omitempty"`
+	Descripcion            string             `json:"descripcion"`
+	FechaLimite            time.Time          `json:"fecha_limite"`
+	Estado                 EstadoAccionMejora `json:"estado"`
+	Vencida                bool               `json:"vencida"` // sin terminar y con la fecha límite cumplida
+	FechaCreacion          time.Time          `json:"fecha_creacion"`
+	FechaActualizacion     time.Time          `json:"fecha_actualizacion"`
+	FechaCompletada        *time.Time         `json:"fecha_completada,omitempty"`
+}
+
+// AccionMejoraRequest crea o modifica una acción de mejora; FechaLimite tiene formato
+// YYYY-MM-DD. Al crearla, Estado es opcional (PENDIENTE por defecto).
+type AccionMejoraRequest struct {
+	IDIndicador            int                 `json:"id_indicador"`
+	IDNivelObjetivo        int                 `json:"id_nivel_objetivo"`
+	IDResponsable          *int                `json:"id_responsable,omitempty"`
+	IDAutoevaluacionOrigen *int                `json:"id_autoevaluacion_origen,omitempty"`
+	Descripcion            string              `json:"descripcion"`
+	FechaLimite            string              `json:"fecha_limite"`
+	Estado                 *EstadoAccionMejora `json:"estado,omitempty"`
+}
+
+// FiltroAccionesMejora define los criterios del listado del plan de acción de una bodega
+type FiltroAccionesMejora struct {
+	IDBodega       int
+	Estado         *EstadoAccionMejora
+	VencidasAl     *time.Time // solo las sin terminar con fecha límite anterior a esta fecha
+	CreadasDesde   *time.Time // fecha_creacion >= CreadasDesde
+	CreadasHasta   *time.Time // fecha_creacion < CreadasHasta
+	SinDescartadas bool
+}
+
+const (
+	ResultadoAccionLograda      = "LOGRADA"
+	ResultadoAccionNoLograda    = "NO_LOGRADA"
+	ResultadoAccionSinRespuesta = "SIN_RESPUESTA" // el indicador no fue respondido en la autoevaluación
+)
+
+// SeguimientoAccion compara una acción del plan con la respuesta de la autoevaluación que
+// la verifica
+type SeguimientoAccion struct {
+	*AccionMejora
+	NivelAlcanzado   *string `json:"nivel_alcanzado,omitempty"`
+	PuntosAlcanzados *int    `json:"puntos_alcanzados,omitempty"`
+	Resultado        string  `json:"resultado"`
+}
+
+// SeguimientoPlanAccion muestra qué acciones planificadas antes de iniciar la
+// autoevaluación se lograron en ella
+type SeguimientoPlanAccion struct {
+	IDAutoevaluacion int                  `json:"id_autoevaluacion"`
+	Estado           EstadoAutoevaluacion `json:"estado"`
+	Acciones         []*SeguimientoAccion `json:"acciones"`
+	Logradas         int                  `json:"logradas"`
+	NoLogradas       int                  `json:"no_logradas"`
+	SinRespuesta     int                  `json:"sin_respuesta"`
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+type AuditorHandler struct {
+	service *service.AuditorService
+}
+
+func NewAuditorHandler(service *service.AuditorService) *AuditorHandler {
+	return &AuditorHandler{service: service}
+}
+
+// GetAuditores GET /api/admin/auditores
+func (h *AuditorHandler) GetAuditores(w http.ResponseWriter, r *http.Request) {
+	auditores, err := h.service.ListarAuditores(r.Context())
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, auditores)
+}
+
+// CrearAuditor POST /api/admin/auditores
+func (h *AuditorHandler) CrearAuditor(w http.ResponseWriter, r *http.Request) {
+	var req domain.CuentaRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	auditor, err := h.service.CrearAuditor(r.Context(), &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, auditor)
+}
+
+// AsignarBodegas PUT /api/admin/auditores/{id}/bodegas
+func (h *AuditorHandler) AsignarBodegas(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req domain.AsignarBodegasRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.service.AsignarBodegas(r.Context(), id, req.IDsBodega); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Bodegas asignadas"})
+}
@@ -3,8 +3,11 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"coviar_backend/internal/domain"
+	"coviar_backend/internal/middleware"
 	"coviar_backend/internal/service"
 	"coviar_backend/pkg/httputil"
 	"coviar_backend/pkg/router"
@@ -49,6 +52,10 @@ func (h *AutoevaluacionHandler) CreateAutoevaluacion(w http.ResponseWriter, r *h
 		return
 	}
 
+	if response.AutoevaluacionPendiente != nil {
+		httputil.SetETag(w, response.AutoevaluacionPendiente.Version)
+	}
+
 	// Si hay una autoevaluación pendiente, retornar con código 200
 	// Si se creó una nueva, retornar con código 201
 	if response.AutoevaluacionPendiente != nil && response.AutoevaluacionPendiente.Estado == domain.EstadoPendiente && len(response.Respuestas) > 0 {
@@ -70,6 +77,8 @@ func (h *AutoevaluacionHandler) GetSegmentos(w http.ResponseWriter, r *http.Requ
 }
 
 // SeleccionarSegmento PUT /api/autoevaluaciones/{id_autoevaluacion}/segmento
+// Requiere If-Match con el ETag de la autoevaluación. Responde 409 con las respuestas que se
+// eliminarían si el cambio no fue confirmado.
 func (h *AutoevaluacionHandler) SeleccionarSegmento(w http.ResponseWriter, r *http.Request) {
 	idStr := router.GetParam(r, "id_autoevaluacion")
 	id, err := strconv.Atoi(idStr)
@@ -78,18 +87,29 @@ func (h *AutoevaluacionHandler) SeleccionarSegmento(w http.ResponseWriter, r *ht
 		return
 	}
 
+	version, err := httputil.VersionIfMatch(r)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
 	var req domain.SeleccionarSegmentoRequest
 	if err := httputil.DecodeJSON(r, &req); err != nil {
 		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
 		return
 	}
 
-	if err := h.service.SeleccionarSegmento(r.Context(), id, req.IDSegmento); err != nil {
+	cambio, err := h.service.SeleccionarSegmento(r.Context(), id, req.IDSegmento, req.Confirmar, version)
+	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
 
-	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Segmento seleccionado correctamente"})
+	httputil.SetETag(w, cambio.Version)
+	httputil.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"mensaje": "Segmento seleccionado correctamente",
+		"cambio":  cambio,
+	})
 }
 
 // GetEstructura GET /api/autoevaluaciones/{id_autoevaluacion}/estructura
@@ -111,6 +131,7 @@ func (h *AutoevaluacionHandler) GetEstructura(w http.ResponseWriter, r *http.Req
 }
 
 // GuardarRespuestas POST /api/autoevaluaciones/{id_autoevaluacion}/respuestas
+// Requiere If-Match con el ETag de la autoevaluación; 412 si otra sesión la modificó
 func (h *AutoevaluacionHandler) GuardarRespuestas(w http.ResponseWriter, r *http.Request) {
 	idStr := router.GetParam(r, "id_autoevaluacion")
 	id, err := strconv.Atoi(idStr)
@@ -119,25 +140,34 @@ func (h *AutoevaluacionHandler) GuardarRespuestas(w http.ResponseWriter, r *http
 		return
 	}
 
+	version, err := httputil.VersionIfMatch(r)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
 	var req domain.GuardarRespuestasRequest
 	if err := httputil.DecodeJSON(r, &req); err != nil {
 		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
 		return
 	}
 
-	respuestasGuardadas, err := h.service.GuardarRespuestas(r.Context(), id, req.Respuestas)
+	respuestasGuardadas, nuevaVersion, err := h.service.GuardarRespuestas(r.Context(), id, version, req.Respuestas)
 	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
 
+	httputil.SetETag(w, nuevaVersion)
 	httputil.RespondJSON(w, http.StatusOK, map[string]interface{}{
 		"mensaje":    "Respuestas guardadas correctamente",
 		"respuestas": respuestasGuardadas,
+		"version":    nuevaVersion,
 	})
 }
 
 // CompletarAutoevaluacion POST /api/autoevaluaciones/{id_autoevaluacion}/completar
+// Requiere If-Match con el ETag de la autoevaluación
 func (h *AutoevaluacionHandler) CompletarAutoevaluacion(w http.ResponseWriter, r *http.Request) {
 	idStr := router.GetParam(r, "id_autoevaluacion")
 	id, err := strconv.Atoi(idStr)
@@ -146,15 +176,72 @@ func (h *AutoevaluacionHandler) CompletarAutoevaluacion(w http.ResponseWriter, r
 		return
 	}
 
-	if err := h.service.CompletarAutoevaluacion(r.Context(), id); err != nil {
+	version, err := httputil.VersionIfMatch(r)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	nuevaVersion, err := h.service.CompletarAutoevaluacion(r.Context(), id, version)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.SetETag(w, nuevaVersion)
+	httputil.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"mensaje": "Autoevaluación completada correctamente",
+		"version": nuevaVersion,
+	})
+}
+
+// ReabrirAutoevaluacion POST /api/admin/autoevaluaciones/{id_autoevaluacion}/reabrir
+func (h *AutoevaluacionHandler) ReabrirAutoevaluacion(w http.ResponseWriter, r *http.Request) {
+	idStr := router.GetParam(r, "id_autoevaluacion")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req domain.ReabrirAutoevaluacionRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	enmienda, err := h.service.ReabrirAutoevaluacion(r.Context(), id, idCuenta, req.Motivo)
+	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
 
-	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Autoevaluación completada correctamente"})
+	httputil.RespondJSON(w, http.StatusOK, enmienda)
+}
+
+// GetReevaluaciones GET /api/admin/bodegas/reevaluacion?estado=
+// estado acepta varios valores separados por comas (VIGENTE, POR_VENCER, VENCIDA,
+// SIN_EVALUACION); por defecto POR_VENCER,VENCIDA.
+func (h *AutoevaluacionHandler) GetReevaluaciones(w http.ResponseWriter, r *http.Request) {
+	var estados []domain.EstadoVigencia
+	if estadoStr := r.URL.Query().Get("estado"); estadoStr != "" {
+		for _, e := range strings.Split(estadoStr, ",") {
+			estados = append(estados, domain.EstadoVigencia(strings.ToUpper(strings.TrimSpace(e))))
+		}
+	}
+
+	vigencias, err := h.service.ListarReevaluaciones(r.Context(), time.Now(), estados)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, vigencias)
 }
 
 // CancelarAutoevaluacion POST /api/autoevaluaciones/{id_autoevaluacion}/cancelar
+// Requiere If-Match con el ETag de la autoevaluación
 func (h *AutoevaluacionHandler) CancelarAutoevaluacion(w http.ResponseWriter, r *http.Request) {
 	idStr := router.GetParam(r, "id_autoevaluacion")
 	id, err := strconv.Atoi(idStr)
@@ -163,10 +250,180 @@ func (h *AutoevaluacionHandler) CancelarAutoevaluacion(w http.ResponseWriter, r
 		return
 	}
 
-	if err := h.service.CancelarAutoevaluacion(r.Context(), id); err != nil {
+	version, err := httputil.VersionIfMatch(r)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	nuevaVersion, err := h.service.CancelarAutoevaluacion(r.Context(), id, version)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.SetETag(w, nuevaVersion)
+	httputil.RespondJSON(w, http.StatusOK, map[string]interface{}{
+		"mensaje": "Autoevaluación cancelada correctamente",
+		"version": nuevaVersion,
+	})
+}
+
+// GetHistorial GET /api/bodegas/{id}/autoevaluaciones?estado=&desde=&hasta=&pagina=&por_pagina=
+// Las fechas tienen formato YYYY-MM-DD; hasta es inclusiva.
+func (h *AutoevaluacionHandler) GetHistorial(w http.ResponseWriter, r *http.Request) {
+	idBodega, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	query := r.URL.Query()
+	filtro := domain.FiltroAutoevaluaciones{IDBodega: idBodega}
+
+	if estadoStr := query.Get("estado"); estadoStr != "" {
+		estado := domain.EstadoAutoevaluacion(strings.ToUpper(estadoStr))
+		filtro.Estado = &estado
+	}
+	if desdeStr := query.Get("desde"); desdeStr != "" {
+		desde, err := time.Parse("2006-01-02", desdeStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "fecha desde inválida (formato YYYY-MM-DD)")
+			return
+		}
+		filtro.Desde = &desde
+	}
+	if hastaStr := query.Get("hasta"); hastaStr != "" {
+		hasta, err := time.Parse("2006-01-02", hastaStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "fecha hasta inválida (formato YYYY-MM-DD)")
+			return
+		}
+		hasta = hasta.AddDate(0, 0, 1)
+		filtro.Hasta = &hasta
+	}
+	if paginaStr := query.Get("pagina"); paginaStr != "" {
+		if filtro.Pagina, err = strconv.Atoi(paginaStr); err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "página inválida")
+			return
+		}
+	}
+	if porPaginaStr := query.Get("por_pagina"); porPaginaStr != "" {
+		if filtro.PorPagina, err = strconv.Atoi(porPaginaStr); err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "por_pagina inválido")
+			return
+		}
+	}
+
+	historial, err := h.service.GetHistorial(r.Context(), filtro)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, historial)
+}
+
+// GetDetalle GET /api/autoevaluaciones/{id_autoevaluacion}
+func (h *AutoevaluacionHandler) GetDetalle(w http.ResponseWriter, r *http.Request) {
+	idStr := router.GetParam(r, "id_autoevaluacion")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	detalle, err := h.service.GetDetalle(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.SetETag(w, detalle.Version)
+	httputil.RespondJSON(w, http.StatusOK, detalle)
+}
+
+// Comparar GET /api/autoevaluaciones/{id_autoevaluacion}/comparar/{otro_id}
+// Compara la autoevaluación otro_id contra la autoevaluación base id_autoevaluacion.
+func (h *AutoevaluacionHandler) Comparar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	otroID, err := strconv.Atoi(router.GetParam(r, "otro_id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID a comparar inválido")
+		return
+	}
+
+	comparacion, err := h.service.Comparar(r.Context(), id, otroID)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, comparacion)
+}
+
+// GetResultados GET /api/autoevaluaciones/{id_autoevaluacion}/resultados
+func (h *AutoevaluacionHandler) GetResultados(w http.ResponseWriter, r *http.Request) {
+	idStr := router.GetParam(r, "id_autoevaluacion")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	resultados, err := h.service.GetResultados(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, resultados)
+}
+
+// GetProgreso GET /api/autoevaluaciones/{id_autoevaluacion}/progreso
+func (h *AutoevaluacionHandler) GetProgreso(w http.ResponseWriter, r *http.Request) {
+	idStr := router.GetParam(r, "id_autoevaluacion")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	progreso, err := h.service.GetProgreso(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, progreso)
+}
+
+// GetRecomendaciones GET /api/autoevaluaciones/{id_autoevaluacion}/recomendaciones?top=N
+func (h *AutoevaluacionHandler) GetRecomendaciones(w http.ResponseWriter, r *http.Request) {
+	idStr := router.GetParam(r, "id_autoevaluacion")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	top := 0
+	if topStr := r.URL.Query().Get("top"); topStr != "" {
+		if top, err = strconv.Atoi(topStr); err != nil || top < 1 {
+			httputil.RespondError(w, http.StatusBadRequest, "top inválido")
+			return
+		}
+	}
+
+	recomendaciones, err := h.service.GetRecomendaciones(r.Context(), id, top)
+	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
 
-	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Autoevaluación cancelada correctamente"})
+	httputil.RespondJSON(w, http.StatusOK, recomendaciones)
 }
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/guia"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+// tamaño máximo del documento de la guía a importar
+const maxTamanioDocumentoGuia = 8 << 20
+
+type GuiaDocumentoHandler struct {
+	service *service.GuiaDocumentoService
+}
+
+func NewGuiaDocumentoHandler(service *service.GuiaDocumentoService) *GuiaDocumentoHandler {
+	return &GuiaDocumentoHandler{service: service}
+}
+
+// Exportar GET /api/admin/guia/versiones/{id}/exportar?formato=json|yaml
+func (h *GuiaDocumentoHandler) Exportar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	formato := guia.FormatoJSON
+	if f := r.URL.Query().Get("formato"); f != "" {
+		formato, err = guia.ParseFormato(f)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	doc, err := h.service.Exportar(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", formato.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="guia_v%d.%s"`, doc.Version, formato))
+	w.WriteHeader(http.StatusOK)
+	guia.Escribir(w, doc, formato)
+}
+
+// Importar POST /api/admin/guia/importar
+// Formulario multipart con el archivo (.json, .yaml o .yml) y, opcionalmente, descripcion.
+// Sin aplicar=true solo devuelve las diferencias con la versión publicada;
+// con aplicar=true el documento se guarda como una nueva versión en borrador.
+func (h *GuiaDocumentoHandler) Importar(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxTamanioDocumentoGuia); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "Error parseando formulario: "+err.Error())
+		return
+	}
+
+	aplicar := false
+	if aplicarStr := r.FormValue("aplicar"); aplicarStr != "" {
+		valor, err := strconv.ParseBool(aplicarStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "valor de aplicar inválido")
+			return
+		}
+		aplicar = valor
+	}
+
+	file, header, err := r.FormFile("archivo")
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "falta el archivo")
+		return
+	}
+	defer file.Close()
+
+	formato, err := guia.FormatoDeArchivo(header.Filename)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	doc, err := guia.Leer(file, formato)
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reporte, err := h.service.Importar(r.Context(), doc, r.FormValue("descripcion"), aplicar)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, reporte)
+}
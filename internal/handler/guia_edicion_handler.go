@@ -0,0 +1,357 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+// GuiaEdicionHandler expone la edición del cuestionario de una versión de la guía.
+// Todas las rutas cuelgan de /api/admin/guia/versiones/{id}.
+type GuiaEdicionHandler struct {
+	service *service.GuiaEdicionService
+}
+
+func NewGuiaEdicionHandler(service *service.GuiaEdicionService) *GuiaEdicionHandler {
+	return &GuiaEdicionHandler{service: service}
+}
+
+// paramsInt lee los parámetros enteros de la ruta; si alguno es inválido responde 400
+func paramsInt(w http.ResponseWriter, r *http.Request, nombres ...string) ([]int, bool) {
+	valores := make([]int, 0, len(nombres))
+	for _, nombre := range nombres {
+		valor, err := strconv.Atoi(router.GetParam(r, nombre))
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, nombre+" inválido")
+			return nil, false
+		}
+		valores = append(valores, valor)
+	}
+	return valores, true
+}
+
+// GetContenido GET /api/admin/guia/versiones/{id}
+func (h *GuiaEdicionHandler) GetContenido(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	contenido, err := h.service.GetContenido(r.Context(), ids[0])
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, contenido)
+}
+
+// CrearCapitulo POST /api/admin/guia/versiones/{id}/capitulos
+func (h *GuiaEdicionHandler) CrearCapitulo(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.CapituloRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	capitulo, err := h.service.CrearCapitulo(r.Context(), ids[0], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, capitulo)
+}
+
+// ModificarCapitulo PUT /api/admin/guia/versiones/{id}/capitulos/{id_capitulo}
+func (h *GuiaEdicionHandler) ModificarCapitulo(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_capitulo")
+	if !ok {
+		return
+	}
+
+	var req domain.CapituloRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	capitulo, err := h.service.ModificarCapitulo(r.Context(), ids[0], ids[1], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, capitulo)
+}
+
+// ReordenarCapitulos PUT /api/admin/guia/versiones/{id}/capitulos/orden
+func (h *GuiaEdicionHandler) ReordenarCapitulos(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.OrdenRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.service.ReordenarCapitulos(r.Context(), ids[0], req.IDs); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Capítulos reordenados correctamente"})
+}
+
+// RetirarCapitulo DELETE /api/admin/guia/versiones/{id}/capitulos/{id_capitulo}
+func (h *GuiaEdicionHandler) RetirarCapitulo(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_capitulo")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RetirarCapitulo(r.Context(), ids[0], ids[1]); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Capítulo retirado correctamente"})
+}
+
+// CrearIndicador POST /api/admin/guia/versiones/{id}/capitulos/{id_capitulo}/indicadores
+func (h *GuiaEdicionHandler) CrearIndicador(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_capitulo")
+	if !ok {
+		return
+	}
+
+	var req domain.IndicadorRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	indicador, err := h.service.CrearIndicador(r.Context(), ids[0], ids[1], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, indicador)
+}
+
+// ReordenarIndicadores PUT /api/admin/guia/versiones/{id}/capitulos/{id_capitulo}/indicadores/orden
+func (h *GuiaEdicionHandler) ReordenarIndicadores(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_capitulo")
+	if !ok {
+		return
+	}
+
+	var req domain.OrdenRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.service.ReordenarIndicadores(r.Context(), ids[0], ids[1], req.IDs); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Indicadores reordenados correctamente"})
+}
+
+// ModificarIndicador PUT /api/admin/guia/versiones/{id}/indicadores/{id_indicador}
+func (h *GuiaEdicionHandler) ModificarIndicador(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_indicador")
+	if !ok {
+		return
+	}
+
+	var req domain.IndicadorRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	indicador, err := h.service.ModificarIndicador(r.Context(), ids[0], ids[1], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, indicador)
+}
+
+// RetirarIndicador DELETE /api/admin/guia/versiones/{id}/indicadores/{id_indicador}
+func (h *GuiaEdicionHandler) RetirarIndicador(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_indicador")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RetirarIndicador(r.Context(), ids[0], ids[1]); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Indicador retirado correctamente"})
+}
+
+// AsignarSegmentos PUT /api/admin/guia/versiones/{id}/indicadores/{id_indicador}/segmentos
+func (h *GuiaEdicionHandler) AsignarSegmentos(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_indicador")
+	if !ok {
+		return
+	}
+
+	var req domain.SegmentosIndicadorRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	segmentos, err := h.service.AsignarSegmentos(r.Context(), ids[0], ids[1], req.Segmentos)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, domain.SegmentosIndicadorRequest{Segmentos: segmentos})
+}
+
+// CrearNivelRespuesta POST /api/admin/guia/versiones/{id}/indicadores/{id_indicador}/niveles-respuesta
+func (h *GuiaEdicionHandler) CrearNivelRespuesta(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_indicador")
+	if !ok {
+		return
+	}
+
+	var req domain.NivelRespuestaRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	nivel, err := h.service.CrearNivelRespuesta(r.Context(), ids[0], ids[1], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, nivel)
+}
+
+// ReordenarNivelesRespuesta PUT /api/admin/guia/versiones/{id}/indicadores/{id_indicador}/niveles-respuesta/orden
+func (h *GuiaEdicionHandler) ReordenarNivelesRespuesta(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_indicador")
+	if !ok {
+		return
+	}
+
+	var req domain.OrdenRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	if err := h.service.ReordenarNivelesRespuesta(r.Context(), ids[0], ids[1], req.IDs); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Niveles de respuesta reordenados correctamente"})
+}
+
+// ModificarNivelRespuesta PUT /api/admin/guia/versiones/{id}/niveles-respuesta/{id_nivel_respuesta}
+func (h *GuiaEdicionHandler) ModificarNivelRespuesta(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_nivel_respuesta")
+	if !ok {
+		return
+	}
+
+	var req domain.NivelRespuestaRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	nivel, err := h.service.ModificarNivelRespuesta(r.Context(), ids[0], ids[1], &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, nivel)
+}
+
+// RetirarNivelRespuesta DELETE /api/admin/guia/versiones/{id}/niveles-respuesta/{id_nivel_respuesta}
+func (h *GuiaEdicionHandler) RetirarNivelRespuesta(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_nivel_respuesta")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RetirarNivelRespuesta(r.Context(), ids[0], ids[1]); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Nivel de respuesta retirado correctamente"})
+}
+
+// ReemplazarNivelesSostenibilidad PUT /api/admin/guia/versiones/{id}/segmentos/{id_segmento}/niveles-sostenibilidad
+func (h *GuiaEdicionHandler) ReemplazarNivelesSostenibilidad(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id", "id_segmento")
+	if !ok {
+		return
+	}
+
+	var req domain.NivelesSostenibilidadRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	niveles, err := h.service.ReemplazarNivelesSostenibilidad(r.Context(), ids[0], ids[1], req.Niveles)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, niveles)
+}
+
+// CambiarEstrategiaPuntaje PUT /api/admin/guia/versiones/{id}/estrategia-puntaje
+func (h *GuiaEdicionHandler) CambiarEstrategiaPuntaje(w http.ResponseWriter, r *http.Request) {
+	ids, ok := paramsInt(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req domain.EstrategiaPuntajeRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	version, err := h.service.CambiarEstrategiaPuntaje(r.Context(), ids[0], req.EstrategiaPuntaje)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, version)
+}
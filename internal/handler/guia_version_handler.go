@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+type GuiaVersionHandler struct {
+	service *service.GuiaVersionService
+}
+
+func NewGuiaVersionHandler(service *service.GuiaVersionService) *GuiaVersionHandler {
+	return &GuiaVersionHandler{service: service}
+}
+
+// GetVersiones GET /api/admin/guia/versiones
+func (h *GuiaVersionHandler) GetVersiones(w http.ResponseWriter, r *http.Request) {
+	versiones, err := h.service.GetVersiones(r.Context())
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, versiones)
+}
+
+// CrearBorrador POST /api/admin/guia/versiones
+func (h *GuiaVersionHandler) CrearBorrador(w http.ResponseWriter, r *http.Request) {
+	var req domain.CrearBorradorGuiaRequest
+	if r.ContentLength != 0 {
+		if err := httputil.DecodeJSON(r, &req); err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+			return
+		}
+	}
+
+	version, err := h.service.CrearBorrador(r.Context(), req.Descripcion)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, version)
+}
+
+// Publicar POST /api/admin/guia/versiones/{id}/publicar
+func (h *GuiaVersionHandler) Publicar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	version, err := h.service.Publicar(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, version)
+}
+
+// DescartarBorrador DELETE /api/admin/guia/versiones/{id}
+func (h *GuiaVersionHandler) DescartarBorrador(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if err := h.service.DescartarBorrador(r.Context(), id); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Borrador descartado correctamente"})
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/georef"
+	"coviar_backend/pkg/httputil"
+)
+
+// tamaño máximo del formulario de importación (los catálogos completos rondan los 10 MB)
+const maxTamanioImportacionUbicaciones = 32 << 20
+
+type ImportacionUbicacionesHandler struct {
+	service          *service.ImportacionUbicacionesService
+	ubicacionService *service.UbicacionService
+}
+
+func NewImportacionUbicacionesHandler(service *service.ImportacionUbicacionesService, ubicacionService *service.UbicacionService) *ImportacionUbicacionesHandler {
+	return &ImportacionUbicacionesHandler{service: service, ubicacionService: ubicacionService}
+}
+
+// Importar POST /api/admin/ubicaciones/importar
+// Formulario multipart con los archivos provincias, departamentos y localidades.
+// Sin aplicar=true solo devuelve el reporte de cambios.
+func (h *ImportacionUbicacionesHandler) Importar(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(maxTamanioImportacionUbicaciones); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "Error parseando formulario: "+err.Error())
+		return
+	}
+
+	aplicar := false
+	if aplicarStr := r.FormValue("aplicar"); aplicarStr != "" {
+		valor, err := strconv.ParseBool(aplicarStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "valor de aplicar inválido")
+			return
+		}
+		aplicar = valor
+	}
+
+	fuentes := make([]georef.Fuente, 0, 3)
+	for _, campo := range []string{"provincias", "departamentos", "localidades"} {
+		file, header, err := r.FormFile(campo)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "falta el archivo de "+campo)
+			return
+		}
+		defer func(f multipart.File) { f.Close() }(file)
+		fuentes = append(fuentes, georef.Fuente{Nombre: header.Filename, Datos: file})
+	}
+
+	catalogo, err := georef.LeerCatalogoDesde(fuentes[0], fuentes[1], fuentes[2])
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reporte, err := h.service.ImportarGeoref(r.Context(), catalogo, aplicar)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, reporte)
+}
+
+// Recargar POST /api/admin/ubicaciones/recargar
+// Vuelve a leer el catálogo desde la base (por ejemplo, luego de importar por CLI).
+func (h *ImportacionUbicacionesHandler) Recargar(w http.ResponseWriter, r *http.Request) {
+	if err := h.ubicacionService.Recargar(r.Context()); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{
+		"mensaje": "Catálogo de ubicaciones recargado",
+	})
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+type PlanAccionHandler struct {
+	service *service.PlanAccionService
+}
+
+func NewPlanAccionHandler(service *service.PlanAccionService) *PlanAccionHandler {
+	return &PlanAccionHandler{service: service}
+}
+
+// Listar GET /api/bodegas/{id}/plan-accion?estado=&vencidas=true
+// vencidas=true devuelve solo las acciones sin terminar con la fecha límite cumplida
+func (h *PlanAccionHandler) Listar(w http.ResponseWriter, r *http.Request) {
+	idBodega, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	ahora := time.Now()
+	query := r.URL.Query()
+	filtro := domain.FiltroAccionesMejora{IDBodega: idBodega}
+
+	if estadoStr := query.Get("estado"); estadoStr != "" {
+		estado := domain.EstadoAccionMejora(strings.ToUpper(estadoStr))
+		filtro.Estado = &estado
+	}
+	if vencidasStr := query.Get("vencidas"); vencidasStr != "" {
+		vencidas, err := strconv.ParseBool(vencidasStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "vencidas inválido")
+			return
+		}
+		if vencidas {
+			filtro.VencidasAl = &ahora
+		}
+	}
+
+	acciones, err := h.service.Listar(r.Context(), filtro, ahora)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, acciones)
+}
+
+// Crear POST /api/bodegas/{id}/plan-accion
+func (h *PlanAccionHandler) Crear(w http.ResponseWriter, r *http.Request) {
+	idBodega, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req domain.AccionMejoraRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	accion, err := h.service.Crear(r.Context(), idBodega, &req, time.Now())
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusCreated, accion)
+}
+
+// Modificar PUT /api/bodegas/{id}/plan-accion/{id_accion}
+func (h *PlanAccionHandler) Modificar(w http.ResponseWriter, r *http.Request) {
+	idBodega, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	idAccion, err := strconv.Atoi(router.GetParam(r, "id_accion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID de acción inválido")
+		return
+	}
+
+	var req domain.AccionMejoraRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	accion, err := h.service.Modificar(r.Context(), idBodega, idAccion, &req, time.Now())
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, accion)
+}
+
+// GetSeguimiento GET /api/autoevaluaciones/{id_autoevaluacion}/plan-accion
+// Muestra qué acciones planificadas antes de la autoevaluación se lograron en ella
+func (h *PlanAccionHandler) GetSeguimiento(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	seguimiento, err := h.service.GetSeguimiento(r.Context(), id, time.Now())
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, seguimiento)
+}
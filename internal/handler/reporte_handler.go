@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+type ReporteHandler struct {
+	service *service.ReporteService
+}
+
+func NewReporteHandler(service *service.ReporteService) *ReporteHandler {
+	return &ReporteHandler{service: service}
+}
+
+// DescargarReporte GET /api/autoevaluaciones/{id_autoevaluacion}/reporte.pdf
+func (h *ReporteHandler) DescargarReporte(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	pdf, err := h.service.GenerarReporte(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	responderPDF(w, fmt.Sprintf("reporte_autoevaluacion_%d.pdf", id), pdf)
+}
+
+// DescargarCertificado GET /api/autoevaluaciones/{id_autoevaluacion}/certificado.pdf
+func (h *ReporteHandler) DescargarCertificado(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	pdf, err := h.service.GenerarCertificado(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	responderPDF(w, fmt.Sprintf("certificado_autoevaluacion_%d.pdf", id), pdf)
+}
+
+func responderPDF(w http.ResponseWriter, nombre string, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, nombre))
+	w.Header().Set("Content-Length", strconv.Itoa(len(pdf)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdf)
+}
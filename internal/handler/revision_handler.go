@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/middleware"
+	"coviar_backend/internal/service"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/router"
+)
+
+type RevisionHandler struct {
+	service *service.RevisionService
+}
+
+func NewRevisionHandler(service *service.RevisionService) *RevisionHandler {
+	return &RevisionHandler{service: service}
+}
+
+// GetAsignadas GET /api/auditor/autoevaluaciones?estado_revision=
+func (h *RevisionHandler) GetAsignadas(w http.ResponseWriter, r *http.Request) {
+	var estadoRevision *domain.EstadoRevisionAutoevaluacion
+	if estadoStr := r.URL.Query().Get("estado_revision"); estadoStr != "" {
+		estado := domain.EstadoRevisionAutoevaluacion(strings.ToUpper(estadoStr))
+		estadoRevision = &estado
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	autoevaluaciones, err := h.service.ListarAsignadas(r.Context(), idCuenta, estadoRevision)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, autoevaluaciones)
+}
+
+// GetRevision GET /api/auditor/autoevaluaciones/{id_autoevaluacion}/revision
+func (h *RevisionHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	revision, err := h.service.GetRevision(r.Context(), id, idCuenta)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, revision)
+}
+
+// IniciarRevision POST /api/auditor/autoevaluaciones/{id_autoevaluacion}/revision
+func (h *RevisionHandler) IniciarRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	revision, err := h.service.IniciarRevision(r.Context(), id, idCuenta)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, revision)
+}
+
+// RevisarRespuesta PUT /api/auditor/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/revision
+func (h *RevisionHandler) RevisarRespuesta(w http.ResponseWriter, r *http.Request) {
+	h.revisarItem(w, r, h.service.RevisarRespuesta)
+}
+
+// RevisarEvidencia PUT /api/auditor/autoevaluaciones/{id_autoevaluacion}/respuestas/{id_respuesta}/evidencia/revision
+func (h *RevisionHandler) RevisarEvidencia(w http.ResponseWriter, r *http.Request) {
+	h.revisarItem(w, r, h.service.RevisarEvidencia)
+}
+
+func (h *RevisionHandler) revisarItem(w http.ResponseWriter, r *http.Request, revisar func(ctx context.Context, idAutoevaluacion, idRespuesta, idCuenta int, req *domain.RevisarItemRequest) error) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+	idRespuesta, err := strconv.Atoi(router.GetParam(r, "id_respuesta"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID de respuesta inválido")
+		return
+	}
+
+	var req domain.RevisarItemRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	if err := revisar(r.Context(), id, idRespuesta, idCuenta, &req); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, map[string]string{"mensaje": "Revisión registrada"})
+}
+
+// FinalizarRevision POST /api/auditor/autoevaluaciones/{id_autoevaluacion}/revision/finalizar
+func (h *RevisionHandler) FinalizarRevision(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id_autoevaluacion"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	var req domain.FinalizarRevisionRequest
+	if err := httputil.DecodeJSON(r, &req); err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+
+	idCuenta := r.Context().Value(middleware.UserIDKey).(int)
+	resumen, err := h.service.FinalizarRevision(r.Context(), id, idCuenta, &req)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	httputil.RespondJSON(w, http.StatusOK, resumen)
+}
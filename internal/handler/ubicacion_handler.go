@@ -9,6 +9,10 @@ import (
 	"coviar_backend/pkg/router"
 )
 
+// maxAgeCatalogo es el tiempo (en segundos) que los clientes pueden reutilizar
+// el catálogo de ubicaciones sin revalidarlo
+const maxAgeCatalogo = 300
+
 type UbicacionHandler struct {
 	service *service.UbicacionService
 }
@@ -20,12 +24,12 @@ func NewUbicacionHandler(service *service.UbicacionService) *UbicacionHandler {
 // ===== PROVINCIAS =====
 
 func (h *UbicacionHandler) GetProvincias(w http.ResponseWriter, r *http.Request) {
-	provincias, err := h.service.GetProvincias(r.Context())
+	provincias, err := h.service.GetProvinciasJSON(r.Context())
 	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, provincias)
+	httputil.RespondCacheable(w, r, provincias, maxAgeCatalogo)
 }
 
 func (h *UbicacionHandler) GetProvinciaByID(w http.ResponseWriter, r *http.Request) {
@@ -40,7 +44,28 @@ func (h *UbicacionHandler) GetProvinciaByID(w http.ResponseWriter, r *http.Reque
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, provincia)
+	httputil.RespondJSONCacheable(w, r, provincia, maxAgeCatalogo)
+}
+
+// GetDepartamentosByProvincia GET /api/provincias/{id}/departamentos
+func (h *UbicacionHandler) GetDepartamentosByProvincia(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if _, err := h.service.GetProvinciaByID(r.Context(), id); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	departamentos, err := h.service.GetDepartamentosByProvinciaID(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+	httputil.RespondJSONCacheable(w, r, departamentos, maxAgeCatalogo)
 }
 
 // ===== DEPARTAMENTOS =====
@@ -59,16 +84,16 @@ func (h *UbicacionHandler) GetDepartamentos(w http.ResponseWriter, r *http.Reque
 			httputil.HandleServiceError(w, err)
 			return
 		}
-		httputil.RespondJSON(w, http.StatusOK, departamentos)
+		httputil.RespondJSONCacheable(w, r, departamentos, maxAgeCatalogo)
 		return
 	}
 
-	departamentos, err := h.service.GetDepartamentos(r.Context())
+	departamentos, err := h.service.GetDepartamentosJSON(r.Context())
 	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, departamentos)
+	httputil.RespondCacheable(w, r, departamentos, maxAgeCatalogo)
 }
 
 func (h *UbicacionHandler) GetDepartamentoByID(w http.ResponseWriter, r *http.Request) {
@@ -83,7 +108,28 @@ func (h *UbicacionHandler) GetDepartamentoByID(w http.ResponseWriter, r *http.Re
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, departamento)
+	httputil.RespondJSONCacheable(w, r, departamento, maxAgeCatalogo)
+}
+
+// GetLocalidadesByDepartamento GET /api/departamentos/{id}/localidades
+func (h *UbicacionHandler) GetLocalidadesByDepartamento(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(router.GetParam(r, "id"))
+	if err != nil {
+		httputil.RespondError(w, http.StatusBadRequest, "ID inválido")
+		return
+	}
+
+	if _, err := h.service.GetDepartamentoByID(r.Context(), id); err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+
+	localidades, err := h.service.GetLocalidadesByDepartamentoID(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+	httputil.RespondJSONCacheable(w, r, localidades, maxAgeCatalogo)
 }
 
 // ===== LOCALIDADES =====
@@ -102,18 +148,20 @@ func (h *UbicacionHandler) GetLocalidades(w http.ResponseWriter, r *http.Request
 			httputil.HandleServiceError(w, err)
 			return
 		}
-		httputil.RespondJSON(w, http.StatusOK, localidades)
+		httputil.RespondJSONCacheable(w, r, localidades, maxAgeCatalogo)
 		return
 	}
 
-	localidades, err := h.service.GetLocalidades(r.Context())
+	localidades, err := h.service.GetLocalidadesJSON(r.Context())
 	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, localidades)
+	httputil.RespondCacheable(w, r, localidades, maxAgeCatalogo)
 }
 
+// GetLocalidadByID GET /api/localidades/{id}
+// Devuelve la localidad con su departamento y provincia.
 func (h *UbicacionHandler) GetLocalidadByID(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(router.GetParam(r, "id"))
 	if err != nil {
@@ -121,10 +169,42 @@ func (h *UbicacionHandler) GetLocalidadByID(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	localidad, err := h.service.GetLocalidadByID(r.Context(), id)
+	localidad, err := h.service.GetLocalidadDetalle(r.Context(), id)
+	if err != nil {
+		httputil.HandleServiceError(w, err)
+		return
+	}
+	httputil.RespondJSONCacheable(w, r, localidad, maxAgeCatalogo)
+}
+
+// BuscarLocalidades GET /api/localidades/buscar?q=&provincia=&limite=
+func (h *UbicacionHandler) BuscarLocalidades(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	provinciaID := 0
+	if provinciaIDStr := query.Get("provincia"); provinciaIDStr != "" {
+		id, err := strconv.Atoi(provinciaIDStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "ID de provincia inválido")
+			return
+		}
+		provinciaID = id
+	}
+
+	limite := 0
+	if limiteStr := query.Get("limite"); limiteStr != "" {
+		l, err := strconv.Atoi(limiteStr)
+		if err != nil {
+			httputil.RespondError(w, http.StatusBadRequest, "límite inválido")
+			return
+		}
+		limite = l
+	}
+
+	localidades, err := h.service.BuscarLocalidades(r.Context(), query.Get("q"), provinciaID, limite)
 	if err != nil {
 		httputil.HandleServiceError(w, err)
 		return
 	}
-	httputil.RespondJSON(w, http.StatusOK, localidad)
+	httputil.RespondJSONCacheable(w, r, localidades, maxAgeCatalogo)
 }
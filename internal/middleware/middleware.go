@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	"coviar_backend/internal/domain"
 	"coviar_backend/pkg/jwt"
 )
 
@@ -42,7 +43,8 @@ func CORS(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Cookie")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Cookie, If-Match")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag")
 		w.Header().Set("Access-Control-Max-Age", "86400")
 
 		if r.Method == http.MethodOptions {
@@ -104,3 +106,24 @@ func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// RequireTipoCuenta restringe el acceso a los tipos de cuenta indicados.
+// Debe usarse después de AuthMiddleware.
+func RequireTipoCuenta(tipos ...domain.TipoCuenta) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userTipo, _ := r.Context().Value(UserTipoKey).(string)
+			for _, tipo := range tipos {
+				if userTipo == string(tipo) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			log.Printf("⛔ Acceso denegado para tipo de cuenta %q en %s", userTipo, r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"no tiene permisos para acceder a este recurso"}`))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type AccionMejoraRepository struct {
+	db *sql.DB
+}
+
+func NewAccionMejoraRepository(db *sql.DB) repository.AccionMejoraRepository {
+	return &AccionMejoraRepository{db: db}
+}
+
+// selectAccion trae la acción con la clave y el nombre del indicador, el nivel objetivo y
+// el nombre del responsable
+const selectAccion = `
+	SELECT a.id_accion, a.id_bodega, a.id_indicador, i.clave, i.nombre, a.id_nivel_objetivo, nr.nombre,
+	       COALESCE(nr.posicion, 0), a.id_responsable, r.nombre || ' ' || r.apellido, a.id_autoevaluacion_origen,
+	       a.descripcion, a.fecha_limite, a.estado, a.fecha_creacion, a.fecha_actualizacion, a.fecha_completada
+	FROM acciones_mejora a
+	INNER JOIN indicadores i ON a.id_indicador = i.id_indicador
+	INNER JOIN niveles_respuesta nr ON a.id_nivel_objetivo = nr.id_nivel_respuesta
+	LEFT JOIN responsables r ON a.id_responsable = r.id_responsable`
+
+func scanAccion(row scanner) (*domain.AccionMejora, error) {
+	a := &domain.AccionMejora{}
+	err := row.Scan(
+		&a.ID, &a.IDBodega, &a.IDIndicador, &a.ClaveIndicador, &a.Indicador, &a.IDNivelObjetivo, &a.NivelObjetivo,
+		&a.PosicionObjetivo, &a.IDResponsable, &a.Responsable, &a.IDAutoevaluacionOrigen,
+		&a.Descripcion, &a.FechaLimite, &a.Estado, &a.FechaCreacion, &a.FechaActualizacion, &a.FechaCompletada,
+	)
+	return a, err
+}
+
+func (r *AccionMejoraRepository) Create(ctx context.Context, tx repository.Transaction, accion *domain.AccionMejora) (int, error) {
+	query := `
+		INSERT INTO acciones_mejora (id_bodega, id_indicador, id_nivel_objetivo, id_responsable, id_autoevaluacion_origen,
+		                             descripcion, fecha_limite, estado, fecha_completada)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id_accion, fecha_creacion, fecha_actualizacion
+	`
+
+	err := conn(r.db, tx).QueryRowContext(ctx, query,
+		accion.IDBodega, accion.IDIndicador, accion.IDNivelObjetivo, accion.IDResponsable, accion.IDAutoevaluacionOrigen,
+		accion.Descripcion, accion.FechaLimite, accion.Estado, accion.FechaCompletada,
+	).Scan(&accion.ID, &accion.FechaCreacion, &accion.FechaActualizacion)
+	if err != nil {
+		return 0, fmt.Errorf("error creating accion de mejora: %w", err)
+	}
+
+	return accion.ID, nil
+}
+
+func (r *AccionMejoraRepository) FindByID(ctx context.Context, id int) (*domain.AccionMejora, error) {
+	accion, err := scanAccion(r.db.QueryRowContext(ctx, selectAccion+` WHERE a.id_accion = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding accion de mejora: %w", err)
+	}
+	return accion, nil
+}
+
+func (r *AccionMejoraRepository) Update(ctx context.Context, tx repository.Transaction, accion *domain.AccionMejora) error {
+	query := `
+		UPDATE acciones_mejora
+		SET id_indicador = $1, id_nivel_objetivo = $2, id_responsable = $3, id_autoevaluacion_origen = $4,
+		    descripcion = $5, fecha_limite = $6, estado = $7, fecha_completada = $8, fecha_actualizacion = NOW()
+		WHERE id_accion = $9
+	`
+
+	res, err := conn(r.db, tx).ExecContext(ctx, query,
+		accion.IDIndicador, accion.IDNivelObjetivo, accion.IDResponsable, accion.IDAutoevaluacionOrigen,
+		accion.Descripcion, accion.FechaLimite, accion.Estado, accion.FechaCompletada, accion.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating accion de mejora: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindByBodega devuelve el plan de acción de la bodega según el filtro, de la fecha límite
+// más próxima a la más lejana
+func (r *AccionMejoraRepository) FindByBodega(ctx context.Context, filtro domain.FiltroAccionesMejora) ([]*domain.AccionMejora, error) {
+	where := []string{"a.id_bodega = $1"}
+	args := []interface{}{filtro.IDBodega}
+
+	if filtro.Estado != nil {
+		args = append(args, string(*filtro.Estado))
+		where = append(where, fmt.Sprintf("a.estado = $%d", len(args)))
+	}
+	if filtro.VencidasAl != nil {
+		args = append(args, *filtro.VencidasAl)
+		where = append(where, fmt.Sprintf("a.fecha_limite < $%d AND a.estado IN ('PENDIENTE', 'EN_CURSO')", len(args)))
+	}
+	if filtro.CreadasDesde != nil {
+		args = append(args, *filtro.CreadasDesde)
+		where = append(where, fmt.Sprintf("a.fecha_creacion >= $%d", len(args)))
+	}
+	if filtro.CreadasHasta != nil {
+		args = append(args, *filtro.CreadasHasta)
+		where = append(where, fmt.Sprintf("a.fecha_creacion < $%d", len(args)))
+	}
+	if filtro.SinDescartadas {
+		where = append(where, "a.estado <> 'DESCARTADA'")
+	}
+
+	query := selectAccion + " WHERE " + strings.Join(where, " AND ") + " ORDER BY a.fecha_limite, a.id_accion"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying acciones de mejora: %w", err)
+	}
+	defer rows.Close()
+
+	acciones := make([]*domain.AccionMejora, 0)
+	for rows.Next() {
+		accion, err := scanAccion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning accion de mejora: %w", err)
+		}
+		acciones = append(acciones, accion)
+	}
+
+	return acciones, rows.Err()
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type AuditorRepository struct {
+	db *sql.DB
+}
+
+func NewAuditorRepository(db *sql.DB) repository.AuditorRepository {
+	return &AuditorRepository{db: db}
+}
+
+// FindAll devuelve las cuentas AUDITOR con sus bodegas asignadas
+func (r *AuditorRepository) FindAll(ctx context.Context) ([]*domain.Auditor, error) {
+	query := `
+		SELECT c.id_cuenta, c.email_login, c.fecha_registro, b.id_bodega, b.nombre_fantasia, ab.fecha_asignacion
+		FROM cuentas c
+		LEFT JOIN auditores_bodega ab ON ab.id_cuenta = c.id_cuenta
+		LEFT JOIN bodegas b ON ab.id_bodega = b.id_bodega
+		WHERE c.tipo = $1
+		ORDER BY c.email_login, b.nombre_fantasia
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.TipoCuentaAuditor)
+	if err != nil {
+		return nil, fmt.Errorf("error querying auditores: %w", err)
+	}
+	defer rows.Close()
+
+	auditores := make([]*domain.Auditor, 0)
+	var actual *domain.Auditor
+	for rows.Next() {
+		var (
+			a               domain.Auditor
+			idBodega        sql.NullInt64
+			nombreFantasia  sql.NullString
+			fechaAsignacion sql.NullTime
+		)
+		if err := rows.Scan(&a.ID, &a.EmailLogin, &a.FechaRegistro, &idBodega, &nombreFantasia, &fechaAsignacion); err != nil {
+			return nil, fmt.Errorf("error scanning auditor: %w", err)
+		}
+		if actual == nil || actual.ID != a.ID {
+			actual = &a
+			actual.Bodegas = make([]*domain.BodegaAsignada, 0)
+			auditores = append(auditores, actual)
+		}
+		if idBodega.Valid {
+			actual.Bodegas = append(actual.Bodegas, &domain.BodegaAsignada{
+				IDBodega:        int(idBodega.Int64),
+				NombreFantasia:  nombreFantasia.String,
+				FechaAsignacion: fechaAsignacion.Time,
+			})
+		}
+	}
+
+	return auditores, rows.Err()
+}
+
+// ReplaceBodegas reemplaza las bodegas asignadas al auditor; las que siguen asignadas
+// conservan su fecha de asignación
+func (r *AuditorRepository) ReplaceBodegas(ctx context.Context, tx repository.Transaction, idCuenta int, idsBodega []int) error {
+	q := conn(r.db, tx)
+
+	ids := make([]int64, 0, len(idsBodega))
+	for _, id := range idsBodega {
+		ids = append(ids, int64(id))
+	}
+
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM auditores_bodega WHERE id_cuenta = $1 AND NOT (id_bodega = ANY($2))
+	`, idCuenta, pq.Array(ids)); err != nil {
+		return fmt.Errorf("error deleting auditores_bodega: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO auditores_bodega (id_cuenta, id_bodega)
+		SELECT $1, unnest($2::integer[])
+		ON CONFLICT (id_cuenta, id_bodega) DO NOTHING
+	`, idCuenta, pq.Array(ids)); err != nil {
+		return fmt.Errorf("error inserting auditores_bodega: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditorRepository) EstaAsignado(ctx context.Context, idCuenta int, idBodega int) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM auditores_bodega WHERE id_cuenta = $1 AND id_bodega = $2)`
+
+	var asignado bool
+	if err := r.db.QueryRowContext(ctx, query, idCuenta, idBodega).Scan(&asignado); err != nil {
+		return false, fmt.Errorf("error checking auditores_bodega: %w", err)
+	}
+
+	return asignado, nil
+}
+
+// FindAutoevaluacionesAsignadas devuelve las autoevaluaciones completadas de las bodegas
+// asignadas al auditor, opcionalmente filtradas por estado de revisión; las que todavía no
+// fueron tomadas primero y luego las más recientes
+func (r *AuditorRepository) FindAutoevaluacionesAsignadas(ctx context.Context, idCuenta int, estadoRevision *domain.EstadoRevisionAutoevaluacion) ([]*domain.AutoevaluacionResumen, error) {
+	query := selectResumen + `
+		INNER JOIN auditores_bodega ab ON ab.id_bodega = a.id_bodega AND ab.id_cuenta = $1
+		WHERE a.estado = $2 AND ($3::estado_revision_autoevaluacion IS NULL OR a.estado_revision = $3)
+		ORDER BY a.estado_revision NULLS FIRST, a.fecha_fin DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idCuenta, domain.EstadoCompletada, estadoRevision)
+	if err != nil {
+		return nil, fmt.Errorf("error querying autoevaluaciones asignadas: %w", err)
+	}
+	defer rows.Close()
+
+	resumenes := make([]*domain.AutoevaluacionResumen, 0)
+	for rows.Next() {
+		res, err := scanResumen(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning autoevaluacion: %w", err)
+		}
+		resumenes = append(resumenes, res)
+	}
+
+	return resumenes, rows.Err()
+}
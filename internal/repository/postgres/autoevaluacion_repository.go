@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"coviar_backend/internal/domain"
@@ -20,13 +21,13 @@ func NewAutoevaluacionRepository(db *sql.DB) repository.AutoevaluacionRepository
 
 func (r *AutoevaluacionRepository) Create(ctx context.Context, tx repository.Transaction, auto *domain.Autoevaluacion) (int, error) {
 	query := `
-		INSERT INTO autoevaluaciones (fecha_inicio, estado, id_bodega)
-		VALUES (NOW(), $1, $2)
-		RETURNING id_autoevaluacion
+		INSERT INTO autoevaluaciones (fecha_inicio, estado, id_bodega, id_guia_version)
+		VALUES (NOW(), $1, $2, $3)
+		RETURNING id_autoevaluacion, version
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query, domain.EstadoPendiente, auto.IDBodega).Scan(&id)
+	err := r.db.QueryRowContext(ctx, query, domain.EstadoPendiente, auto.IDBodega, auto.IDGuiaVersion).Scan(&id, &auto.Version)
 	if err != nil {
 		return 0, fmt.Errorf("error creating autoevaluacion: %w", err)
 	}
@@ -60,14 +61,14 @@ func (r *AutoevaluacionRepository) Create(ctx context.Context, tx repository.Tra
 func (r *AutoevaluacionRepository) FindByID(ctx context.Context, id int) (*domain.Autoevaluacion, error) {
 	query := `
 		SELECT id_autoevaluacion, fecha_inicio, fecha_fin, estado, id_bodega, id_segmento, 
-		       puntaje_final, id_nivel_sostenibilidad                                      
+		       puntaje_final, id_nivel_sostenibilidad, estado_evidencia, id_guia_version, version
 		FROM autoevaluaciones WHERE id_autoevaluacion = $1
 	`
 
 	auto := &domain.Autoevaluacion{}
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&auto.ID, &auto.FechaInicio, &auto.FechaFin, &auto.Estado, &auto.IDBodega, &auto.IDSegmento,
-		&auto.PuntajeFinal, &auto.IDNivelSostenibilidad,
+		&auto.PuntajeFinal, &auto.IDNivelSostenibilidad, &auto.EstadoEvidencia, &auto.IDGuiaVersion, &auto.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -79,10 +80,10 @@ func (r *AutoevaluacionRepository) FindByID(ctx context.Context, id int) (*domai
 	return auto, nil
 }
 
-func (r *AutoevaluacionRepository) UpdateSegmento(ctx context.Context, id int, idSegmento int) error {
+func (r *AutoevaluacionRepository) UpdateSegmento(ctx context.Context, tx repository.Transaction, id int, idSegmento int) error {
 	query := `UPDATE autoevaluaciones SET id_segmento = $1 WHERE id_autoevaluacion = $2`
 
-	_, err := r.db.ExecContext(ctx, query, idSegmento, id)
+	_, err := conn(r.db, tx).ExecContext(ctx, query, idSegmento, id)
 	if err != nil {
 		return fmt.Errorf("error updating segmento: %w", err)
 	}
@@ -120,20 +121,23 @@ func (r *AutoevaluacionRepository) Complete(ctx context.Context, id int) error {
 }
 
 // CompleteWithScore completa la autoevaluación con el puntaje calculado y nivel de sostenibilidad
-func (r *AutoevaluacionRepository) CompleteWithScore(ctx context.Context, id int, puntajeFinal int, idNivelSostenibilidad int) error {
+func (r *AutoevaluacionRepository) CompleteWithScore(ctx context.Context, tx repository.Transaction, id int, puntajeFinal int, idNivelSostenibilidad int) error {
 	query := `
 		UPDATE autoevaluaciones 
 		SET estado = $1, 
 		    fecha_fin = NOW(),
 		    puntaje_final = $2,
 		    id_nivel_sostenibilidad = $3
-		WHERE id_autoevaluacion = $4
+		WHERE id_autoevaluacion = $4 AND estado = $5
 	`
 
-	_, err := r.db.ExecContext(ctx, query, domain.EstadoCompletada, puntajeFinal, idNivelSostenibilidad, id)
+	res, err := conn(r.db, tx).ExecContext(ctx, query, domain.EstadoCompletada, puntajeFinal, idNivelSostenibilidad, id, domain.EstadoPendiente)
 	if err != nil {
 		return fmt.Errorf("error completing autoevaluacion with score: %w", err)
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrAutoevaluacionNoPendiente
+	}
 
 	return nil
 }
@@ -162,7 +166,7 @@ func (r *AutoevaluacionRepository) CompleteWithScore(ctx context.Context, id int
 func (r *AutoevaluacionRepository) FindPendienteByBodega(ctx context.Context, idBodega int) (*domain.Autoevaluacion, error) {
 	query := `
 		SELECT id_autoevaluacion, fecha_inicio, fecha_fin, estado, id_bodega, id_segmento,
-		       puntaje_final, id_nivel_sostenibilidad                                      
+		       puntaje_final, id_nivel_sostenibilidad, id_guia_version, version
 		FROM autoevaluaciones 
 		WHERE id_bodega = $1 AND estado = $2
 	`
@@ -170,7 +174,7 @@ func (r *AutoevaluacionRepository) FindPendienteByBodega(ctx context.Context, id
 	auto := &domain.Autoevaluacion{}
 	err := r.db.QueryRowContext(ctx, query, idBodega, domain.EstadoPendiente).Scan(
 		&auto.ID, &auto.FechaInicio, &auto.FechaFin, &auto.Estado, &auto.IDBodega, &auto.IDSegmento,
-		&auto.PuntajeFinal, &auto.IDNivelSostenibilidad,
+		&auto.PuntajeFinal, &auto.IDNivelSostenibilidad, &auto.IDGuiaVersion, &auto.Version,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -182,13 +186,208 @@ func (r *AutoevaluacionRepository) FindPendienteByBodega(ctx context.Context, id
 	return auto, nil
 }
 
-func (r *AutoevaluacionRepository) Cancel(ctx context.Context, id int) error {
-	query := `UPDATE autoevaluaciones SET estado = $1, fecha_fin = NOW() WHERE id_autoevaluacion = $2`
+func (r *AutoevaluacionRepository) Cancel(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `UPDATE autoevaluaciones SET estado = $1, fecha_fin = NOW() WHERE id_autoevaluacion = $2 AND estado = $3`
 
-	_, err := r.db.ExecContext(ctx, query, domain.EstadoCancelada, id)
+	res, err := conn(r.db, tx).ExecContext(ctx, query, domain.EstadoCancelada, id, domain.EstadoPendiente)
 	if err != nil {
 		return fmt.Errorf("error canceling autoevaluacion: %w", err)
 	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrAutoevaluacionNoPendiente
+	}
+
+	return nil
+}
+
+// Reopen vuelve a PENDIENTE una autoevaluación completada y borra su resultado final y su
+// revisión (la revisión de cada respuesta se conserva para que la bodega vea las observaciones)
+func (r *AutoevaluacionRepository) Reopen(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `
+		UPDATE autoevaluaciones
+		SET estado = $1, fecha_fin = NULL, puntaje_final = NULL, id_nivel_sostenibilidad = NULL,
+		    ultima_actividad = NOW(), aviso_inactividad = NULL, recordatorio_vigencia = NULL,
+		    estado_revision = NULL, id_cuenta_revision = NULL, fecha_inicio_revision = NULL, fecha_fin_revision = NULL,
+		    comentario_revision = NULL, puntaje_validado = NULL, id_nivel_sostenibilidad_validado = NULL,
+		    version = version + 1
+		WHERE id_autoevaluacion = $2 AND estado = $3
+		  AND (estado_revision IS NULL OR estado_revision = $4)
+	`
+
+	res, err := conn(r.db, tx).ExecContext(ctx, query, domain.EstadoPendiente, id, domain.EstadoCompletada, domain.RevisionAutoevaluacionObservada)
+	if err != nil {
+		return fmt.Errorf("error reopening autoevaluacion: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrAutoevaluacionNoReabrible
+	}
+
+	return nil
+}
+
+// AvanzarVersion incrementa la versión de la autoevaluación y devuelve la nueva. Con una
+// versión esperada solo lo hace si coincide con la actual (si no, ErrVersionDesactualizada);
+// dentro de una transacción bloquea la fila hasta confirmarla, por lo que las
+// modificaciones concurrentes se ordenan y solo la primera encuentra su versión.
+func (r *AutoevaluacionRepository) AvanzarVersion(ctx context.Context, tx repository.Transaction, id int, esperada *int) (int, error) {
+	query := `
+		UPDATE autoevaluaciones SET version = version + 1
+		WHERE id_autoevaluacion = $1 AND ($2::integer IS NULL OR version = $2)
+		RETURNING version
+	`
+
+	var version int
+	err := conn(r.db, tx).QueryRowContext(ctx, query, id, esperada).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrVersionDesactualizada
+		}
+		return 0, fmt.Errorf("error updating version: %w", err)
+	}
+
+	return version, nil
+}
+
+// RegistrarActividad marca que la autoevaluación tuvo actividad ahora y descarta el aviso
+// de inactividad enviado
+func (r *AutoevaluacionRepository) RegistrarActividad(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `UPDATE autoevaluaciones SET ultima_actividad = NOW(), aviso_inactividad = NULL WHERE id_autoevaluacion = $1`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error updating ultima_actividad: %w", err)
+	}
+
+	return nil
+}
+
+// FindPendientesInactivas devuelve las autoevaluaciones pendientes sin actividad desde la
+// fecha indicada, con el email de la cuenta de su bodega
+func (r *AutoevaluacionRepository) FindPendientesInactivas(ctx context.Context, sinActividadDesde time.Time) ([]*domain.AutoevaluacionInactiva, error) {
+	query := `
+		SELECT a.id_autoevaluacion, a.id_bodega, b.nombre_fantasia, COALESCE(c.email_login, ''),
+		       a.fecha_inicio, a.ultima_actividad, a.aviso_inactividad
+		FROM autoevaluaciones a
+		INNER JOIN bodegas b ON a.id_bodega = b.id_bodega
+		LEFT JOIN cuentas c ON c.id_bodega = a.id_bodega
+		WHERE a.estado = $1 AND a.ultima_actividad < $2
+		ORDER BY a.ultima_actividad
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.EstadoPendiente, sinActividadDesde)
+	if err != nil {
+		return nil, fmt.Errorf("error querying autoevaluaciones inactivas: %w", err)
+	}
+	defer rows.Close()
+
+	var inactivas []*domain.AutoevaluacionInactiva
+	for rows.Next() {
+		a := &domain.AutoevaluacionInactiva{}
+		if err := rows.Scan(&a.ID, &a.IDBodega, &a.Bodega, &a.Email, &a.FechaInicio, &a.UltimaActividad, &a.AvisoInactividad); err != nil {
+			return nil, fmt.Errorf("error scanning autoevaluacion inactiva: %w", err)
+		}
+		inactivas = append(inactivas, a)
+	}
+
+	return inactivas, rows.Err()
+}
+
+// CancelarInactiva cancela la autoevaluación solo si sigue pendiente, sin actividad desde
+// sinActividadDesde y avisada hasta avisadaHasta. Devuelve false si entretanto la bodega
+// la retomó o la terminó.
+func (r *AutoevaluacionRepository) CancelarInactiva(ctx context.Context, id int, sinActividadDesde, avisadaHasta time.Time) (bool, error) {
+	query := `
+		UPDATE autoevaluaciones
+		SET estado = $1, fecha_fin = NOW(), version = version + 1
+		WHERE id_autoevaluacion = $2 AND estado = $3
+		  AND ultima_actividad < $4 AND aviso_inactividad <= $5
+	`
+
+	res, err := r.db.ExecContext(ctx, query, domain.EstadoCancelada, id, domain.EstadoPendiente, sinActividadDesde, avisadaHasta)
+	if err != nil {
+		return false, fmt.Errorf("error canceling autoevaluacion inactiva: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("error canceling autoevaluacion inactiva: %w", err)
+	}
+
+	return n > 0, nil
+}
+
+func (r *AutoevaluacionRepository) MarcarAvisoInactividad(ctx context.Context, id int) error {
+	query := `UPDATE autoevaluaciones SET aviso_inactividad = NOW() WHERE id_autoevaluacion = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error updating aviso_inactividad: %w", err)
+	}
+
+	return nil
+}
+
+// queryUltimasCompletadas trae, por bodega, su última autoevaluación completada (o NULL)
+// y si tiene una pendiente
+const queryUltimasCompletadas = `
+	SELECT DISTINCT ON (b.id_bodega)
+	       b.id_bodega, b.nombre_fantasia, COALESCE(c.email_login, ''),
+	       EXISTS(SELECT 1 FROM autoevaluaciones p WHERE p.id_bodega = b.id_bodega AND p.estado = $1),
+	       a.id_autoevaluacion, a.fecha_fin, a.id_nivel_sostenibilidad, ns.nombre, ns.meses_vigencia, a.recordatorio_vigencia
+	FROM bodegas b
+	LEFT JOIN autoevaluaciones a ON a.id_bodega = b.id_bodega AND a.estado = $2
+	LEFT JOIN niveles_sostenibilidad ns ON a.id_nivel_sostenibilidad = ns.id_nivel_sostenibilidad
+	LEFT JOIN cuentas c ON c.id_bodega = b.id_bodega
+`
+
+// FindUltimasCompletadas devuelve la última autoevaluación completada de cada bodega
+func (r *AutoevaluacionRepository) FindUltimasCompletadas(ctx context.Context) ([]*domain.UltimaEvaluacionBodega, error) {
+	query := queryUltimasCompletadas + `ORDER BY b.id_bodega, a.fecha_fin DESC NULLS LAST`
+
+	rows, err := r.db.QueryContext(ctx, query, domain.EstadoPendiente, domain.EstadoCompletada)
+	if err != nil {
+		return nil, fmt.Errorf("error querying ultimas autoevaluaciones completadas: %w", err)
+	}
+	defer rows.Close()
+
+	ultimas := make([]*domain.UltimaEvaluacionBodega, 0)
+	for rows.Next() {
+		u, err := scanUltimaEvaluacion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning ultima autoevaluacion completada: %w", err)
+		}
+		ultimas = append(ultimas, u)
+	}
+
+	return ultimas, rows.Err()
+}
+
+// FindUltimaCompletadaByBodega devuelve la última autoevaluación completada de la bodega
+func (r *AutoevaluacionRepository) FindUltimaCompletadaByBodega(ctx context.Context, idBodega int) (*domain.UltimaEvaluacionBodega, error) {
+	query := queryUltimasCompletadas + `WHERE b.id_bodega = $3 ORDER BY b.id_bodega, a.fecha_fin DESC NULLS LAST`
+
+	u, err := scanUltimaEvaluacion(r.db.QueryRowContext(ctx, query, domain.EstadoPendiente, domain.EstadoCompletada, idBodega))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding ultima autoevaluacion completada: %w", err)
+	}
+	return u, nil
+}
+
+func scanUltimaEvaluacion(row scanner) (*domain.UltimaEvaluacionBodega, error) {
+	u := &domain.UltimaEvaluacionBodega{}
+	err := row.Scan(&u.IDBodega, &u.Bodega, &u.Email, &u.TienePendiente,
+		&u.IDAutoevaluacion, &u.FechaFin, &u.IDNivelSostenibilidad, &u.NivelSostenibilidad, &u.MesesVigencia, &u.RecordatorioVigencia)
+	return u, err
+}
+
+// MarcarRecordatorioVigencia registra a cuántos días del vencimiento se envió el último
+// recordatorio de reevaluación
+func (r *AutoevaluacionRepository) MarcarRecordatorioVigencia(ctx context.Context, id int, diasAntes int) error {
+	query := `UPDATE autoevaluaciones SET recordatorio_vigencia = $1 WHERE id_autoevaluacion = $2`
+
+	if _, err := r.db.ExecContext(ctx, query, diasAntes, id); err != nil {
+		return fmt.Errorf("error updating recordatorio_vigencia: %w", err)
+	}
 
 	return nil
 }
@@ -205,13 +404,101 @@ func (r *AutoevaluacionRepository) HasPendingByBodega(ctx context.Context, idBod
 	return exists, nil
 }
 
-func (r *AutoevaluacionRepository) UpdateEvidenciaStatus(ctx context.Context, id int, estado domain.EstadoEvidencia) error {
+func (r *AutoevaluacionRepository) UpdateEvidenciaStatus(ctx context.Context, tx repository.Transaction, id int, estado domain.EstadoEvidencia) error {
 	query := `UPDATE autoevaluaciones SET estado_evidencia = $1 WHERE id_autoevaluacion = $2`
 
-	_, err := r.db.ExecContext(ctx, query, string(estado), id)
+	_, err := conn(r.db, tx).ExecContext(ctx, query, string(estado), id)
 	if err != nil {
 		return fmt.Errorf("error updating evidencia status: %w", err)
 	}
 
 	return nil
 }
+
+// selectResumen trae la autoevaluación con los nombres de segmento y niveles de sostenibilidad
+const selectResumen = `
+	SELECT a.id_autoevaluacion, a.fecha_inicio, a.fecha_fin, a.estado, a.id_bodega, a.id_guia_version,
+	       a.id_segmento, s.nombre, a.puntaje_final, a.id_nivel_sostenibilidad, ns.nombre,
+	       a.estado_evidencia, a.estado_revision, a.id_cuenta_revision, a.comentario_revision, a.puntaje_validado,
+	       a.id_nivel_sostenibilidad_validado, nv.nombre, a.version
+	FROM autoevaluaciones a
+	LEFT JOIN segmentos s ON a.id_segmento = s.id_segmento
+	LEFT JOIN niveles_sostenibilidad ns ON a.id_nivel_sostenibilidad = ns.id_nivel_sostenibilidad
+	LEFT JOIN niveles_sostenibilidad nv ON a.id_nivel_sostenibilidad_validado = nv.id_nivel_sostenibilidad
+`
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanResumen(row scanner) (*domain.AutoevaluacionResumen, error) {
+	res := &domain.AutoevaluacionResumen{}
+	err := row.Scan(
+		&res.ID, &res.FechaInicio, &res.FechaFin, &res.Estado, &res.IDBodega, &res.IDGuiaVersion,
+		&res.IDSegmento, &res.Segmento, &res.PuntajeFinal, &res.IDNivelSostenibilidad, &res.NivelSostenibilidad,
+		&res.EstadoEvidencia, &res.EstadoRevision, &res.IDCuentaRevision, &res.ComentarioRevision, &res.PuntajeValidado,
+		&res.IDNivelSostenibilidadValidado, &res.NivelSostenibilidadValidado, &res.Version,
+	)
+	return res, err
+}
+
+// FindByBodega devuelve una página del historial de la bodega (más recientes primero) y el total sin paginar
+func (r *AutoevaluacionRepository) FindByBodega(ctx context.Context, filtro domain.FiltroAutoevaluaciones) ([]*domain.AutoevaluacionResumen, int, error) {
+	where := []string{"a.id_bodega = $1"}
+	args := []interface{}{filtro.IDBodega}
+
+	if filtro.Estado != nil {
+		args = append(args, string(*filtro.Estado))
+		where = append(where, fmt.Sprintf("a.estado = $%d", len(args)))
+	}
+	if filtro.Desde != nil {
+		args = append(args, *filtro.Desde)
+		where = append(where, fmt.Sprintf("a.fecha_inicio >= $%d", len(args)))
+	}
+	if filtro.Hasta != nil {
+		args = append(args, *filtro.Hasta)
+		where = append(where, fmt.Sprintf("a.fecha_inicio < $%d", len(args)))
+	}
+	condicion := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM autoevaluaciones a` + condicion
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("error counting autoevaluaciones: %w", err)
+	}
+
+	args = append(args, filtro.PorPagina, (filtro.Pagina-1)*filtro.PorPagina)
+	query := selectResumen + condicion +
+		fmt.Sprintf(" ORDER BY a.fecha_inicio DESC, a.id_autoevaluacion DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error querying autoevaluaciones: %w", err)
+	}
+	defer rows.Close()
+
+	resumenes := make([]*domain.AutoevaluacionResumen, 0)
+	for rows.Next() {
+		res, err := scanResumen(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error scanning autoevaluacion: %w", err)
+		}
+		resumenes = append(resumenes, res)
+	}
+
+	return resumenes, total, rows.Err()
+}
+
+func (r *AutoevaluacionRepository) FindResumenByID(ctx context.Context, id int) (*domain.AutoevaluacionResumen, error) {
+	query := selectResumen + ` WHERE a.id_autoevaluacion = $1`
+
+	res, err := scanResumen(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
+	}
+
+	return res, nil
+}
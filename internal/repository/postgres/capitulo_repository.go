@@ -17,10 +17,15 @@ func NewCapituloRepository(db *sql.DB) repository.CapituloRepository {
 	return &CapituloRepository{db: db}
 }
 
-func (r *CapituloRepository) FindAll(ctx context.Context) ([]*domain.Capitulo, error) {
-	query := `SELECT id_capitulo, nombre, descripcion, orden FROM capitulos ORDER BY orden`
+func (r *CapituloRepository) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Capitulo, error) {
+	query := `
+		SELECT id_capitulo, id_guia_version, clave, nombre, descripcion, orden, peso
+		FROM capitulos
+		WHERE id_guia_version = $1
+		ORDER BY orden
+	`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	rows, err := r.db.QueryContext(ctx, query, idGuiaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error querying capitulos: %w", err)
 	}
@@ -29,7 +34,7 @@ func (r *CapituloRepository) FindAll(ctx context.Context) ([]*domain.Capitulo, e
 	var capitulos []*domain.Capitulo
 	for rows.Next() {
 		cap := &domain.Capitulo{}
-		if err := rows.Scan(&cap.ID, &cap.Nombre, &cap.Descripcion, &cap.Orden); err != nil {
+		if err := rows.Scan(&cap.ID, &cap.IDGuiaVersion, &cap.Clave, &cap.Nombre, &cap.Descripcion, &cap.Orden, &cap.Peso); err != nil {
 			return nil, fmt.Errorf("error scanning capitulo: %w", err)
 		}
 		capitulos = append(capitulos, cap)
@@ -37,3 +42,92 @@ func (r *CapituloRepository) FindAll(ctx context.Context) ([]*domain.Capitulo, e
 
 	return capitulos, rows.Err()
 }
+
+func (r *CapituloRepository) FindByID(ctx context.Context, id int) (*domain.Capitulo, error) {
+	query := `
+		SELECT id_capitulo, id_guia_version, clave, nombre, descripcion, orden, peso
+		FROM capitulos
+		WHERE id_capitulo = $1
+	`
+
+	cap := &domain.Capitulo{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&cap.ID, &cap.IDGuiaVersion, &cap.Clave, &cap.Nombre, &cap.Descripcion, &cap.Orden, &cap.Peso)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding capitulo: %w", err)
+	}
+
+	return cap, nil
+}
+
+// Create inserta el capítulo; si no trae clave se genera a partir del ID
+func (r *CapituloRepository) Create(ctx context.Context, tx repository.Transaction, capitulo *domain.Capitulo) (int, error) {
+	q := conn(r.db, tx)
+
+	query := `
+		INSERT INTO capitulos (id_guia_version, clave, nombre, descripcion, orden, peso)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id_capitulo
+	`
+
+	var id int
+	err := q.QueryRowContext(ctx, query,
+		capitulo.IDGuiaVersion, capitulo.Clave, capitulo.Nombre, capitulo.Descripcion, capitulo.Orden, capitulo.Peso,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error creating capitulo: %w", err)
+	}
+
+	if capitulo.Clave == "" {
+		capitulo.Clave = fmt.Sprintf("CAP-%d", id)
+		if _, err := q.ExecContext(ctx, `UPDATE capitulos SET clave = $1 WHERE id_capitulo = $2`, capitulo.Clave, id); err != nil {
+			return 0, fmt.Errorf("error setting capitulo clave: %w", err)
+		}
+	}
+
+	capitulo.ID = id
+	return id, nil
+}
+
+func (r *CapituloRepository) Update(ctx context.Context, tx repository.Transaction, capitulo *domain.Capitulo) error {
+	query := `UPDATE capitulos SET nombre = $1, descripcion = $2, peso = $3 WHERE id_capitulo = $4`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, capitulo.Nombre, capitulo.Descripcion, capitulo.Peso, capitulo.ID); err != nil {
+		return fmt.Errorf("error updating capitulo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CapituloRepository) UpdateOrden(ctx context.Context, tx repository.Transaction, id int, orden int) error {
+	if _, err := conn(r.db, tx).ExecContext(ctx, `UPDATE capitulos SET orden = $1 WHERE id_capitulo = $2`, orden, id); err != nil {
+		return fmt.Errorf("error updating capitulo orden: %w", err)
+	}
+
+	return nil
+}
+
+// Delete elimina el capítulo junto con sus indicadores y niveles de respuesta
+func (r *CapituloRepository) Delete(ctx context.Context, tx repository.Transaction, id int) error {
+	q := conn(r.db, tx)
+
+	const indicadoresCapitulo = `SELECT id_indicador FROM indicadores WHERE id_capitulo = $1`
+	borrados := []struct {
+		entidad string
+		query   string
+	}{
+		{"niveles_respuesta", `DELETE FROM niveles_respuesta WHERE id_indicador IN (` + indicadoresCapitulo + `)`},
+		{"segmento_indicador", `DELETE FROM segmento_indicador WHERE id_indicador IN (` + indicadoresCapitulo + `)`},
+		{"indicadores", `DELETE FROM indicadores WHERE id_capitulo = $1`},
+		{"capitulo", `DELETE FROM capitulos WHERE id_capitulo = $1`},
+	}
+	for _, b := range borrados {
+		if _, err := q.ExecContext(ctx, b.query, id); err != nil {
+			return fmt.Errorf("error deleting %s: %w", b.entidad, err)
+		}
+	}
+
+	return nil
+}
@@ -20,9 +20,9 @@ func NewCuentaRepository(db *sql.DB) repository.CuentaRepository {
 
 func (r *CuentaRepository) Create(ctx context.Context, tx repository.Transaction, cuenta *domain.Cuenta) (int, error) {
 	// Validar restricciones antes de insertar si es necesario
-	// - tipo debe ser 'BODEGA' o 'ADMINISTRADOR_APP'
+	// - tipo debe ser 'BODEGA', 'ADMINISTRADOR_APP' o 'AUDITOR'
 	// - Si tipo = 'BODEGA', id_bodega no puede ser NULL
-	// - Si tipo = 'ADMINISTRADOR_APP', id_bodega debe ser NULL
+	// - Si tipo = 'ADMINISTRADOR_APP' o 'AUDITOR', id_bodega debe ser NULL
 	// - email_login único, id_bodega único
 	query := `
 	       INSERT INTO cuentas (tipo, id_bodega, email_login, password_hash, fecha_registro)
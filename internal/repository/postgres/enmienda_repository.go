@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type EnmiendaRepository struct {
+	db *sql.DB
+}
+
+func NewEnmiendaRepository(db *sql.DB) repository.EnmiendaRepository {
+	return &EnmiendaRepository{db: db}
+}
+
+// Create registra la reapertura; la copia del estado anterior se guarda como JSON
+func (r *EnmiendaRepository) Create(ctx context.Context, tx repository.Transaction, enmienda *domain.EnmiendaAutoevaluacion) (int, error) {
+	snapshot, err := json.Marshal(enmienda.Anterior)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding snapshot: %w", err)
+	}
+
+	query := `
+		INSERT INTO enmiendas_autoevaluacion (id_autoevaluacion, id_cuenta, motivo, snapshot)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id_enmienda, fecha_reapertura
+	`
+
+	err = conn(r.db, tx).QueryRowContext(ctx, query, enmienda.IDAutoevaluacion, enmienda.IDCuenta, enmienda.Motivo, snapshot).
+		Scan(&enmienda.ID, &enmienda.FechaReapertura)
+	if err != nil {
+		return 0, fmt.Errorf("error creating enmienda: %w", err)
+	}
+
+	return enmienda.ID, nil
+}
+
+func (r *EnmiendaRepository) FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.EnmiendaAutoevaluacion, error) {
+	query := `
+		SELECT id_enmienda, id_autoevaluacion, id_cuenta, motivo, fecha_reapertura, snapshot,
+		       fecha_recompletado, puntaje_nuevo, id_nivel_sostenibilidad_nuevo
+		FROM enmiendas_autoevaluacion
+		WHERE id_autoevaluacion = $1
+		ORDER BY fecha_reapertura
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying enmiendas: %w", err)
+	}
+	defer rows.Close()
+
+	enmiendas := make([]*domain.EnmiendaAutoevaluacion, 0)
+	for rows.Next() {
+		e := &domain.EnmiendaAutoevaluacion{}
+		var snapshot []byte
+		if err := rows.Scan(&e.ID, &e.IDAutoevaluacion, &e.IDCuenta, &e.Motivo, &e.FechaReapertura, &snapshot,
+			&e.FechaRecompletado, &e.PuntajeNuevo, &e.IDNivelSostenibilidadNuevo); err != nil {
+			return nil, fmt.Errorf("error scanning enmienda: %w", err)
+		}
+		if err := json.Unmarshal(snapshot, &e.Anterior); err != nil {
+			return nil, fmt.Errorf("error decoding snapshot: %w", err)
+		}
+		enmiendas = append(enmiendas, e)
+	}
+
+	return enmiendas, rows.Err()
+}
+
+// RegistrarRecompletado cierra la enmienda abierta de la autoevaluación, si la hay,
+// con el resultado obtenido al volver a completarla
+func (r *EnmiendaRepository) RegistrarRecompletado(ctx context.Context, tx repository.Transaction, idAutoevaluacion int, puntaje int, idNivelSostenibilidad *int) error {
+	query := `
+		UPDATE enmiendas_autoevaluacion
+		SET fecha_recompletado = NOW(), puntaje_nuevo = $2, id_nivel_sostenibilidad_nuevo = $3
+		WHERE id_autoevaluacion = $1 AND fecha_recompletado IS NULL
+	`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, idAutoevaluacion, puntaje, idNivelSostenibilidad); err != nil {
+		return fmt.Errorf("error updating enmienda: %w", err)
+	}
+
+	return nil
+}
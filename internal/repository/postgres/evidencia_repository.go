@@ -25,7 +25,7 @@ func (r *EvidenciaRepository) Create(ctx context.Context, tx repository.Transact
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query, evidencia.IDRespuesta, evidencia.Nombre, evidencia.Ubicacion).Scan(&id)
+	err := conn(r.db, tx).QueryRowContext(ctx, query, evidencia.IDRespuesta, evidencia.Nombre, evidencia.Ubicacion).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("error creating evidencia: %w", err)
 	}
@@ -82,7 +82,7 @@ func (r *EvidenciaRepository) FindByAutoevaluacion(ctx context.Context, idAutoev
 func (r *EvidenciaRepository) Delete(ctx context.Context, tx repository.Transaction, id int) error {
 	query := `DELETE FROM evidencias WHERE id_evidencia = $1`
 
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := conn(r.db, tx).ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("error deleting evidencia: %w", err)
 	}
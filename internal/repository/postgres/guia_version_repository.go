@@ -0,0 +1,254 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type GuiaVersionRepository struct {
+	db *sql.DB
+}
+
+func NewGuiaVersionRepository(db *sql.DB) repository.GuiaVersionRepository {
+	return &GuiaVersionRepository{db: db}
+}
+
+const selectGuiaVersion = `
+	SELECT id_guia_version, numero, estado, descripcion, estrategia_puntaje, id_version_origen, fecha_creacion, fecha_publicacion
+	FROM guia_versiones
+`
+
+func scanGuiaVersion(row scanner) (*domain.GuiaVersion, error) {
+	v := &domain.GuiaVersion{}
+	err := row.Scan(&v.ID, &v.Numero, &v.Estado, &v.Descripcion, &v.EstrategiaPuntaje, &v.IDVersionOrigen, &v.FechaCreacion, &v.FechaPublicacion)
+	return v, err
+}
+
+func (r *GuiaVersionRepository) FindAll(ctx context.Context) ([]*domain.GuiaVersion, error) {
+	rows, err := r.db.QueryContext(ctx, selectGuiaVersion+` ORDER BY numero DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying guia_versiones: %w", err)
+	}
+	defer rows.Close()
+
+	versiones := make([]*domain.GuiaVersion, 0)
+	for rows.Next() {
+		v, err := scanGuiaVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning guia_version: %w", err)
+		}
+		versiones = append(versiones, v)
+	}
+
+	return versiones, rows.Err()
+}
+
+func (r *GuiaVersionRepository) FindByID(ctx context.Context, id int) (*domain.GuiaVersion, error) {
+	v, err := scanGuiaVersion(r.db.QueryRowContext(ctx, selectGuiaVersion+` WHERE id_guia_version = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding guia_version: %w", err)
+	}
+	return v, nil
+}
+
+func (r *GuiaVersionRepository) FindPublicada(ctx context.Context) (*domain.GuiaVersion, error) {
+	v, err := scanGuiaVersion(r.db.QueryRowContext(ctx, selectGuiaVersion+` WHERE estado = $1`, domain.GuiaVersionPublicada))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("no hay una versión publicada de la guía")
+		}
+		return nil, fmt.Errorf("error finding guia_version publicada: %w", err)
+	}
+	return v, nil
+}
+
+// FindBorrador devuelve la versión en borrador o nil si no hay ninguna
+func (r *GuiaVersionRepository) FindBorrador(ctx context.Context) (*domain.GuiaVersion, error) {
+	v, err := scanGuiaVersion(r.db.QueryRowContext(ctx, selectGuiaVersion+` WHERE estado = $1`, domain.GuiaVersionBorrador))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error finding guia_version borrador: %w", err)
+	}
+	return v, nil
+}
+
+// CrearBorradorVacio crea una versión BORRADOR sin contenido, con la estrategia de puntaje de la versión de origen
+func (r *GuiaVersionRepository) CrearBorradorVacio(ctx context.Context, tx repository.Transaction, idOrigen int, descripcion string) (*domain.GuiaVersion, error) {
+	v := &domain.GuiaVersion{
+		Estado:          domain.GuiaVersionBorrador,
+		Descripcion:     descripcion,
+		IDVersionOrigen: &idOrigen,
+	}
+	err := conn(r.db, tx).QueryRowContext(ctx, `
+		INSERT INTO guia_versiones (numero, estado, descripcion, id_version_origen, estrategia_puntaje)
+		SELECT COALESCE(MAX(numero), 0) + 1, $1, $2, $3,
+		       (SELECT estrategia_puntaje FROM guia_versiones WHERE id_guia_version = $3)
+		FROM guia_versiones
+		RETURNING id_guia_version, numero, estrategia_puntaje, fecha_creacion
+	`, domain.GuiaVersionBorrador, descripcion, idOrigen).Scan(&v.ID, &v.Numero, &v.EstrategiaPuntaje, &v.FechaCreacion)
+	if err != nil {
+		return nil, fmt.Errorf("error creating guia_version: %w", err)
+	}
+
+	return v, nil
+}
+
+// CrearBorrador crea una versión BORRADOR copiando todo el contenido de la versión de origen.
+// Capítulos e indicadores conservan su clave, lo que permite asociarlos entre versiones.
+func (r *GuiaVersionRepository) CrearBorrador(ctx context.Context, tx repository.Transaction, idOrigen int, descripcion string) (*domain.GuiaVersion, error) {
+	q := conn(r.db, tx)
+
+	v, err := r.CrearBorradorVacio(ctx, tx, idOrigen, descripcion)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relación entre los indicadores de la versión de origen y sus copias, por la clave
+	// del capítulo y la del indicador (única en la versión)
+	const mapaIndicadores = `
+		WITH mapa AS (
+			SELECT io.id_indicador AS id_origen, inu.id_indicador AS id_nuevo
+			FROM indicadores io
+			INNER JOIN capitulos co ON io.id_capitulo = co.id_capitulo AND co.id_guia_version = $1
+			INNER JOIN indicadores inu ON inu.clave = io.clave AND inu.id_guia_version = $2
+			INNER JOIN capitulos cn ON inu.id_capitulo = cn.id_capitulo AND cn.clave = co.clave
+		)
+	`
+
+	copias := []struct {
+		entidad string
+		query   string
+	}{
+		{"capitulos", `
+			INSERT INTO capitulos (id_guia_version, clave, nombre, descripcion, orden, peso)
+			SELECT $2, clave, nombre, descripcion, orden, peso
+			FROM capitulos WHERE id_guia_version = $1
+		`},
+		{"indicadores", `
+			INSERT INTO indicadores (id_capitulo, clave, nombre, descripcion, orden, peso)
+			SELECT cn.id_capitulo, i.clave, i.nombre, i.descripcion, i.orden, i.peso
+			FROM indicadores i
+			INNER JOIN capitulos co ON i.id_capitulo = co.id_capitulo AND co.id_guia_version = $1
+			INNER JOIN capitulos cn ON cn.clave = co.clave AND cn.id_guia_version = $2
+		`},
+		{"niveles_respuesta", mapaIndicadores + `
+			INSERT INTO niveles_respuesta (id_indicador, nombre, descripcion, puntos, posicion)
+			SELECT m.id_nuevo, nr.nombre, nr.descripcion, nr.puntos, nr.posicion
+			FROM niveles_respuesta nr
+			INNER JOIN mapa m ON nr.id_indicador = m.id_origen
+		`},
+		{"segmento_indicador", mapaIndicadores + `
+			INSERT INTO segmento_indicador (id_segmento, id_indicador)
+			SELECT si.id_segmento, m.id_nuevo
+			FROM segmento_indicador si
+			INNER JOIN mapa m ON si.id_indicador = m.id_origen
+		`},
+		{"niveles_sostenibilidad", `
+			INSERT INTO niveles_sostenibilidad (id_segmento, id_guia_version, nombre, min_puntaje, max_puntaje, meses_vigencia)
+			SELECT id_segmento, $2, nombre, min_puntaje, max_puntaje, meses_vigencia
+			FROM niveles_sostenibilidad WHERE id_guia_version = $1
+		`},
+		// Los niveles copiados se asocian por segmento y nombre, y los capítulos por clave
+		{"requisitos_nivel_capitulo", `
+			INSERT INTO requisitos_nivel_capitulo (id_nivel_sostenibilidad, id_capitulo, min_porcentaje)
+			SELECT nsn.id_nivel_sostenibilidad, cn.id_capitulo, r.min_porcentaje
+			FROM requisitos_nivel_capitulo r
+			INNER JOIN niveles_sostenibilidad nso ON r.id_nivel_sostenibilidad = nso.id_nivel_sostenibilidad AND nso.id_guia_version = $1
+			INNER JOIN niveles_sostenibilidad nsn ON nsn.id_segmento = nso.id_segmento AND nsn.nombre = nso.nombre AND nsn.id_guia_version = $2
+			INNER JOIN capitulos co ON r.id_capitulo = co.id_capitulo AND co.id_guia_version = $1
+			INNER JOIN capitulos cn ON cn.clave = co.clave AND cn.id_guia_version = $2
+			ON CONFLICT DO NOTHING
+		`},
+	}
+	for _, c := range copias {
+		if _, err := q.ExecContext(ctx, c.query, idOrigen, v.ID); err != nil {
+			return nil, fmt.Errorf("error copying %s: %w", c.entidad, err)
+		}
+	}
+
+	return v, nil
+}
+
+// UpdateEstrategia cambia la estrategia de puntaje de una versión en borrador
+func (r *GuiaVersionRepository) UpdateEstrategia(ctx context.Context, tx repository.Transaction, id int, estrategia domain.EstrategiaPuntaje) error {
+	result, err := conn(r.db, tx).ExecContext(ctx, `
+		UPDATE guia_versiones SET estrategia_puntaje = $1
+		WHERE id_guia_version = $2 AND estado = $3
+	`, estrategia, id, domain.GuiaVersionBorrador)
+	if err != nil {
+		return fmt.Errorf("error updating estrategia_puntaje: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.ErrGuiaVersionNoEditable
+	}
+
+	return nil
+}
+
+// Publicar archiva la versión publicada actual y publica el borrador indicado
+func (r *GuiaVersionRepository) Publicar(ctx context.Context, tx repository.Transaction, id int) error {
+	q := conn(r.db, tx)
+
+	if _, err := q.ExecContext(ctx, `UPDATE guia_versiones SET estado = $1 WHERE estado = $2`,
+		domain.GuiaVersionArchivada, domain.GuiaVersionPublicada); err != nil {
+		return fmt.Errorf("error archiving guia_version: %w", err)
+	}
+
+	result, err := q.ExecContext(ctx, `
+		UPDATE guia_versiones SET estado = $1, fecha_publicacion = NOW()
+		WHERE id_guia_version = $2 AND estado = $3
+	`, domain.GuiaVersionPublicada, id, domain.GuiaVersionBorrador)
+	if err != nil {
+		return fmt.Errorf("error publishing guia_version: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.ErrGuiaVersionNoEditable
+	}
+
+	return nil
+}
+
+// Delete elimina una versión y todo su contenido; solo debe usarse con borradores
+func (r *GuiaVersionRepository) Delete(ctx context.Context, tx repository.Transaction, id int) error {
+	q := conn(r.db, tx)
+
+	const indicadoresVersion = `
+		SELECT i.id_indicador FROM indicadores i
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo
+		WHERE c.id_guia_version = $1
+	`
+	borrados := []struct {
+		entidad string
+		query   string
+	}{
+		{"niveles_respuesta", `DELETE FROM niveles_respuesta WHERE id_indicador IN (` + indicadoresVersion + `)`},
+		{"segmento_indicador", `DELETE FROM segmento_indicador WHERE id_indicador IN (` + indicadoresVersion + `)`},
+		{"indicadores", `DELETE FROM indicadores WHERE id_indicador IN (` + indicadoresVersion + `)`},
+		{"capitulos", `DELETE FROM capitulos WHERE id_guia_version = $1`},
+		{"niveles_sostenibilidad", `DELETE FROM niveles_sostenibilidad WHERE id_guia_version = $1`},
+	}
+	for _, b := range borrados {
+		if _, err := q.ExecContext(ctx, b.query, id); err != nil {
+			return fmt.Errorf("error deleting %s: %w", b.entidad, err)
+		}
+	}
+
+	result, err := q.ExecContext(ctx, `DELETE FROM guia_versiones WHERE id_guia_version = $1 AND estado = $2`, id, domain.GuiaVersionBorrador)
+	if err != nil {
+		return fmt.Errorf("error deleting guia_version: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return domain.ErrGuiaVersionNoEditable
+	}
+
+	return nil
+}
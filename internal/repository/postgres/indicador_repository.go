@@ -19,7 +19,7 @@ func NewIndicadorRepository(db *sql.DB) repository.IndicadorRepository {
 
 func (r *IndicadorRepository) FindByCapitulo(ctx context.Context, idCapitulo int) ([]*domain.Indicador, error) {
 	query := `
-		SELECT id_indicador, id_capitulo, nombre, descripcion, orden
+		SELECT id_indicador, id_capitulo, clave, nombre, descripcion, orden, peso
 		FROM indicadores
 		WHERE id_capitulo = $1
 		ORDER BY orden
@@ -34,7 +34,7 @@ func (r *IndicadorRepository) FindByCapitulo(ctx context.Context, idCapitulo int
 	var indicadores []*domain.Indicador
 	for rows.Next() {
 		ind := &domain.Indicador{}
-		if err := rows.Scan(&ind.ID, &ind.IDCapitulo, &ind.Nombre, &ind.Descripcion, &ind.Orden); err != nil {
+		if err := rows.Scan(&ind.ID, &ind.IDCapitulo, &ind.Clave, &ind.Nombre, &ind.Descripcion, &ind.Orden, &ind.Peso); err != nil {
 			return nil, fmt.Errorf("error scanning indicador: %w", err)
 		}
 		indicadores = append(indicadores, ind)
@@ -43,10 +43,17 @@ func (r *IndicadorRepository) FindByCapitulo(ctx context.Context, idCapitulo int
 	return indicadores, rows.Err()
 }
 
-func (r *IndicadorRepository) FindBySegmento(ctx context.Context, idSegmento int) ([]int, error) {
-	query := `SELECT id_indicador FROM segmento_indicador WHERE id_segmento = $1`
+// FindBySegmento devuelve los indicadores de la versión de la guía habilitados para el segmento
+func (r *IndicadorRepository) FindBySegmento(ctx context.Context, idSegmento int, idGuiaVersion int) ([]int, error) {
+	query := `
+		SELECT si.id_indicador
+		FROM segmento_indicador si
+		INNER JOIN indicadores i ON si.id_indicador = i.id_indicador
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo
+		WHERE si.id_segmento = $1 AND c.id_guia_version = $2
+	`
 
-	rows, err := r.db.QueryContext(ctx, query, idSegmento)
+	rows, err := r.db.QueryContext(ctx, query, idSegmento, idGuiaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error querying segmento_indicador: %w", err)
 	}
@@ -63,3 +70,163 @@ func (r *IndicadorRepository) FindBySegmento(ctx context.Context, idSegmento int
 
 	return indicadorIds, rows.Err()
 }
+
+const selectIndicador = `
+	SELECT i.id_indicador, i.id_capitulo, i.clave, i.nombre, i.descripcion, i.orden, i.peso
+	FROM indicadores i
+`
+
+func (r *IndicadorRepository) FindByID(ctx context.Context, id int) (*domain.Indicador, error) {
+	ind := &domain.Indicador{}
+	err := r.db.QueryRowContext(ctx, selectIndicador+` WHERE i.id_indicador = $1`, id).
+		Scan(&ind.ID, &ind.IDCapitulo, &ind.Clave, &ind.Nombre, &ind.Descripcion, &ind.Orden, &ind.Peso)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding indicador: %w", err)
+	}
+
+	return ind, nil
+}
+
+// FindByVersion devuelve todos los indicadores de una versión de la guía, ordenados por capítulo
+func (r *IndicadorRepository) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Indicador, error) {
+	query := selectIndicador + `
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo
+		WHERE c.id_guia_version = $1
+		ORDER BY c.orden, i.orden
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying indicadores: %w", err)
+	}
+	defer rows.Close()
+
+	var indicadores []*domain.Indicador
+	for rows.Next() {
+		ind := &domain.Indicador{}
+		if err := rows.Scan(&ind.ID, &ind.IDCapitulo, &ind.Clave, &ind.Nombre, &ind.Descripcion, &ind.Orden, &ind.Peso); err != nil {
+			return nil, fmt.Errorf("error scanning indicador: %w", err)
+		}
+		indicadores = append(indicadores, ind)
+	}
+
+	return indicadores, rows.Err()
+}
+
+// Create inserta el indicador; si no trae clave se genera a partir del ID
+func (r *IndicadorRepository) Create(ctx context.Context, tx repository.Transaction, indicador *domain.Indicador) (int, error) {
+	q := conn(r.db, tx)
+
+	query := `
+		INSERT INTO indicadores (id_capitulo, clave, nombre, descripcion, orden, peso)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id_indicador
+	`
+
+	var id int
+	err := q.QueryRowContext(ctx, query,
+		indicador.IDCapitulo, indicador.Clave, indicador.Nombre, indicador.Descripcion, indicador.Orden, indicador.Peso,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error creating indicador: %w", err)
+	}
+
+	if indicador.Clave == "" {
+		indicador.Clave = fmt.Sprintf("IND-%d", id)
+		if _, err := q.ExecContext(ctx, `UPDATE indicadores SET clave = $1 WHERE id_indicador = $2`, indicador.Clave, id); err != nil {
+			return 0, fmt.Errorf("error setting indicador clave: %w", err)
+		}
+	}
+
+	indicador.ID = id
+	return id, nil
+}
+
+func (r *IndicadorRepository) Update(ctx context.Context, tx repository.Transaction, indicador *domain.Indicador) error {
+	query := `
+		UPDATE indicadores SET id_capitulo = $1, nombre = $2, descripcion = $3, orden = $4, peso = $5
+		WHERE id_indicador = $6
+	`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query,
+		indicador.IDCapitulo, indicador.Nombre, indicador.Descripcion, indicador.Orden, indicador.Peso, indicador.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("error updating indicador: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IndicadorRepository) UpdateOrden(ctx context.Context, tx repository.Transaction, id int, orden int) error {
+	if _, err := conn(r.db, tx).ExecContext(ctx, `UPDATE indicadores SET orden = $1 WHERE id_indicador = $2`, orden, id); err != nil {
+		return fmt.Errorf("error updating indicador orden: %w", err)
+	}
+
+	return nil
+}
+
+// Delete elimina el indicador junto con sus niveles de respuesta y su habilitación por segmento
+func (r *IndicadorRepository) Delete(ctx context.Context, tx repository.Transaction, id int) error {
+	q := conn(r.db, tx)
+
+	borrados := []struct {
+		entidad string
+		query   string
+	}{
+		{"niveles_respuesta", `DELETE FROM niveles_respuesta WHERE id_indicador = $1`},
+		{"segmento_indicador", `DELETE FROM segmento_indicador WHERE id_indicador = $1`},
+		{"indicador", `DELETE FROM indicadores WHERE id_indicador = $1`},
+	}
+	for _, b := range borrados {
+		if _, err := q.ExecContext(ctx, b.query, id); err != nil {
+			return fmt.Errorf("error deleting %s: %w", b.entidad, err)
+		}
+	}
+
+	return nil
+}
+
+// FindSegmentos devuelve los segmentos para los que está habilitado el indicador
+func (r *IndicadorRepository) FindSegmentos(ctx context.Context, idIndicador int) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id_segmento FROM segmento_indicador WHERE id_indicador = $1 ORDER BY id_segmento
+	`, idIndicador)
+	if err != nil {
+		return nil, fmt.Errorf("error querying segmento_indicador: %w", err)
+	}
+	defer rows.Close()
+
+	segmentos := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning segmento id: %w", err)
+		}
+		segmentos = append(segmentos, id)
+	}
+
+	return segmentos, rows.Err()
+}
+
+// ReplaceSegmentos reemplaza los segmentos para los que está habilitado el indicador
+func (r *IndicadorRepository) ReplaceSegmentos(ctx context.Context, tx repository.Transaction, idIndicador int, segmentos []int) error {
+	q := conn(r.db, tx)
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM segmento_indicador WHERE id_indicador = $1`, idIndicador); err != nil {
+		return fmt.Errorf("error deleting segmento_indicador: %w", err)
+	}
+
+	for _, idSegmento := range segmentos {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO segmento_indicador (id_segmento, id_indicador) VALUES ($1, $2)
+		`, idSegmento, idIndicador); err != nil {
+			return fmt.Errorf("error inserting segmento_indicador: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -42,3 +42,98 @@ func (r *NivelRespuestaRepository) FindByIndicador(ctx context.Context, idIndica
 
 	return niveles, rows.Err()
 }
+
+// FindByVersion trae los niveles de respuesta de todos los indicadores de una versión
+// de la guía, ordenados por indicador y posición
+func (r *NivelRespuestaRepository) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.NivelRespuesta, error) {
+	query := `
+		SELECT nr.id_nivel_respuesta, nr.id_indicador, nr.nombre, nr.descripcion, nr.puntos, COALESCE(nr.posicion, 0) as posicion
+		FROM niveles_respuesta nr
+		INNER JOIN indicadores i ON nr.id_indicador = i.id_indicador
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo
+		WHERE c.id_guia_version = $1
+		ORDER BY nr.id_indicador, nr.posicion ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying niveles_respuesta: %w", err)
+	}
+	defer rows.Close()
+
+	var niveles []*domain.NivelRespuesta
+	for rows.Next() {
+		nivel := &domain.NivelRespuesta{}
+		if err := rows.Scan(&nivel.ID, &nivel.IDIndicador, &nivel.Nombre, &nivel.Descripcion, &nivel.Puntos, &nivel.Posicion); err != nil {
+			return nil, fmt.Errorf("error scanning nivel_respuesta: %w", err)
+		}
+		niveles = append(niveles, nivel)
+	}
+
+	return niveles, rows.Err()
+}
+
+func (r *NivelRespuestaRepository) FindByID(ctx context.Context, id int) (*domain.NivelRespuesta, error) {
+	query := `
+		SELECT id_nivel_respuesta, id_indicador, nombre, descripcion, puntos, COALESCE(posicion, 0) as posicion
+		FROM niveles_respuesta
+		WHERE id_nivel_respuesta = $1
+	`
+
+	nivel := &domain.NivelRespuesta{}
+	err := r.db.QueryRowContext(ctx, query, id).
+		Scan(&nivel.ID, &nivel.IDIndicador, &nivel.Nombre, &nivel.Descripcion, &nivel.Puntos, &nivel.Posicion)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, domain.ErrNotFound
+		}
+		return nil, fmt.Errorf("error finding nivel_respuesta: %w", err)
+	}
+
+	return nivel, nil
+}
+
+func (r *NivelRespuestaRepository) Create(ctx context.Context, tx repository.Transaction, nivel *domain.NivelRespuesta) (int, error) {
+	query := `
+		INSERT INTO niveles_respuesta (id_indicador, nombre, descripcion, puntos, posicion)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id_nivel_respuesta
+	`
+
+	var id int
+	err := conn(r.db, tx).QueryRowContext(ctx, query,
+		nivel.IDIndicador, nivel.Nombre, nivel.Descripcion, nivel.Puntos, nivel.Posicion,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("error creating nivel_respuesta: %w", err)
+	}
+
+	nivel.ID = id
+	return id, nil
+}
+
+func (r *NivelRespuestaRepository) Update(ctx context.Context, tx repository.Transaction, nivel *domain.NivelRespuesta) error {
+	query := `UPDATE niveles_respuesta SET nombre = $1, descripcion = $2, puntos = $3 WHERE id_nivel_respuesta = $4`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, nivel.Nombre, nivel.Descripcion, nivel.Puntos, nivel.ID); err != nil {
+		return fmt.Errorf("error updating nivel_respuesta: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NivelRespuestaRepository) UpdatePosicion(ctx context.Context, tx repository.Transaction, id int, posicion int) error {
+	if _, err := conn(r.db, tx).ExecContext(ctx, `UPDATE niveles_respuesta SET posicion = $1 WHERE id_nivel_respuesta = $2`, posicion, id); err != nil {
+		return fmt.Errorf("error updating nivel_respuesta posicion: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NivelRespuestaRepository) Delete(ctx context.Context, tx repository.Transaction, id int) error {
+	if _, err := conn(r.db, tx).ExecContext(ctx, `DELETE FROM niveles_respuesta WHERE id_nivel_respuesta = $1`, id); err != nil {
+		return fmt.Errorf("error deleting nivel_respuesta: %w", err)
+	}
+
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	"coviar_backend/internal/domain"
 	"coviar_backend/internal/repository"
 )
@@ -19,13 +21,13 @@ func NewRespuestaRepository(db *sql.DB) repository.RespuestaRepository {
 
 func (r *RespuestaRepository) Create(ctx context.Context, tx repository.Transaction, respuesta *domain.Respuesta) (int, error) {
 	query := `
-		INSERT INTO respuestas (id_nivel_respuesta, id_indicador, id_autoevaluacion)
-		VALUES ($1, $2, $3)
+		INSERT INTO respuestas (id_nivel_respuesta, id_indicador, id_autoevaluacion, nota)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id_respuesta
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query, respuesta.IDNivelRespuesta, respuesta.IDIndicador, respuesta.IDAutoevaluacion).Scan(&id)
+	err := conn(r.db, tx).QueryRowContext(ctx, query, respuesta.IDNivelRespuesta, respuesta.IDIndicador, respuesta.IDAutoevaluacion, respuesta.Nota).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("error creating respuesta: %w", err)
 	}
@@ -36,15 +38,17 @@ func (r *RespuestaRepository) Create(ctx context.Context, tx repository.Transact
 
 func (r *RespuestaRepository) Upsert(ctx context.Context, tx repository.Transaction, respuesta *domain.Respuesta) (int, error) {
 	query := `
-		INSERT INTO respuestas (id_nivel_respuesta, id_indicador, id_autoevaluacion)
-		VALUES ($1, $2, $3)
+		INSERT INTO respuestas (id_nivel_respuesta, id_indicador, id_autoevaluacion, nota)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (id_autoevaluacion, id_indicador) 
-		DO UPDATE SET id_nivel_respuesta = EXCLUDED.id_nivel_respuesta
+		DO UPDATE SET id_nivel_respuesta = EXCLUDED.id_nivel_respuesta, nota = EXCLUDED.nota,
+		    estado_revision = CASE WHEN respuestas.id_nivel_respuesta = EXCLUDED.id_nivel_respuesta
+		                           THEN respuestas.estado_revision ELSE 'PENDIENTE_REVISION' END
 		RETURNING id_respuesta
 	`
 
 	var id int
-	err := r.db.QueryRowContext(ctx, query, respuesta.IDNivelRespuesta, respuesta.IDIndicador, respuesta.IDAutoevaluacion).Scan(&id)
+	err := conn(r.db, tx).QueryRowContext(ctx, query, respuesta.IDNivelRespuesta, respuesta.IDIndicador, respuesta.IDAutoevaluacion, respuesta.Nota).Scan(&id)
 	if err != nil {
 		return 0, fmt.Errorf("error upserting respuesta: %w", err)
 	}
@@ -55,7 +59,7 @@ func (r *RespuestaRepository) Upsert(ctx context.Context, tx repository.Transact
 
 func (r *RespuestaRepository) FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.Respuesta, error) {
 	query := `
-		SELECT id_respuesta, id_nivel_respuesta, id_indicador, id_autoevaluacion
+		SELECT id_respuesta, id_nivel_respuesta, id_indicador, id_autoevaluacion, nota
 		FROM respuestas
 		WHERE id_autoevaluacion = $1
 	`
@@ -69,7 +73,7 @@ func (r *RespuestaRepository) FindByAutoevaluacion(ctx context.Context, idAutoev
 	var respuestas []*domain.Respuesta
 	for rows.Next() {
 		resp := &domain.Respuesta{}
-		if err := rows.Scan(&resp.ID, &resp.IDNivelRespuesta, &resp.IDIndicador, &resp.IDAutoevaluacion); err != nil {
+		if err := rows.Scan(&resp.ID, &resp.IDNivelRespuesta, &resp.IDIndicador, &resp.IDAutoevaluacion, &resp.Nota); err != nil {
 			return nil, fmt.Errorf("error scanning respuesta: %w", err)
 		}
 		respuestas = append(respuestas, resp)
@@ -89,19 +93,50 @@ func (r *RespuestaRepository) DeleteByAutoevaluacion(ctx context.Context, idAuto
 	return nil
 }
 
-func (r *RespuestaRepository) CalculateTotalScore(ctx context.Context, idAutoevaluacion int) (int, error) {
+// DeleteByIDs elimina las respuestas indicadas; sus evidencias deben eliminarse antes
+func (r *RespuestaRepository) DeleteByIDs(ctx context.Context, tx repository.Transaction, ids []int) error {
+	query := `DELETE FROM respuestas WHERE id_respuesta = ANY($1)`
+
+	_, err := conn(r.db, tx).ExecContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error deleting respuestas: %w", err)
+	}
+
+	return nil
+}
+
+// FindDetalleByAutoevaluacion devuelve las respuestas con capítulo, indicador, nivel elegido, nota
+// y revisión del auditor, en el orden del cuestionario
+func (r *RespuestaRepository) FindDetalleByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.RespuestaDetalle, error) {
 	query := `
-		SELECT COALESCE(SUM(nr.puntos), 0) as total_puntos
+		SELECT r.id_respuesta, c.id_capitulo, c.clave, c.nombre, i.id_indicador, i.clave, i.nombre,
+		       nr.id_nivel_respuesta, nr.nombre, nr.puntos, e.id_evidencia IS NOT NULL, r.nota, COALESCE(nr.posicion, 0),
+		       r.estado_revision, r.comentario_revision, e.estado_revision, e.comentario_revision
 		FROM respuestas r
+		INNER JOIN indicadores i ON r.id_indicador = i.id_indicador
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo
 		INNER JOIN niveles_respuesta nr ON r.id_nivel_respuesta = nr.id_nivel_respuesta
+		LEFT JOIN evidencias e ON e.id_respuesta = r.id_respuesta
 		WHERE r.id_autoevaluacion = $1
+		ORDER BY c.orden, i.orden
 	`
 
-	var totalPuntos int
-	err := r.db.QueryRowContext(ctx, query, idAutoevaluacion).Scan(&totalPuntos)
+	rows, err := r.db.QueryContext(ctx, query, idAutoevaluacion)
 	if err != nil {
-		return 0, fmt.Errorf("error calculating total score: %w", err)
+		return nil, fmt.Errorf("error querying detalle de respuestas: %w", err)
+	}
+	defer rows.Close()
+
+	detalles := make([]*domain.RespuestaDetalle, 0)
+	for rows.Next() {
+		d := &domain.RespuestaDetalle{}
+		if err := rows.Scan(&d.ID, &d.IDCapitulo, &d.ClaveCapitulo, &d.Capitulo, &d.IDIndicador, &d.ClaveIndicador, &d.Indicador,
+			&d.IDNivelRespuesta, &d.NivelRespuesta, &d.Puntos, &d.TieneEvidencia, &d.Nota, &d.PosicionNivel,
+			&d.EstadoRevision, &d.ComentarioRevision, &d.EstadoRevisionEvidencia, &d.ComentarioRevisionEvidencia); err != nil {
+			return nil, fmt.Errorf("error scanning detalle de respuesta: %w", err)
+		}
+		detalles = append(detalles, d)
 	}
 
-	return totalPuntos, nil
+	return detalles, rows.Err()
 }
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type ResultadoCapituloRepository struct {
+	db *sql.DB
+}
+
+func NewResultadoCapituloRepository(db *sql.DB) repository.ResultadoCapituloRepository {
+	return &ResultadoCapituloRepository{db: db}
+}
+
+// FindPuntajesIndicador devuelve, para cada indicador de la versión habilitado para el segmento,
+// los puntos obtenidos en la respuesta (0 si no fue respondido), los puntos máximos, los pesos y
+// si la respuesta fue rechazada por el auditor.
+// El cálculo de los resultados queda a cargo de la estrategia de puntaje del servicio.
+func (r *ResultadoCapituloRepository) FindPuntajesIndicador(ctx context.Context, idAutoevaluacion int, idSegmento int, idGuiaVersion int) ([]*domain.PuntajeIndicador, error) {
+	query := `
+		SELECT c.id_capitulo, c.nombre, c.peso, i.id_indicador, i.peso,
+		       COALESCE(nr.puntos, 0), COALESCE(m.max_puntos, 0), COALESCE(r.estado_revision = 'RECHAZADA', false)
+		FROM segmento_indicador si
+		INNER JOIN indicadores i ON si.id_indicador = i.id_indicador
+		INNER JOIN capitulos c ON i.id_capitulo = c.id_capitulo AND c.id_guia_version = $3
+		LEFT JOIN (
+			SELECT id_indicador, MAX(puntos) AS max_puntos
+			FROM niveles_respuesta
+			GROUP BY id_indicador
+		) m ON m.id_indicador = i.id_indicador
+		LEFT JOIN respuestas r ON r.id_indicador = i.id_indicador AND r.id_autoevaluacion = $1
+		LEFT JOIN niveles_respuesta nr ON r.id_nivel_respuesta = nr.id_nivel_respuesta
+		WHERE si.id_segmento = $2
+		ORDER BY c.orden, i.orden
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idAutoevaluacion, idSegmento, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying puntajes por indicador: %w", err)
+	}
+	defer rows.Close()
+
+	puntajes := make([]*domain.PuntajeIndicador, 0)
+	for rows.Next() {
+		p := &domain.PuntajeIndicador{}
+		if err := rows.Scan(&p.IDCapitulo, &p.Capitulo, &p.PesoCapitulo, &p.IDIndicador, &p.PesoIndicador, &p.Puntos, &p.PuntosMaximos, &p.Rechazada); err != nil {
+			return nil, fmt.Errorf("error scanning puntaje por indicador: %w", err)
+		}
+		puntajes = append(puntajes, p)
+	}
+
+	return puntajes, rows.Err()
+}
+
+// ReplaceByAutoevaluacion reemplaza los resultados guardados de la autoevaluación
+func (r *ResultadoCapituloRepository) ReplaceByAutoevaluacion(ctx context.Context, tx repository.Transaction, idAutoevaluacion int, resultados []*domain.ResultadoCapitulo) error {
+	q := conn(r.db, tx)
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM resultados_capitulo WHERE id_autoevaluacion = $1`, idAutoevaluacion); err != nil {
+		return fmt.Errorf("error deleting resultados por capitulo: %w", err)
+	}
+
+	query := `
+		INSERT INTO resultados_capitulo (id_autoevaluacion, id_capitulo, puntos_obtenidos, puntos_maximos, porcentaje)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, res := range resultados {
+		if _, err := q.ExecContext(ctx, query, idAutoevaluacion, res.IDCapitulo, res.PuntosObtenidos, res.PuntosMaximos, res.Porcentaje); err != nil {
+			return fmt.Errorf("error saving resultado por capitulo: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *ResultadoCapituloRepository) FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.ResultadoCapitulo, error) {
+	query := `
+		SELECT rc.id_capitulo, c.nombre, rc.puntos_obtenidos, rc.puntos_maximos, rc.porcentaje
+		FROM resultados_capitulo rc
+		INNER JOIN capitulos c ON rc.id_capitulo = c.id_capitulo
+		WHERE rc.id_autoevaluacion = $1
+		ORDER BY c.orden
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying resultados por capitulo: %w", err)
+	}
+	defer rows.Close()
+
+	resultados := make([]*domain.ResultadoCapitulo, 0)
+	for rows.Next() {
+		res := &domain.ResultadoCapitulo{}
+		if err := rows.Scan(&res.IDCapitulo, &res.Capitulo, &res.PuntosObtenidos, &res.PuntosMaximos, &res.Porcentaje); err != nil {
+			return nil, fmt.Errorf("error scanning resultado por capitulo: %w", err)
+		}
+		resultados = append(resultados, res)
+	}
+
+	return resultados, rows.Err()
+}
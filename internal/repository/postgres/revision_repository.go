@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+type RevisionRepository struct {
+	db *sql.DB
+}
+
+func NewRevisionRepository(db *sql.DB) repository.RevisionRepository {
+	return &RevisionRepository{db: db}
+}
+
+// Iniciar pasa a EN_REVISION una autoevaluación completada que todavía no fue tomada por
+// un auditor
+func (r *RevisionRepository) Iniciar(ctx context.Context, idAutoevaluacion int, idCuenta int) error {
+	query := `
+		UPDATE autoevaluaciones
+		SET estado_revision = $1, id_cuenta_revision = $2, fecha_inicio_revision = NOW()
+		WHERE id_autoevaluacion = $3 AND estado = $4 AND estado_revision IS NULL
+	`
+
+	result, err := r.db.ExecContext(ctx, query, domain.RevisionEnCurso, idCuenta, idAutoevaluacion, domain.EstadoCompletada)
+	if err != nil {
+		return fmt.Errorf("error starting revision: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrRevisionNoDisponible
+	}
+
+	return nil
+}
+
+// revisionDelAuditor restringe una actualización a las autoevaluaciones ($5) en revisión por
+// el auditor ($3), para que nadie más tome una revisión ya empezada
+const revisionDelAuditor = `
+	EXISTS (
+		SELECT 1 FROM autoevaluaciones a
+		WHERE a.id_autoevaluacion = $5 AND a.estado_revision = 'EN_REVISION' AND a.id_cuenta_revision = $3
+	)`
+
+func (r *RevisionRepository) RevisarRespuesta(ctx context.Context, idAutoevaluacion int, idRespuesta int, idCuenta int, estado domain.EstadoRevision, comentario *string) error {
+	query := `
+		UPDATE respuestas
+		SET estado_revision = $1, comentario_revision = $2, id_cuenta_revision = $3, fecha_revision = NOW()
+		WHERE id_respuesta = $4 AND id_autoevaluacion = $5 AND` + revisionDelAuditor
+
+	result, err := r.db.ExecContext(ctx, query, estado, comentario, idCuenta, idRespuesta, idAutoevaluacion)
+	if err != nil {
+		return fmt.Errorf("error updating revision de respuesta: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RevisionRepository) RevisarEvidencia(ctx context.Context, idAutoevaluacion int, idRespuesta int, idCuenta int, estado domain.EstadoRevision, comentario *string) error {
+	query := `
+		UPDATE evidencias e
+		SET estado_revision = $1, comentario_revision = $2, id_cuenta_revision = $3, fecha_revision = NOW()
+		FROM respuestas r
+		WHERE e.id_respuesta = r.id_respuesta AND r.id_respuesta = $4 AND r.id_autoevaluacion = $5 AND` + revisionDelAuditor
+
+	result, err := r.db.ExecContext(ctx, query, estado, comentario, idCuenta, idRespuesta, idAutoevaluacion)
+	if err != nil {
+		return fmt.Errorf("error updating revision de evidencia: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrNotFound
+	}
+
+	return nil
+}
+
+// Finalizar cierra la revisión en curso del auditor con su resultado y el puntaje
+// recalculado sin las respuestas rechazadas
+func (r *RevisionRepository) Finalizar(ctx context.Context, idAutoevaluacion int, idCuenta int, resultado domain.EstadoRevisionAutoevaluacion, comentario *string, puntaje int, idNivelSostenibilidad *int) error {
+	query := `
+		UPDATE autoevaluaciones
+		SET estado_revision = $1, comentario_revision = $2, fecha_fin_revision = NOW(),
+		    puntaje_validado = $3, id_nivel_sostenibilidad_validado = $4
+		WHERE id_autoevaluacion = $5 AND estado_revision = $6 AND id_cuenta_revision = $7
+	`
+
+	result, err := r.db.ExecContext(ctx, query, resultado, comentario, puntaje, idNivelSostenibilidad, idAutoevaluacion, domain.RevisionEnCurso, idCuenta)
+	if err != nil {
+		return fmt.Errorf("error finishing revision: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return domain.ErrRevisionNoEnCurso
+	}
+
+	return nil
+}
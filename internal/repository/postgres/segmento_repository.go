@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
+
 	"coviar_backend/internal/domain"
 	"coviar_backend/internal/repository"
 )
@@ -53,15 +55,15 @@ func (r *SegmentoRepository) FindByID(ctx context.Context, id int) (*domain.Segm
 	return seg, nil
 }
 
-func (r *SegmentoRepository) FindNivelesSostenibilidadBySegmento(ctx context.Context, idSegmento int) ([]*domain.NivelSostenibilidad, error) {  // ✅ NUEVO MÉTODO COMPLETO
+func (r *SegmentoRepository) FindNivelesSostenibilidadBySegmento(ctx context.Context, idSegmento int, idGuiaVersion int) ([]*domain.NivelSostenibilidad, error) {  // ✅ NUEVO MÉTODO COMPLETO
 	query := `
-		SELECT id_nivel_sostenibilidad, id_segmento, nombre, min_puntaje, max_puntaje 
+		SELECT id_nivel_sostenibilidad, id_segmento, id_guia_version, nombre, min_puntaje, max_puntaje, meses_vigencia 
 		FROM niveles_sostenibilidad 
-		WHERE id_segmento = $1
+		WHERE id_segmento = $1 AND id_guia_version = $2
 		ORDER BY min_puntaje ASC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, idSegmento)
+	rows, err := r.db.QueryContext(ctx, query, idSegmento, idGuiaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error querying niveles_sostenibilidad: %w", err)
 	}
@@ -70,11 +72,116 @@ func (r *SegmentoRepository) FindNivelesSostenibilidadBySegmento(ctx context.Con
 	var niveles []*domain.NivelSostenibilidad
 	for rows.Next() {
 		nivel := &domain.NivelSostenibilidad{}
-		if err := rows.Scan(&nivel.ID, &nivel.IDSegmento, &nivel.Nombre, &nivel.MinPuntaje, &nivel.MaxPuntaje); err != nil {
+		if err := rows.Scan(&nivel.ID, &nivel.IDSegmento, &nivel.IDGuiaVersion, &nivel.Nombre, &nivel.MinPuntaje, &nivel.MaxPuntaje, &nivel.MesesVigencia); err != nil {
+			return nil, fmt.Errorf("error scanning nivel_sostenibilidad: %w", err)
+		}
+		niveles = append(niveles, nivel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return niveles, r.cargarRequisitos(ctx, niveles)
+}
+
+func (r *SegmentoRepository) FindNivelesSostenibilidadByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.NivelSostenibilidad, error) {
+	query := `
+		SELECT id_nivel_sostenibilidad, id_segmento, id_guia_version, nombre, min_puntaje, max_puntaje, meses_vigencia
+		FROM niveles_sostenibilidad
+		WHERE id_guia_version = $1
+		ORDER BY id_segmento, min_puntaje
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error querying niveles_sostenibilidad: %w", err)
+	}
+	defer rows.Close()
+
+	niveles := make([]*domain.NivelSostenibilidad, 0)
+	for rows.Next() {
+		nivel := &domain.NivelSostenibilidad{}
+		if err := rows.Scan(&nivel.ID, &nivel.IDSegmento, &nivel.IDGuiaVersion, &nivel.Nombre, &nivel.MinPuntaje, &nivel.MaxPuntaje, &nivel.MesesVigencia); err != nil {
 			return nil, fmt.Errorf("error scanning nivel_sostenibilidad: %w", err)
 		}
 		niveles = append(niveles, nivel)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return niveles, r.cargarRequisitos(ctx, niveles)
+}
+
+// ReplaceNivelesSostenibilidad reemplaza los rangos de niveles de un segmento en una versión de la guía
+func (r *SegmentoRepository) ReplaceNivelesSostenibilidad(ctx context.Context, tx repository.Transaction, idSegmento int, idGuiaVersion int, niveles []*domain.NivelSostenibilidad) error {
+	q := conn(r.db, tx)
+
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM niveles_sostenibilidad WHERE id_segmento = $1 AND id_guia_version = $2
+	`, idSegmento, idGuiaVersion); err != nil {
+		return fmt.Errorf("error deleting niveles_sostenibilidad: %w", err)
+	}
+
+	for _, nivel := range niveles {
+		err := q.QueryRowContext(ctx, `
+			INSERT INTO niveles_sostenibilidad (id_segmento, id_guia_version, nombre, min_puntaje, max_puntaje, meses_vigencia)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING id_nivel_sostenibilidad
+		`, idSegmento, idGuiaVersion, nivel.Nombre, nivel.MinPuntaje, nivel.MaxPuntaje, nivel.MesesVigencia).Scan(&nivel.ID)
+		if err != nil {
+			return fmt.Errorf("error inserting nivel_sostenibilidad: %w", err)
+		}
+		nivel.IDSegmento = idSegmento
+		nivel.IDGuiaVersion = idGuiaVersion
+
+		for _, req := range nivel.Requisitos {
+			if _, err := q.ExecContext(ctx, `
+				INSERT INTO requisitos_nivel_capitulo (id_nivel_sostenibilidad, id_capitulo, min_porcentaje)
+				VALUES ($1, $2, $3)
+			`, nivel.ID, req.IDCapitulo, req.MinPorcentaje); err != nil {
+				return fmt.Errorf("error inserting requisito_nivel_capitulo: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// cargarRequisitos completa los porcentajes mínimos por capítulo de cada nivel
+func (r *SegmentoRepository) cargarRequisitos(ctx context.Context, niveles []*domain.NivelSostenibilidad) error {
+	if len(niveles) == 0 {
+		return nil
+	}
+
+	porID := make(map[int]*domain.NivelSostenibilidad, len(niveles))
+	ids := make([]int64, 0, len(niveles))
+	for _, nivel := range niveles {
+		nivel.Requisitos = make([]*domain.RequisitoCapitulo, 0)
+		porID[nivel.ID] = nivel
+		ids = append(ids, int64(nivel.ID))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT rq.id_nivel_sostenibilidad, rq.id_capitulo, rq.min_porcentaje
+		FROM requisitos_nivel_capitulo rq
+		INNER JOIN capitulos c ON rq.id_capitulo = c.id_capitulo
+		WHERE rq.id_nivel_sostenibilidad = ANY($1)
+		ORDER BY c.orden
+	`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("error querying requisitos_nivel_capitulo: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idNivel int
+		req := &domain.RequisitoCapitulo{}
+		if err := rows.Scan(&idNivel, &req.IDCapitulo, &req.MinPorcentaje); err != nil {
+			return fmt.Errorf("error scanning requisito_nivel_capitulo: %w", err)
+		}
+		porID[idNivel].Requisitos = append(porID[idNivel].Requisitos, req)
+	}
 
-	return niveles, rows.Err()
+	return rows.Err()
 }
@@ -36,3 +36,18 @@ func (tm *TransactionManager) BeginTx(ctx context.Context) (repository.Transacti
 	}
 	return &PostgresTransaction{tx: tx}, nil
 }
+
+// querier es el subconjunto común de *sql.DB y *sql.Tx usado por los repositorios
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn devuelve la transacción recibida si existe, o la conexión directa en caso contrario
+func conn(db *sql.DB, tx repository.Transaction) querier {
+	if pt, ok := tx.(*PostgresTransaction); ok && pt != nil {
+		return pt.tx
+	}
+	return db
+}
@@ -20,7 +20,7 @@ func NewUbicacionRepository(db *sql.DB) repository.UbicacionRepository {
 // ===== PROVINCIAS =====
 
 func (r *UbicacionRepository) GetProvincias(ctx context.Context) ([]*domain.Provincia, error) {
-	query := `SELECT id_provincia, nombre FROM provincias ORDER BY nombre`
+	query := `SELECT id_provincia, nombre, id_georef FROM provincias ORDER BY nombre`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -31,7 +31,7 @@ func (r *UbicacionRepository) GetProvincias(ctx context.Context) ([]*domain.Prov
 	var provincias []*domain.Provincia
 	for rows.Next() {
 		provincia := &domain.Provincia{}
-		if err := rows.Scan(&provincia.ID, &provincia.Nombre); err != nil {
+		if err := rows.Scan(&provincia.ID, &provincia.Nombre, &provincia.IDGeoref); err != nil {
 			return nil, fmt.Errorf("error scanning provincia: %w", err)
 		}
 		provincias = append(provincias, provincia)
@@ -41,10 +41,10 @@ func (r *UbicacionRepository) GetProvincias(ctx context.Context) ([]*domain.Prov
 }
 
 func (r *UbicacionRepository) GetProvinciaByID(ctx context.Context, id int) (*domain.Provincia, error) {
-	query := `SELECT id_provincia, nombre FROM provincias WHERE id_provincia = $1`
+	query := `SELECT id_provincia, nombre, id_georef FROM provincias WHERE id_provincia = $1`
 
 	provincia := &domain.Provincia{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&provincia.ID, &provincia.Nombre)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&provincia.ID, &provincia.Nombre, &provincia.IDGeoref)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -59,7 +59,7 @@ func (r *UbicacionRepository) GetProvinciaByID(ctx context.Context, id int) (*do
 // ===== DEPARTAMENTOS =====
 
 func (r *UbicacionRepository) GetDepartamentos(ctx context.Context) ([]*domain.Departamento, error) {
-	query := `SELECT id_departamento, id_provincia, nombre FROM departamentos ORDER BY nombre`
+	query := `SELECT id_departamento, id_provincia, nombre, id_georef FROM departamentos ORDER BY nombre`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -70,7 +70,7 @@ func (r *UbicacionRepository) GetDepartamentos(ctx context.Context) ([]*domain.D
 	var departamentos []*domain.Departamento
 	for rows.Next() {
 		departamento := &domain.Departamento{}
-		if err := rows.Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre); err != nil {
+		if err := rows.Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre, &departamento.IDGeoref); err != nil {
 			return nil, fmt.Errorf("error scanning departamento: %w", err)
 		}
 		departamentos = append(departamentos, departamento)
@@ -80,10 +80,10 @@ func (r *UbicacionRepository) GetDepartamentos(ctx context.Context) ([]*domain.D
 }
 
 func (r *UbicacionRepository) GetDepartamentoByID(ctx context.Context, id int) (*domain.Departamento, error) {
-	query := `SELECT id_departamento, id_provincia, nombre FROM departamentos WHERE id_departamento = $1`
+	query := `SELECT id_departamento, id_provincia, nombre, id_georef FROM departamentos WHERE id_departamento = $1`
 
 	departamento := &domain.Departamento{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre, &departamento.IDGeoref)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -97,7 +97,7 @@ func (r *UbicacionRepository) GetDepartamentoByID(ctx context.Context, id int) (
 
 func (r *UbicacionRepository) GetDepartamentosByProvinciaID(ctx context.Context, provinciaID int) ([]*domain.Departamento, error) {
 	// ✅ LÍNEA 99 CORREGIDA
-	query := `SELECT id_departamento, id_provincia, nombre FROM departamentos WHERE id_provincia = $1 ORDER BY nombre`
+	query := `SELECT id_departamento, id_provincia, nombre, id_georef FROM departamentos WHERE id_provincia = $1 ORDER BY nombre`
 
 	rows, err := r.db.QueryContext(ctx, query, provinciaID)
 	if err != nil {
@@ -108,7 +108,7 @@ func (r *UbicacionRepository) GetDepartamentosByProvinciaID(ctx context.Context,
 	var departamentos []*domain.Departamento
 	for rows.Next() {
 		departamento := &domain.Departamento{}
-		if err := rows.Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre); err != nil {
+		if err := rows.Scan(&departamento.ID, &departamento.IDProvincia, &departamento.Nombre, &departamento.IDGeoref); err != nil {
 			return nil, fmt.Errorf("error scanning departamento: %w", err)
 		}
 		departamentos = append(departamentos, departamento)
@@ -120,7 +120,7 @@ func (r *UbicacionRepository) GetDepartamentosByProvinciaID(ctx context.Context,
 // ===== LOCALIDADES =====
 
 func (r *UbicacionRepository) GetLocalidades(ctx context.Context) ([]*domain.Localidad, error) {
-	query := `SELECT id_localidad, id_departamento, nombre FROM localidades ORDER BY nombre`
+	query := `SELECT id_localidad, id_departamento, nombre, id_georef FROM localidades ORDER BY nombre`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -131,7 +131,7 @@ func (r *UbicacionRepository) GetLocalidades(ctx context.Context) ([]*domain.Loc
 	var localidades []*domain.Localidad
 	for rows.Next() {
 		localidad := &domain.Localidad{}
-		if err := rows.Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre); err != nil {
+		if err := rows.Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre, &localidad.IDGeoref); err != nil {
 			return nil, fmt.Errorf("error scanning localidad: %w", err)
 		}
 		localidades = append(localidades, localidad)
@@ -141,10 +141,10 @@ func (r *UbicacionRepository) GetLocalidades(ctx context.Context) ([]*domain.Loc
 }
 
 func (r *UbicacionRepository) GetLocalidadByID(ctx context.Context, id int) (*domain.Localidad, error) {
-	query := `SELECT id_localidad, id_departamento, nombre FROM localidades WHERE id_localidad = $1`
+	query := `SELECT id_localidad, id_departamento, nombre, id_georef FROM localidades WHERE id_localidad = $1`
 
 	localidad := &domain.Localidad{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre, &localidad.IDGeoref)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -158,7 +158,7 @@ func (r *UbicacionRepository) GetLocalidadByID(ctx context.Context, id int) (*do
 
 func (r *UbicacionRepository) GetLocalidadesByDepartamentoID(ctx context.Context, departamentoID int) ([]*domain.Localidad, error) {
 	// ✅ LÍNEA 165 CORREGIDA
-	query := `SELECT id_localidad, id_departamento, nombre FROM localidades WHERE id_departamento = $1 ORDER BY nombre`
+	query := `SELECT id_localidad, id_departamento, nombre, id_georef FROM localidades WHERE id_departamento = $1 ORDER BY nombre`
 
 	rows, err := r.db.QueryContext(ctx, query, departamentoID)
 	if err != nil {
@@ -169,7 +169,7 @@ func (r *UbicacionRepository) GetLocalidadesByDepartamentoID(ctx context.Context
 	var localidades []*domain.Localidad
 	for rows.Next() {
 		localidad := &domain.Localidad{}
-		if err := rows.Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre); err != nil {
+		if err := rows.Scan(&localidad.ID, &localidad.IDDepartamento, &localidad.Nombre, &localidad.IDGeoref); err != nil {
 			return nil, fmt.Errorf("error scanning localidad: %w", err)
 		}
 		localidades = append(localidades, localidad)
@@ -177,3 +177,125 @@ func (r *UbicacionRepository) GetLocalidadesByDepartamentoID(ctx context.Context
 
 	return localidades, rows.Err()
 }
+
+// GetLocalidadesEnUso obtiene los IDs de localidades referenciadas por alguna bodega. Dentro
+// de una transacción primero bloquea las localidades, para que ninguna bodega empiece a
+// referenciar otra hasta que la transacción termine.
+func (r *UbicacionRepository) GetLocalidadesEnUso(ctx context.Context, tx repository.Transaction) ([]int, error) {
+	if tx != nil {
+		if _, err := conn(r.db, tx).ExecContext(ctx, `SELECT 1 FROM localidades FOR UPDATE`); err != nil {
+			return nil, fmt.Errorf("error locking localidades: %w", err)
+		}
+	}
+
+	query := `SELECT DISTINCT id_localidad FROM bodegas WHERE id_localidad IS NOT NULL`
+
+	rows, err := conn(r.db, tx).QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting localidades en uso: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning localidad en uso: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// ===== IMPORTACIÓN DEL CATÁLOGO =====
+
+func (r *UbicacionRepository) CreateProvincia(ctx context.Context, tx repository.Transaction, provincia *domain.Provincia) (int, error) {
+	query := `INSERT INTO provincias (nombre, id_georef) VALUES ($1, $2) RETURNING id_provincia`
+
+	var id int
+	if err := conn(r.db, tx).QueryRowContext(ctx, query, provincia.Nombre, provincia.IDGeoref).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error creating provincia: %w", err)
+	}
+
+	provincia.ID = id
+	return id, nil
+}
+
+func (r *UbicacionRepository) UpdateProvincia(ctx context.Context, tx repository.Transaction, provincia *domain.Provincia) error {
+	query := `UPDATE provincias SET nombre = $1, id_georef = $2 WHERE id_provincia = $3`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, provincia.Nombre, provincia.IDGeoref, provincia.ID); err != nil {
+		return fmt.Errorf("error updating provincia: %w", err)
+	}
+	return nil
+}
+
+func (r *UbicacionRepository) DeleteProvincia(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `DELETE FROM provincias WHERE id_provincia = $1`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error deleting provincia: %w", err)
+	}
+	return nil
+}
+
+func (r *UbicacionRepository) CreateDepartamento(ctx context.Context, tx repository.Transaction, departamento *domain.Departamento) (int, error) {
+	query := `INSERT INTO departamentos (id_provincia, nombre, id_georef) VALUES ($1, $2, $3) RETURNING id_departamento`
+
+	var id int
+	if err := conn(r.db, tx).QueryRowContext(ctx, query, departamento.IDProvincia, departamento.Nombre, departamento.IDGeoref).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error creating departamento: %w", err)
+	}
+
+	departamento.ID = id
+	return id, nil
+}
+
+func (r *UbicacionRepository) UpdateDepartamento(ctx context.Context, tx repository.Transaction, departamento *domain.Departamento) error {
+	query := `UPDATE departamentos SET id_provincia = $1, nombre = $2, id_georef = $3 WHERE id_departamento = $4`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, departamento.IDProvincia, departamento.Nombre, departamento.IDGeoref, departamento.ID); err != nil {
+		return fmt.Errorf("error updating departamento: %w", err)
+	}
+	return nil
+}
+
+func (r *UbicacionRepository) DeleteDepartamento(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `DELETE FROM departamentos WHERE id_departamento = $1`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error deleting departamento: %w", err)
+	}
+	return nil
+}
+
+func (r *UbicacionRepository) CreateLocalidad(ctx context.Context, tx repository.Transaction, localidad *domain.Localidad) (int, error) {
+	query := `INSERT INTO localidades (id_departamento, nombre, id_georef) VALUES ($1, $2, $3) RETURNING id_localidad`
+
+	var id int
+	if err := conn(r.db, tx).QueryRowContext(ctx, query, localidad.IDDepartamento, localidad.Nombre, localidad.IDGeoref).Scan(&id); err != nil {
+		return 0, fmt.Errorf("error creating localidad: %w", err)
+	}
+
+	localidad.ID = id
+	return id, nil
+}
+
+func (r *UbicacionRepository) UpdateLocalidad(ctx context.Context, tx repository.Transaction, localidad *domain.Localidad) error {
+	query := `UPDATE localidades SET id_departamento = $1, nombre = $2, id_georef = $3 WHERE id_localidad = $4`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, localidad.IDDepartamento, localidad.Nombre, localidad.IDGeoref, localidad.ID); err != nil {
+		return fmt.Errorf("error updating localidad: %w", err)
+	}
+	return nil
+}
+
+func (r *UbicacionRepository) DeleteLocalidad(ctx context.Context, tx repository.Transaction, id int) error {
+	query := `DELETE FROM localidades WHERE id_localidad = $1`
+
+	if _, err := conn(r.db, tx).ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("error deleting localidad: %w", err)
+	}
+	return nil
+}
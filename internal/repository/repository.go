@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"coviar_backend/internal/domain"
 )
@@ -65,6 +66,18 @@ type UbicacionRepository interface {
 	GetLocalidades(ctx context.Context) ([]*domain.Localidad, error)
 	GetLocalidadByID(ctx context.Context, id int) (*domain.Localidad, error)
 	GetLocalidadesByDepartamentoID(ctx context.Context, departamentoID int) ([]*domain.Localidad, error)
+	GetLocalidadesEnUso(ctx context.Context, tx Transaction) ([]int, error)
+
+	// Importación del catálogo oficial
+	CreateProvincia(ctx context.Context, tx Transaction, provincia *domain.Provincia) (int, error)
+	UpdateProvincia(ctx context.Context, tx Transaction, provincia *domain.Provincia) error
+	DeleteProvincia(ctx context.Context, tx Transaction, id int) error
+	CreateDepartamento(ctx context.Context, tx Transaction, departamento *domain.Departamento) (int, error)
+	UpdateDepartamento(ctx context.Context, tx Transaction, departamento *domain.Departamento) error
+	DeleteDepartamento(ctx context.Context, tx Transaction, id int) error
+	CreateLocalidad(ctx context.Context, tx Transaction, localidad *domain.Localidad) (int, error)
+	UpdateLocalidad(ctx context.Context, tx Transaction, localidad *domain.Localidad) error
+	DeleteLocalidad(ctx context.Context, tx Transaction, id int) error
 }
 
 // TransactionManager maneja transacciones
@@ -73,35 +86,106 @@ type TransactionManager interface {
 }
 
 // Repositorios para Autoevaluación
+type GuiaVersionRepository interface {
+	FindAll(ctx context.Context) ([]*domain.GuiaVersion, error)
+	FindByID(ctx context.Context, id int) (*domain.GuiaVersion, error)
+	FindPublicada(ctx context.Context) (*domain.GuiaVersion, error)
+	FindBorrador(ctx context.Context) (*domain.GuiaVersion, error)
+	CrearBorrador(ctx context.Context, tx Transaction, idOrigen int, descripcion string) (*domain.GuiaVersion, error)
+	CrearBorradorVacio(ctx context.Context, tx Transaction, idOrigen int, descripcion string) (*domain.GuiaVersion, error)
+	UpdateEstrategia(ctx context.Context, tx Transaction, id int, estrategia domain.EstrategiaPuntaje) error
+	Publicar(ctx context.Context, tx Transaction, id int) error
+	Delete(ctx context.Context, tx Transaction, id int) error
+}
+
 type SegmentoRepository interface {
 	FindAll(ctx context.Context) ([]*domain.Segmento, error)
 	FindByID(ctx context.Context, id int) (*domain.Segmento, error)
-	FindNivelesSostenibilidadBySegmento(ctx context.Context, idSegmento int) ([]*domain.NivelSostenibilidad, error)
+	FindNivelesSostenibilidadBySegmento(ctx context.Context, idSegmento int, idGuiaVersion int) ([]*domain.NivelSostenibilidad, error)
+	FindNivelesSostenibilidadByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.NivelSostenibilidad, error)
+	ReplaceNivelesSostenibilidad(ctx context.Context, tx Transaction, idSegmento int, idGuiaVersion int, niveles []*domain.NivelSostenibilidad) error
 }
 
 type AutoevaluacionRepository interface {
 	Create(ctx context.Context, tx Transaction, auto *domain.Autoevaluacion) (int, error)
 	FindByID(ctx context.Context, id int) (*domain.Autoevaluacion, error)
-	UpdateSegmento(ctx context.Context, id int, idSegmento int) error
+	UpdateSegmento(ctx context.Context, tx Transaction, id int, idSegmento int) error
 	Complete(ctx context.Context, id int) error
 	FindPendienteByBodega(ctx context.Context, idBodega int) (*domain.Autoevaluacion, error)
-	CompleteWithScore(ctx context.Context, id int, puntajeFinal int, idNivelSostenibilidad int) error
-	Cancel(ctx context.Context, id int) error
+	CompleteWithScore(ctx context.Context, tx Transaction, id int, puntajeFinal int, idNivelSostenibilidad int) error
+	Cancel(ctx context.Context, tx Transaction, id int) error
 	HasPendingByBodega(ctx context.Context, idBodega int) (bool, error)
-	UpdateEvidenciaStatus(ctx context.Context, id int, estado domain.EstadoEvidencia) error
+	UpdateEvidenciaStatus(ctx context.Context, tx Transaction, id int, estado domain.EstadoEvidencia) error
+	FindByBodega(ctx context.Context, filtro domain.FiltroAutoevaluaciones) ([]*domain.AutoevaluacionResumen, int, error)
+	FindResumenByID(ctx context.Context, id int) (*domain.AutoevaluacionResumen, error)
+	Reopen(ctx context.Context, tx Transaction, id int) error
+	AvanzarVersion(ctx context.Context, tx Transaction, id int, esperada *int) (int, error)
+	RegistrarActividad(ctx context.Context, tx Transaction, id int) error
+	FindPendientesInactivas(ctx context.Context, sinActividadDesde time.Time) ([]*domain.AutoevaluacionInactiva, error)
+	MarcarAvisoInactividad(ctx context.Context, id int) error
+	CancelarInactiva(ctx context.Context, id int, sinActividadDesde, avisadaHasta time.Time) (bool, error)
+	FindUltimasCompletadas(ctx context.Context) ([]*domain.UltimaEvaluacionBodega, error)
+	FindUltimaCompletadaByBodega(ctx context.Context, idBodega int) (*domain.UltimaEvaluacionBodega, error)
+	MarcarRecordatorioVigencia(ctx context.Context, id int, diasAntes int) error
+}
+
+type AuditorRepository interface {
+	FindAll(ctx context.Context) ([]*domain.Auditor, error)
+	ReplaceBodegas(ctx context.Context, tx Transaction, idCuenta int, idsBodega []int) error
+	EstaAsignado(ctx context.Context, idCuenta int, idBodega int) (bool, error)
+	FindAutoevaluacionesAsignadas(ctx context.Context, idCuenta int, estadoRevision *domain.EstadoRevisionAutoevaluacion) ([]*domain.AutoevaluacionResumen, error)
+}
+
+type RevisionRepository interface {
+	Iniciar(ctx context.Context, idAutoevaluacion int, idCuenta int) error
+	RevisarRespuesta(ctx context.Context, idAutoevaluacion int, idRespuesta int, idCuenta int, estado domain.EstadoRevision, comentario *string) error
+	RevisarEvidencia(ctx context.Context, idAutoevaluacion int, idRespuesta int, idCuenta int, estado domain.EstadoRevision, comentario *string) error
+	Finalizar(ctx context.Context, idAutoevaluacion int, idCuenta int, resultado domain.EstadoRevisionAutoevaluacion, comentario *string, puntaje int, idNivelSostenibilidad *int) error
+}
+
+type AccionMejoraRepository interface {
+	Create(ctx context.Context, tx Transaction, accion *domain.AccionMejora) (int, error)
+	FindByID(ctx context.Context, id int) (*domain.AccionMejora, error)
+	Update(ctx context.Context, tx Transaction, accion *domain.AccionMejora) error
+	FindByBodega(ctx context.Context, filtro domain.FiltroAccionesMejora) ([]*domain.AccionMejora, error)
+}
+
+type EnmiendaRepository interface {
+	Create(ctx context.Context, tx Transaction, enmienda *domain.EnmiendaAutoevaluacion) (int, error)
+	FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.EnmiendaAutoevaluacion, error)
+	RegistrarRecompletado(ctx context.Context, tx Transaction, idAutoevaluacion int, puntaje int, idNivelSostenibilidad *int) error
 }
 
 type CapituloRepository interface {
-	FindAll(ctx context.Context) ([]*domain.Capitulo, error)
+	FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Capitulo, error)
+	FindByID(ctx context.Context, id int) (*domain.Capitulo, error)
+	Create(ctx context.Context, tx Transaction, capitulo *domain.Capitulo) (int, error)
+	Update(ctx context.Context, tx Transaction, capitulo *domain.Capitulo) error
+	UpdateOrden(ctx context.Context, tx Transaction, id int, orden int) error
+	Delete(ctx context.Context, tx Transaction, id int) error
 }
 
 type IndicadorRepository interface {
 	FindByCapitulo(ctx context.Context, idCapitulo int) ([]*domain.Indicador, error)
-	FindBySegmento(ctx context.Context, idSegmento int) ([]int, error)
+	FindBySegmento(ctx context.Context, idSegmento int, idGuiaVersion int) ([]int, error)
+	FindByID(ctx context.Context, id int) (*domain.Indicador, error)
+	FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Indicador, error)
+	Create(ctx context.Context, tx Transaction, indicador *domain.Indicador) (int, error)
+	Update(ctx context.Context, tx Transaction, indicador *domain.Indicador) error
+	UpdateOrden(ctx context.Context, tx Transaction, id int, orden int) error
+	Delete(ctx context.Context, tx Transaction, id int) error
+	FindSegmentos(ctx context.Context, idIndicador int) ([]int, error)
+	ReplaceSegmentos(ctx context.Context, tx Transaction, idIndicador int, segmentos []int) error
 }
 
 type NivelRespuestaRepository interface {
 	FindByIndicador(ctx context.Context, idIndicador int) ([]*domain.NivelRespuesta, error)
+	FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.NivelRespuesta, error)
+	FindByID(ctx context.Context, id int) (*domain.NivelRespuesta, error)
+	Create(ctx context.Context, tx Transaction, nivel *domain.NivelRespuesta) (int, error)
+	Update(ctx context.Context, tx Transaction, nivel *domain.NivelRespuesta) error
+	UpdatePosicion(ctx context.Context, tx Transaction, id int, posicion int) error
+	Delete(ctx context.Context, tx Transaction, id int) error
 }
 
 type RespuestaRepository interface {
@@ -109,7 +193,14 @@ type RespuestaRepository interface {
 	Upsert(ctx context.Context, tx Transaction, respuesta *domain.Respuesta) (int, error)
 	FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.Respuesta, error)
 	DeleteByAutoevaluacion(ctx context.Context, idAutoevaluacion int) error
-	CalculateTotalScore(ctx context.Context, idAutoevaluacion int) (int, error)
+	DeleteByIDs(ctx context.Context, tx Transaction, ids []int) error
+	FindDetalleByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.RespuestaDetalle, error)
+}
+
+type ResultadoCapituloRepository interface {
+	FindPuntajesIndicador(ctx context.Context, idAutoevaluacion int, idSegmento int, idGuiaVersion int) ([]*domain.PuntajeIndicador, error)
+	ReplaceByAutoevaluacion(ctx context.Context, tx Transaction, idAutoevaluacion int, resultados []*domain.ResultadoCapitulo) error
+	FindByAutoevaluacion(ctx context.Context, idAutoevaluacion int) ([]*domain.ResultadoCapitulo, error)
 }
 
 type EvidenciaRepository interface {
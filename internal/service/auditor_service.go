@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
+)
+
+// AuditorService administra las cuentas AUDITOR y las bodegas que cada una revisa
+type AuditorService struct {
+	cuentaRepo  repository.CuentaRepository
+	auditorRepo repository.AuditorRepository
+	bodegaRepo  repository.BodegaRepository
+	txManager   repository.TransactionManager
+}
+
+func NewAuditorService(cuentaRepo repository.CuentaRepository, auditorRepo repository.AuditorRepository, bodegaRepo repository.BodegaRepository, txManager repository.TransactionManager) *AuditorService {
+	return &AuditorService{
+		cuentaRepo:  cuentaRepo,
+		auditorRepo: auditorRepo,
+		bodegaRepo:  bodegaRepo,
+		txManager:   txManager,
+	}
+}
+
+// CrearAuditor registra una cuenta AUDITOR, sin bodegas asignadas
+func (s *AuditorService) CrearAuditor(ctx context.Context, req *domain.CuentaRequest) (*domain.Auditor, error) {
+	req.EmailLogin = strings.TrimSpace(req.EmailLogin)
+
+	var errs validator.ValidationErrors
+	if err := validator.ValidateEmail(req.EmailLogin); err != nil {
+		errs = append(errs, validator.ValidationError{Field: "email_login", Message: err.Error()})
+	}
+	if err := validator.ValidatePasswordStrength(req.Password); err != nil {
+		errs = append(errs, validator.ValidationError{Field: "password", Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	existente, err := s.cuentaRepo.FindByEmail(ctx, req.EmailLogin)
+	if err != nil {
+		return nil, fmt.Errorf("error al buscar cuenta: %w", err)
+	}
+	if existente != nil {
+		return nil, domain.ErrEmailYaRegistrado
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return nil, fmt.Errorf("error al hashear contraseña: %w", err)
+	}
+
+	cuenta := &domain.Cuenta{
+		Tipo:         domain.TipoCuentaAuditor,
+		EmailLogin:   req.EmailLogin,
+		PasswordHash: hash,
+	}
+	if _, err := s.cuentaRepo.Create(ctx, nil, cuenta); err != nil {
+		return nil, err
+	}
+
+	return &domain.Auditor{
+		ID:            cuenta.ID,
+		EmailLogin:    cuenta.EmailLogin,
+		FechaRegistro: cuenta.FechaRegistro,
+		Bodegas:       make([]*domain.BodegaAsignada, 0),
+	}, nil
+}
+
+func (s *AuditorService) ListarAuditores(ctx context.Context) ([]*domain.Auditor, error) {
+	return s.auditorRepo.FindAll(ctx)
+}
+
+// AsignarBodegas reemplaza las bodegas asignadas al auditor por las indicadas
+func (s *AuditorService) AsignarBodegas(ctx context.Context, idCuenta int, idsBodega []int) error {
+	cuenta, err := s.cuentaRepo.FindByID(ctx, idCuenta)
+	if err != nil {
+		return err
+	}
+	if cuenta.Tipo != domain.TipoCuentaAuditor {
+		return validator.ValidationErrors{{Field: "id_cuenta", Message: "la cuenta no es de un auditor"}}
+	}
+
+	var errs validator.ValidationErrors
+	vistos := make(map[int]bool, len(idsBodega))
+	ids := make([]int, 0, len(idsBodega))
+	for i, id := range idsBodega {
+		if vistos[id] {
+			continue
+		}
+		vistos[id] = true
+		if _, err := s.bodegaRepo.FindByID(ctx, id); err != nil {
+			if err == domain.ErrNotFound {
+				errs = append(errs, validator.ValidationError{Field: fmt.Sprintf("ids_bodega[%d]", i), Message: fmt.Sprintf("la bodega %d no existe", id)})
+				continue
+			}
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.auditorRepo.ReplaceBodegas(ctx, tx, idCuenta, ids); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nil
+}
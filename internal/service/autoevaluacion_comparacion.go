@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/validator"
+)
+
+// Comparar compara dos autoevaluaciones de la misma bodega indicador por indicador.
+// idBase es la autoevaluación de referencia (por ejemplo, la del año anterior).
+// Los indicadores y capítulos se asocian por su clave, estable entre versiones de la guía.
+func (s *AutoevaluacionService) Comparar(ctx context.Context, idBase, idComparada int) (*domain.ComparacionAutoevaluaciones, error) {
+	if idBase == idComparada {
+		return nil, validator.ValidationErrors{{Field: "otro_id", Message: "debe ser una autoevaluación distinta"}}
+	}
+
+	base, err := s.GetDetalle(ctx, idBase)
+	if err != nil {
+		return nil, err
+	}
+	comparada, err := s.GetDetalle(ctx, idComparada)
+	if err != nil {
+		return nil, err
+	}
+	if base.IDBodega != comparada.IDBodega {
+		return nil, validator.ValidationErrors{{Field: "otro_id", Message: "las autoevaluaciones pertenecen a bodegas distintas"}}
+	}
+
+	resultado := &domain.ComparacionAutoevaluaciones{
+		Base:             base.AutoevaluacionResumen,
+		Comparada:        comparada.AutoevaluacionResumen,
+		MismoSegmento:    base.IDSegmento != nil && comparada.IDSegmento != nil && *base.IDSegmento == *comparada.IDSegmento,
+		MismaGuiaVersion: base.IDGuiaVersion == comparada.IDGuiaVersion,
+		Capitulos:        make([]*domain.ComparacionCapitulo, 0),
+		Indicadores:      make([]*domain.ComparacionIndicador, 0, len(base.Respuestas)),
+	}
+
+	if base.PuntajeFinal != nil && comparada.PuntajeFinal != nil {
+		delta := *comparada.PuntajeFinal - *base.PuntajeFinal
+		resultado.DeltaPuntaje = &delta
+	}
+
+	resultado.CambioNivel, err = s.compararNiveles(ctx, base.AutoevaluacionResumen, comparada.AutoevaluacionResumen)
+	if err != nil {
+		return nil, err
+	}
+
+	// Capítulos en el orden del cuestionario: primero los de la base y luego los que solo aparecen en la comparada
+	capitulos := make(map[string]*domain.ComparacionCapitulo)
+	capitulo := func(r *domain.RespuestaDetalle) *domain.ComparacionCapitulo {
+		c, ok := capitulos[r.ClaveCapitulo]
+		if !ok {
+			c = &domain.ComparacionCapitulo{ClaveCapitulo: r.ClaveCapitulo, IDCapitulo: r.IDCapitulo, Capitulo: r.Capitulo}
+			capitulos[r.ClaveCapitulo] = c
+			resultado.Capitulos = append(resultado.Capitulos, c)
+		}
+		return c
+	}
+
+	respuestasComparada := make(map[string]*domain.RespuestaDetalle, len(comparada.Respuestas))
+	for _, r := range comparada.Respuestas {
+		respuestasComparada[r.ClaveIndicador] = r
+	}
+
+	for _, rb := range base.Respuestas {
+		capitulo(rb).SubtotalBase += rb.Puntos
+
+		item := &domain.ComparacionIndicador{
+			ClaveIndicador: rb.ClaveIndicador,
+			IDIndicador:    rb.IDIndicador,
+			Indicador:      rb.Indicador,
+			IDCapitulo:     rb.IDCapitulo,
+			NivelBase:      &rb.NivelRespuesta,
+			PuntosBase:     &rb.Puntos,
+		}
+
+		rc, ok := respuestasComparada[rb.ClaveIndicador]
+		if !ok {
+			item.Cambio = domain.CambioSoloEnBase
+			resultado.IndicadoresNoComparables++
+			resultado.Indicadores = append(resultado.Indicadores, item)
+			continue
+		}
+		delete(respuestasComparada, rb.ClaveIndicador)
+
+		delta := rc.Puntos - rb.Puntos
+		item.IDIndicador = rc.IDIndicador
+		item.Indicador = rc.Indicador
+		item.NivelComparada = &rc.NivelRespuesta
+		item.PuntosComparada = &rc.Puntos
+		item.DeltaPuntos = &delta
+		item.Cambio = cambioSegun(delta)
+		resultado.Indicadores = append(resultado.Indicadores, item)
+	}
+
+	for _, rc := range comparada.Respuestas {
+		capitulo(rc).SubtotalComparada += rc.Puntos
+		if _, soloComparada := respuestasComparada[rc.ClaveIndicador]; !soloComparada {
+			continue
+		}
+		resultado.IndicadoresNoComparables++
+		resultado.Indicadores = append(resultado.Indicadores, &domain.ComparacionIndicador{
+			ClaveIndicador:  rc.ClaveIndicador,
+			IDIndicador:     rc.IDIndicador,
+			Indicador:       rc.Indicador,
+			IDCapitulo:      rc.IDCapitulo,
+			NivelComparada:  &rc.NivelRespuesta,
+			PuntosComparada: &rc.Puntos,
+			Cambio:          domain.CambioSoloEnComparada,
+		})
+	}
+
+	for _, c := range resultado.Capitulos {
+		c.Delta = c.SubtotalComparada - c.SubtotalBase
+	}
+
+	return resultado, nil
+}
+
+// compararNiveles determina si el nivel de sostenibilidad subió, bajó o se mantuvo,
+// según la posición de cada nivel dentro de su segmento
+func (s *AutoevaluacionService) compararNiveles(ctx context.Context, base, comparada *domain.AutoevaluacionResumen) (string, error) {
+	if base.IDNivelSostenibilidad == nil || comparada.IDNivelSostenibilidad == nil {
+		return "", nil
+	}
+	if *base.IDNivelSostenibilidad == *comparada.IDNivelSostenibilidad {
+		return domain.CambioIgual, nil
+	}
+
+	posBase, err := s.posicionNivel(ctx, base)
+	if err != nil {
+		return "", err
+	}
+	posComparada, err := s.posicionNivel(ctx, comparada)
+	if err != nil {
+		return "", err
+	}
+	if posBase < 0 || posComparada < 0 {
+		return "", nil
+	}
+	return cambioSegun(posComparada - posBase), nil
+}
+
+// posicionNivel devuelve el índice del nivel de la autoevaluación dentro de los niveles
+// de su segmento (ordenados de menor a mayor puntaje), o -1 si no se encuentra
+func (s *AutoevaluacionService) posicionNivel(ctx context.Context, auto *domain.AutoevaluacionResumen) (int, error) {
+	if auto.IDSegmento == nil {
+		return -1, nil
+	}
+	niveles, err := s.segmentoRepo.FindNivelesSostenibilidadBySegmento(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
+	if err != nil {
+		return -1, fmt.Errorf("error getting niveles sostenibilidad: %w", err)
+	}
+	for i, nivel := range niveles {
+		if nivel.ID == *auto.IDNivelSostenibilidad {
+			return i, nil
+		}
+	}
+	return -1, nil
+}
+
+func cambioSegun(delta int) string {
+	switch {
+	case delta > 0:
+		return domain.CambioSube
+	case delta < 0:
+		return domain.CambioBaja
+	default:
+		return domain.CambioIgual
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/validator"
+)
+
+// GetProgreso indica cuántos de los indicadores habilitados para el segmento ya fueron
+// respondidos, en total y por capítulo, y cuáles faltan responder o no tienen evidencia.
+// Usa los mismos indicadores requeridos que CompletarAutoevaluacion.
+func (s *AutoevaluacionService) GetProgreso(ctx context.Context, idAutoevaluacion int) (*domain.ProgresoAutoevaluacion, error) {
+	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
+	}
+	if auto == nil {
+		return nil, domain.ErrNotFound
+	}
+	if auto.IDSegmento == nil {
+		return nil, validator.ValidationErrors{{Field: "id_segmento", Message: "la autoevaluación no tiene segmento seleccionado"}}
+	}
+
+	// La estructura marca como habilitados los indicadores de IndicadorRepository.FindBySegmento
+	estructura, err := s.GetEstructura(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+	// id_indicador -> tiene evidencia
+	respondidos := make(map[int]bool, len(respuestas))
+	for _, r := range respuestas {
+		respondidos[r.IDIndicador] = r.TieneEvidencia
+	}
+
+	progreso := &domain.ProgresoAutoevaluacion{
+		IDAutoevaluacion: idAutoevaluacion,
+		Capitulos:        make([]*domain.ProgresoCapitulo, 0, len(estructura.Capitulos)),
+		SinResponder:     make([]*domain.IndicadorFaltante, 0),
+		SinEvidencia:     make([]*domain.IndicadorFaltante, 0),
+	}
+	for _, cap := range estructura.Capitulos {
+		capProgreso := &domain.ProgresoCapitulo{
+			IDCapitulo: cap.Capitulo.ID,
+			Capitulo:   cap.Capitulo.Nombre,
+		}
+		for _, ind := range cap.Indicadores {
+			if !ind.Habilitado {
+				continue
+			}
+			capProgreso.Requeridos++
+
+			faltante := &domain.IndicadorFaltante{
+				IDCapitulo:  cap.Capitulo.ID,
+				Capitulo:    cap.Capitulo.Nombre,
+				IDIndicador: ind.Indicador.ID,
+				Clave:       ind.Indicador.Clave,
+				Indicador:   ind.Indicador.Nombre,
+			}
+			tieneEvidencia, respondido := respondidos[ind.Indicador.ID]
+			switch {
+			case !respondido:
+				progreso.SinResponder = append(progreso.SinResponder, faltante)
+			case !tieneEvidencia:
+				capProgreso.Respondidos++
+				progreso.SinEvidencia = append(progreso.SinEvidencia, faltante)
+			default:
+				capProgreso.Respondidos++
+			}
+		}
+		if capProgreso.Requeridos == 0 {
+			continue
+		}
+
+		capProgreso.Porcentaje = porcentaje(capProgreso.Respondidos, capProgreso.Requeridos)
+		progreso.Respondidos += capProgreso.Respondidos
+		progreso.Requeridos += capProgreso.Requeridos
+		progreso.Capitulos = append(progreso.Capitulos, capProgreso)
+	}
+
+	progreso.Porcentaje = porcentaje(progreso.Respondidos, progreso.Requeridos)
+	progreso.Completa = progreso.Requeridos > 0 && len(progreso.SinResponder) == 0
+
+	return progreso, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/validator"
+)
+
+// ReabrirAutoevaluacion vuelve a PENDIENTE una autoevaluación completada para que se
+// corrijan sus respuestas. Solo la usan los administradores y exige un motivo; las
+// respuestas, el puntaje y el nivel anteriores quedan guardados en la enmienda y el
+// resultado se recalcula al volver a completarla. No se puede reabrir mientras un auditor
+// la revisa ni después de validada; sí cuando la revisión la dejó OBSERVADA.
+func (s *AutoevaluacionService) ReabrirAutoevaluacion(ctx context.Context, idAutoevaluacion, idCuenta int, motivo string) (*domain.EnmiendaAutoevaluacion, error) {
+	motivo = strings.TrimSpace(motivo)
+	if motivo == "" {
+		return nil, validator.ValidationErrors{{Field: "motivo", Message: "el motivo es obligatorio"}}
+	}
+
+	resumen, err := s.autoevaluacionRepo.FindResumenByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+	if resumen.Estado != domain.EstadoCompletada {
+		return nil, domain.ErrAutoevaluacionNoCompletada
+	}
+	if resumen.EstadoRevision != nil && *resumen.EstadoRevision != domain.RevisionAutoevaluacionObservada {
+		return nil, domain.ErrAutoevaluacionNoReabrible
+	}
+
+	// La bodega solo puede tener una autoevaluación pendiente a la vez
+	pendiente, err := s.autoevaluacionRepo.FindPendienteByBodega(ctx, resumen.IDBodega)
+	if err != nil {
+		return nil, fmt.Errorf("error checking for pending autoevaluacion: %w", err)
+	}
+	if pendiente != nil {
+		return nil, domain.ErrBodegaConPendiente
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+	capitulos, err := s.resultadoRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resultados por capitulo: %w", err)
+	}
+	if capitulos == nil {
+		capitulos = []*domain.ResultadoCapitulo{}
+	}
+
+	enmienda := &domain.EnmiendaAutoevaluacion{
+		IDAutoevaluacion: idAutoevaluacion,
+		IDCuenta:         idCuenta,
+		Motivo:           motivo,
+		Anterior: &domain.SnapshotAutoevaluacion{
+			FechaFin:              resumen.FechaFin,
+			PuntajeFinal:          resumen.PuntajeFinal,
+			IDNivelSostenibilidad: resumen.IDNivelSostenibilidad,
+			NivelSostenibilidad:   resumen.NivelSostenibilidad,
+			Capitulos:             capitulos,
+			Respuestas:            respuestas,
+
+			EstadoRevision:                resumen.EstadoRevision,
+			ComentarioRevision:            resumen.ComentarioRevision,
+			PuntajeValidado:               resumen.PuntajeValidado,
+			IDNivelSostenibilidadValidado: resumen.IDNivelSostenibilidadValidado,
+		},
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.autoevaluacionRepo.Reopen(ctx, tx, idAutoevaluacion); err != nil {
+		return nil, err
+	}
+	if err := s.resultadoRepo.ReplaceByAutoevaluacion(ctx, tx, idAutoevaluacion, nil); err != nil {
+		return nil, fmt.Errorf("error deleting resultados por capitulo: %w", err)
+	}
+	if _, err := s.enmiendaRepo.Create(ctx, tx, enmienda); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+
+	return enmienda, nil
+}
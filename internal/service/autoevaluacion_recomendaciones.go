@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"coviar_backend/internal/domain"
+)
+
+// Cantidad de recomendaciones usadas por defecto para proyectar el puntaje
+const recomendacionesTopDefecto = 5
+
+// GetRecomendaciones lista, para cada indicador habilitado que no está en su nivel máximo,
+// el nivel siguiente como próximo paso, ordenado por la ganancia de puntos que produciría.
+// Los puntajes se calculan con la estrategia de puntaje de la versión de la guía, así que
+// con PONDERADA la ganancia de cada indicador refleja su peso y el de su capítulo.
+// El puntaje proyectado supone que se implementan las primeras top recomendaciones.
+func (s *AutoevaluacionService) GetRecomendaciones(ctx context.Context, idAutoevaluacion int, top int) (*domain.RecomendacionesResponse, error) {
+	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
+	}
+	if auto == nil || auto.IDSegmento == nil {
+		return nil, fmt.Errorf("autoevaluacion not found or segmento not selected")
+	}
+
+	estructura, err := s.obtenerEstructura(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.guiaVersionRepo.FindByID(ctx, auto.IDGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error finding guia_version: %w", err)
+	}
+	estrategia := estrategiaDe(version)
+
+	respuestas, err := s.respuestaRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+	nivelElegido := make(map[int]int, len(respuestas))
+	for _, r := range respuestas {
+		nivelElegido[r.IDIndicador] = r.IDNivelRespuesta
+	}
+
+	resultado := &domain.RecomendacionesResponse{
+		IDAutoevaluacion: idAutoevaluacion,
+		Recomendaciones:  make([]*domain.Recomendacion, 0),
+	}
+
+	// Puntajes de los indicadores habilitados con las respuestas actuales, y los puntos que
+	// tendría cada indicador recomendado en el nivel sugerido
+	puntajes := make([]*domain.PuntajeIndicador, 0)
+	puntajePorIndicador := make(map[int]*domain.PuntajeIndicador)
+	puntosSugeridos := make(map[int]int)
+
+	for _, cap := range estructura.Capitulos {
+		for _, ind := range cap.Indicadores {
+			if !ind.Habilitado || len(ind.NivelesRespuesta) == 0 {
+				continue
+			}
+
+			// Los niveles vienen ordenados por posición; el siguiente al elegido es el próximo paso
+			siguiente := 0
+			var actual *domain.NivelRespuesta
+			if idNivel, ok := nivelElegido[ind.Indicador.ID]; ok {
+				for i, nivel := range ind.NivelesRespuesta {
+					if nivel.ID == idNivel {
+						actual = nivel
+						siguiente = i + 1
+						break
+					}
+				}
+			}
+
+			p := &domain.PuntajeIndicador{
+				IDCapitulo:    cap.Capitulo.ID,
+				Capitulo:      cap.Capitulo.Nombre,
+				PesoCapitulo:  cap.Capitulo.Peso,
+				IDIndicador:   ind.Indicador.ID,
+				PesoIndicador: ind.Indicador.Peso,
+			}
+			for _, nivel := range ind.NivelesRespuesta {
+				if nivel.Puntos > p.PuntosMaximos {
+					p.PuntosMaximos = nivel.Puntos
+				}
+			}
+			if actual != nil {
+				p.Puntos = actual.Puntos
+			}
+			puntajes = append(puntajes, p)
+			puntajePorIndicador[ind.Indicador.ID] = p
+
+			if siguiente >= len(ind.NivelesRespuesta) {
+				continue
+			}
+
+			sugerido := ind.NivelesRespuesta[siguiente]
+			rec := &domain.Recomendacion{
+				IDCapitulo:      cap.Capitulo.ID,
+				Capitulo:        cap.Capitulo.Nombre,
+				IDIndicador:     ind.Indicador.ID,
+				Indicador:       ind.Indicador.Nombre,
+				IDNivelSugerido: sugerido.ID,
+				NivelSugerido:   sugerido.Nombre,
+				ProximoPaso:     sugerido.Descripcion,
+			}
+			if actual != nil {
+				rec.NivelActual = &actual.Nombre
+				rec.PuntosActuales = actual.Puntos
+			}
+			puntosSugeridos[ind.Indicador.ID] = sugerido.Puntos
+			resultado.Recomendaciones = append(resultado.Recomendaciones, rec)
+		}
+	}
+
+	resultado.PuntajeActual = puntajeTotal(estrategia, puntajes)
+
+	// La ganancia de cada recomendación es la diferencia en el puntaje total al pasar solo
+	// ese indicador al nivel sugerido
+	for _, rec := range resultado.Recomendaciones {
+		p := puntajePorIndicador[rec.IDIndicador]
+		p.Puntos = puntosSugeridos[rec.IDIndicador]
+		rec.Ganancia = puntajeTotal(estrategia, puntajes) - resultado.PuntajeActual
+		p.Puntos = rec.PuntosActuales
+	}
+
+	// A igual ganancia se conserva el orden del cuestionario
+	sort.SliceStable(resultado.Recomendaciones, func(i, j int) bool {
+		return resultado.Recomendaciones[i].Ganancia > resultado.Recomendaciones[j].Ganancia
+	})
+
+	if top <= 0 {
+		top = recomendacionesTopDefecto
+	}
+	if top > len(resultado.Recomendaciones) {
+		top = len(resultado.Recomendaciones)
+	}
+	resultado.Top = top
+
+	// El puntaje proyectado se recalcula con todas las recomendaciones aplicadas a la vez,
+	// porque con PONDERADA los subtotales redondeados no se suman de a una ganancia
+	for _, rec := range resultado.Recomendaciones[:top] {
+		puntajePorIndicador[rec.IDIndicador].Puntos = puntosSugeridos[rec.IDIndicador]
+	}
+	resultado.PuntajeProyectado = puntajeTotal(estrategia, puntajes)
+
+	return resultado, nil
+}
+
+// puntajeTotal suma los puntos obtenidos en los capítulos según la estrategia de puntaje
+func puntajeTotal(estrategia ScoringStrategy, puntajes []*domain.PuntajeIndicador) int {
+	total := 0
+	for _, c := range estrategia.CalcularCapitulos(puntajes) {
+		total += c.PuntosObtenidos
+	}
+	return total
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"coviar_backend/internal/domain"
+)
+
+// SeleccionarSegmento selecciona un segmento para la autoevaluación pendiente. Si ya tiene
+// respuestas de indicadores que el nuevo segmento no habilita, sin confirmar devuelve
+// CambioSegmentoSinConfirmar con lo que se eliminaría; con confirmar elimina esas
+// respuestas y sus evidencias junto con el cambio de segmento. El cambio se aplica solo si
+// la versión coincide con la actual.
+func (s *AutoevaluacionService) SeleccionarSegmento(ctx context.Context, idAutoevaluacion int, idSegmento int, confirmar bool, version *int) (*domain.CambioSegmento, error) {
+	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
+	}
+	if auto == nil {
+		return nil, domain.ErrNotFound
+	}
+	if auto.Estado != domain.EstadoPendiente {
+		return nil, domain.ErrAutoevaluacionNoPendiente
+	}
+
+	seg, err := s.segmentoRepo.FindByID(ctx, idSegmento)
+	if err != nil {
+		return nil, fmt.Errorf("error finding segmento: %w", err)
+	}
+
+	cambio, restantes, conEvidencia, err := s.previsualizarCambioSegmento(ctx, auto, seg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cambio.RespuestasFueraDeSegmento) > 0 && !confirmar {
+		return nil, &domain.CambioSegmentoSinConfirmar{Cambio: cambio}
+	}
+
+	// Las evidencias se apartan y solo se borran del disco si se confirma la transacción
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	papelera := newPapeleraEvidencias()
+	confirmada := false
+	defer func() {
+		if !confirmada {
+			tx.Rollback()
+			papelera.Restaurar()
+		}
+	}()
+
+	nuevaVersion, err := s.avanzarVersion(ctx, tx, idAutoevaluacion, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cambio.RespuestasFueraDeSegmento) > 0 {
+		ids := make([]int, 0, len(cambio.RespuestasFueraDeSegmento))
+		for _, r := range cambio.RespuestasFueraDeSegmento {
+			ids = append(ids, r.ID)
+			if !r.TieneEvidencia {
+				continue
+			}
+			evidencia, err := s.evidenciaRepo.FindByRespuesta(ctx, r.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error getting evidencia: %w", err)
+			}
+			if evidencia == nil {
+				continue
+			}
+			if err := papelera.Apartar(evidencia.Ubicacion); err != nil {
+				return nil, err
+			}
+			if err := s.evidenciaRepo.Delete(ctx, tx, evidencia.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := s.respuestaRepo.DeleteByIDs(ctx, tx, ids); err != nil {
+			return nil, err
+		}
+		if err := s.autoevaluacionRepo.UpdateEvidenciaStatus(ctx, tx, idAutoevaluacion, estadoEvidencia(restantes, conEvidencia)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.autoevaluacionRepo.UpdateSegmento(ctx, tx, idAutoevaluacion, idSegmento); err != nil {
+		return nil, fmt.Errorf("error selecting segmento: %w", err)
+	}
+	if err := s.autoevaluacionRepo.RegistrarActividad(ctx, tx, idAutoevaluacion); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	confirmada = true
+	papelera.Vaciar()
+
+	cambio.Aplicado = true
+	cambio.Version = nuevaVersion
+	return cambio, nil
+}
+
+// previsualizarCambioSegmento separa las respuestas que el segmento no habilita; devuelve
+// además cuántas respuestas quedan y cuántas de ellas tienen evidencia
+func (s *AutoevaluacionService) previsualizarCambioSegmento(ctx context.Context, auto *domain.Autoevaluacion, seg *domain.Segmento) (*domain.CambioSegmento, int, int, error) {
+	idsIndicador, err := s.indicadorRepo.FindBySegmento(ctx, seg.ID, auto.IDGuiaVersion)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("error getting indicadores del segmento: %w", err)
+	}
+	habilitados := make(map[int]bool, len(idsIndicador))
+	for _, id := range idsIndicador {
+		habilitados[id] = true
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, auto.ID)
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("error getting respuestas: %w", err)
+	}
+
+	cambio := &domain.CambioSegmento{
+		IDSegmentoAnterior:        auto.IDSegmento,
+		IDSegmento:                seg.ID,
+		Segmento:                  seg.Nombre,
+		RespuestasFueraDeSegmento: make([]*domain.RespuestaDetalle, 0),
+	}
+	restantes, conEvidencia := 0, 0
+	for _, r := range respuestas {
+		switch {
+		case !habilitados[r.IDIndicador]:
+			cambio.RespuestasFueraDeSegmento = append(cambio.RespuestasFueraDeSegmento, r)
+			if r.TieneEvidencia {
+				cambio.EvidenciasEliminadas++
+			}
+		case r.TieneEvidencia:
+			restantes++
+			conEvidencia++
+		default:
+			restantes++
+		}
+	}
+
+	return cambio, restantes, conEvidencia, nil
+}
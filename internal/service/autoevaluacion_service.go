@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"fmt"
-	"os"
+	"math"
+	"unicode/utf8"
 
 	"coviar_backend/internal/domain"
 	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
 )
 
 type AutoevaluacionService struct {
@@ -17,6 +19,12 @@ type AutoevaluacionService struct {
 	nivelRespuestaRepo repository.NivelRespuestaRepository
 	respuestaRepo      repository.RespuestaRepository
 	evidenciaRepo      repository.EvidenciaRepository
+	resultadoRepo      repository.ResultadoCapituloRepository
+	guiaVersionRepo    repository.GuiaVersionRepository
+	txManager          repository.TransactionManager
+	enmiendaRepo       repository.EnmiendaRepository
+	estructuraCache    *EstructuraCache
+	vigencia           PoliticaVigencia
 }
 
 func NewAutoevaluacionService(
@@ -27,6 +35,12 @@ func NewAutoevaluacionService(
 	nivelRespuestaRepo repository.NivelRespuestaRepository,
 	respuestaRepo repository.RespuestaRepository,
 	evidenciaRepo repository.EvidenciaRepository,
+	resultadoRepo repository.ResultadoCapituloRepository,
+	guiaVersionRepo repository.GuiaVersionRepository,
+	enmiendaRepo repository.EnmiendaRepository,
+	txManager repository.TransactionManager,
+	estructuraCache *EstructuraCache,
+	vigencia PoliticaVigencia,
 ) *AutoevaluacionService {
 	return &AutoevaluacionService{
 		autoevaluacionRepo: autoevaluacionRepo,
@@ -36,6 +50,12 @@ func NewAutoevaluacionService(
 		nivelRespuestaRepo: nivelRespuestaRepo,
 		respuestaRepo:      respuestaRepo,
 		evidenciaRepo:      evidenciaRepo,
+		resultadoRepo:      resultadoRepo,
+		guiaVersionRepo:    guiaVersionRepo,
+		enmiendaRepo:       enmiendaRepo,
+		txManager:          txManager,
+		estructuraCache:    estructuraCache,
+		vigencia:           vigencia,
 	}
 }
 
@@ -76,6 +96,7 @@ func (s *AutoevaluacionService) CreateAutoevaluacion(ctx context.Context, idBode
 			respuestasDTO[i] = domain.GuardarRespuestaRequest{
 				IDIndicador:      resp.IDIndicador,
 				IDNivelRespuesta: resp.IDNivelRespuesta,
+				Nota:             resp.Nota,
 			}
 		}
 
@@ -86,9 +107,15 @@ func (s *AutoevaluacionService) CreateAutoevaluacion(ctx context.Context, idBode
 		}, nil
 	}
 
-	// No existe autoevaluación pendiente, crear una nueva
+	// No existe autoevaluación pendiente, crear una nueva con la versión publicada de la guía
+	version, err := s.guiaVersionRepo.FindPublicada(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	auto := &domain.Autoevaluacion{
-		IDBodega: idBodega,
+		IDBodega:      idBodega,
+		IDGuiaVersion: version.ID,
 	}
 
 	id, err := s.autoevaluacionRepo.Create(ctx, nil, auto)
@@ -113,51 +140,42 @@ func (s *AutoevaluacionService) GetSegmentos(ctx context.Context) ([]*domain.Seg
 	return segmentos, nil
 }
 
-// SeleccionarSegmento selecciona un segmento para la autoevaluación
-func (s *AutoevaluacionService) SeleccionarSegmento(ctx context.Context, idAutoevaluacion int, idSegmento int) error {
-	// Verificar que la autoevaluación existe
+// GetEstructura obtiene la estructura del cuestionario con indicadores habilitados según el segmento
+func (s *AutoevaluacionService) GetEstructura(ctx context.Context, idAutoevaluacion int) (*domain.EstructuraAutoevaluacion, error) {
+	// Obtener autoevaluación
 	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
 	if err != nil {
-		return fmt.Errorf("error finding autoevaluacion: %w", err)
+		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
 	}
 
-	if auto == nil {
-		return domain.ErrNotFound
+	if auto == nil || auto.IDSegmento == nil {
+		return nil, fmt.Errorf("autoevaluacion not found or segmento not selected")
 	}
 
-	// Verificar que el segmento existe
-	seg, err := s.segmentoRepo.FindByID(ctx, idSegmento)
-	if err != nil {
-		return fmt.Errorf("error finding segmento: %w", err)
-	}
+	return s.obtenerEstructura(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
+}
 
-	if seg == nil {
-		return domain.ErrNotFound
+// obtenerEstructura devuelve la estructura del caché o la carga y la guarda
+func (s *AutoevaluacionService) obtenerEstructura(ctx context.Context, idSegmento, idGuiaVersion int) (*domain.EstructuraAutoevaluacion, error) {
+	estructura, generacion := s.estructuraCache.obtener(idSegmento, idGuiaVersion)
+	if estructura != nil {
+		return estructura, nil
 	}
 
-	// Actualizar la autoevaluación con el segmento
-	err = s.autoevaluacionRepo.UpdateSegmento(ctx, idAutoevaluacion, idSegmento)
+	estructura, err := s.cargarEstructura(ctx, idSegmento, idGuiaVersion)
 	if err != nil {
-		return fmt.Errorf("error selecting segmento: %w", err)
+		return nil, err
 	}
+	s.estructuraCache.guardar(idSegmento, idGuiaVersion, generacion, estructura)
 
-	return nil
+	return estructura, nil
 }
 
-// GetEstructura obtiene la estructura del cuestionario con indicadores habilitados según el segmento
-func (s *AutoevaluacionService) GetEstructura(ctx context.Context, idAutoevaluacion int) (*domain.EstructuraAutoevaluacion, error) {
-	// Obtener autoevaluación
-	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
-	if err != nil {
-		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
-	}
-
-	if auto == nil || auto.IDSegmento == nil {
-		return nil, fmt.Errorf("autoevaluacion not found or segmento not selected")
-	}
-
-	// Obtener indicadores habilitados para este segmento
-	habilitadosIds, err := s.indicadorRepo.FindBySegmento(ctx, *auto.IDSegmento)
+// cargarEstructura arma el cuestionario de la versión con una cantidad fija de consultas
+// (indicadores habilitados, capítulos, indicadores y niveles de respuesta)
+func (s *AutoevaluacionService) cargarEstructura(ctx context.Context, idSegmento, idGuiaVersion int) (*domain.EstructuraAutoevaluacion, error) {
+	// Obtener indicadores habilitados para este segmento en la versión de la guía de la autoevaluación
+	habilitadosIds, err := s.indicadorRepo.FindBySegmento(ctx, idSegmento, idGuiaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error getting enabled indicators: %w", err)
 	}
@@ -168,68 +186,75 @@ func (s *AutoevaluacionService) GetEstructura(ctx context.Context, idAutoevaluac
 		habilitadosMap[id] = true
 	}
 
-	// Obtener todos los capítulos
-	capitulos, err := s.capituloRepo.FindAll(ctx)
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, idGuiaVersion)
 	if err != nil {
 		return nil, fmt.Errorf("error getting capitulos: %w", err)
 	}
+	indicadores, err := s.indicadorRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting indicadores: %w", err)
+	}
+	niveles, err := s.nivelRespuestaRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting niveles_respuesta: %w", err)
+	}
 
-	estructura := &domain.EstructuraAutoevaluacion{
-		Capitulos: make([]*domain.CapituloEstructura, 0),
+	// Los niveles vienen ordenados por posición dentro de cada indicador
+	nivelesPorIndicador := make(map[int][]*domain.NivelRespuesta)
+	for _, n := range niveles {
+		nivelesPorIndicador[n.IDIndicador] = append(nivelesPorIndicador[n.IDIndicador], n)
 	}
 
+	estructura := &domain.EstructuraAutoevaluacion{
+		Capitulos: make([]*domain.CapituloEstructura, 0, len(capitulos)),
+	}
+	capitulosPorID := make(map[int]*domain.CapituloEstructura, len(capitulos))
 	for _, cap := range capitulos {
-		// Obtener indicadores del capítulo
-		indicadores, err := s.indicadorRepo.FindByCapitulo(ctx, cap.ID)
-		if err != nil {
-			return nil, fmt.Errorf("error getting indicadores: %w", err)
-		}
-
 		capEstructura := &domain.CapituloEstructura{
 			Capitulo:    cap,
 			Indicadores: make([]*domain.IndicadorConHabilitacion, 0),
 		}
+		capitulosPorID[cap.ID] = capEstructura
+		estructura.Capitulos = append(estructura.Capitulos, capEstructura)
+	}
 
-		for _, ind := range indicadores {
-			// Obtener niveles de respuesta
-			niveles, err := s.nivelRespuestaRepo.FindByIndicador(ctx, ind.ID)
-			if err != nil {
-				return nil, fmt.Errorf("error getting niveles_respuesta: %w", err)
-			}
-
-			habilitado := habilitadosMap[ind.ID]
-
-			indConHab := &domain.IndicadorConHabilitacion{
-				Indicador:        ind,
-				NivelesRespuesta: niveles,
-				Habilitado:       habilitado,
-			}
-
-			capEstructura.Indicadores = append(capEstructura.Indicadores, indConHab)
+	// Los indicadores vienen ordenados por capítulo y orden
+	for _, ind := range indicadores {
+		capEstructura, ok := capitulosPorID[ind.IDCapitulo]
+		if !ok {
+			continue
 		}
-
-		estructura.Capitulos = append(estructura.Capitulos, capEstructura)
+		capEstructura.Indicadores = append(capEstructura.Indicadores, &domain.IndicadorConHabilitacion{
+			Indicador:        ind,
+			NivelesRespuesta: nivelesPorIndicador[ind.ID],
+			Habilitado:       habilitadosMap[ind.ID],
+		})
 	}
 
 	return estructura, nil
 }
 
-// GuardarRespuestas guarda las respuestas de la autoevaluación y retorna las respuestas con sus IDs
-func (s *AutoevaluacionService) GuardarRespuestas(ctx context.Context, idAutoevaluacion int, respuestas []domain.GuardarRespuestaRequest) ([]*domain.Respuesta, error) {
+// GuardarRespuestas guarda las respuestas de la autoevaluación si la versión coincide con la
+// actual y retorna las respuestas con sus IDs y la nueva versión
+func (s *AutoevaluacionService) GuardarRespuestas(ctx context.Context, idAutoevaluacion int, version *int, respuestas []domain.GuardarRespuestaRequest) ([]*domain.Respuesta, int, error) {
 	// Verificar que la autoevaluación existe
 	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
 	if err != nil {
-		return nil, fmt.Errorf("error finding autoevaluacion: %w", err)
+		return nil, 0, fmt.Errorf("error finding autoevaluacion: %w", err)
 	}
 
 	if auto == nil {
-		return nil, domain.ErrNotFound
+		return nil, 0, domain.ErrNotFound
+	}
+
+	if err := s.validarRespuestas(ctx, auto, respuestas); err != nil {
+		return nil, 0, err
 	}
 
 	// Obtener respuestas existentes para detectar cambios de nivel
 	existentes, err := s.respuestaRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
 	if err != nil {
-		return nil, fmt.Errorf("error getting existing respuestas: %w", err)
+		return nil, 0, fmt.Errorf("error getting existing respuestas: %w", err)
 	}
 
 	// Mapa de id_indicador -> respuesta existente
@@ -238,16 +263,40 @@ func (s *AutoevaluacionService) GuardarRespuestas(ctx context.Context, idAutoeva
 		existentesMap[r.IDIndicador] = r
 	}
 
-	// Guardar nuevas respuestas o actualizar existentes
+	// Todo el lote se guarda en una transacción; las evidencias de respuestas que
+	// cambian de nivel se apartan y solo se borran del disco si se confirma
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	papelera := newPapeleraEvidencias()
+	confirmada := false
+	defer func() {
+		if !confirmada {
+			tx.Rollback()
+			papelera.Restaurar()
+		}
+	}()
+
+	nuevaVersion, err := s.avanzarVersion(ctx, tx, idAutoevaluacion, version)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	resultado := make([]*domain.Respuesta, 0, len(respuestas))
 	for _, respReq := range respuestas {
 		// Si la respuesta ya existía con un nivel diferente, eliminar su evidencia
-		if existente, ok := existentesMap[respReq.IDIndicador]; ok {
-			if existente.IDNivelRespuesta != respReq.IDNivelRespuesta {
-				evidencia, err := s.evidenciaRepo.FindByRespuesta(ctx, existente.ID)
-				if err == nil && evidencia != nil {
-					os.Remove(evidencia.Ubicacion)
-					s.evidenciaRepo.Delete(ctx, nil, evidencia.ID)
+		if existente, ok := existentesMap[respReq.IDIndicador]; ok && existente.IDNivelRespuesta != respReq.IDNivelRespuesta {
+			evidencia, err := s.evidenciaRepo.FindByRespuesta(ctx, existente.ID)
+			if err != nil {
+				return nil, 0, fmt.Errorf("error getting evidencia: %w", err)
+			}
+			if evidencia != nil {
+				if err := papelera.Apartar(evidencia.Ubicacion); err != nil {
+					return nil, 0, err
+				}
+				if err := s.evidenciaRepo.Delete(ctx, tx, evidencia.ID); err != nil {
+					return nil, 0, err
 				}
 			}
 		}
@@ -256,17 +305,120 @@ func (s *AutoevaluacionService) GuardarRespuestas(ctx context.Context, idAutoeva
 			IDNivelRespuesta: respReq.IDNivelRespuesta,
 			IDIndicador:      respReq.IDIndicador,
 			IDAutoevaluacion: idAutoevaluacion,
+			Nota:             notaRespuesta(respReq.Nota, existentesMap[respReq.IDIndicador]),
 		}
 
-		id, err := s.respuestaRepo.Upsert(ctx, nil, respuesta)
+		id, err := s.respuestaRepo.Upsert(ctx, tx, respuesta)
 		if err != nil {
-			return nil, fmt.Errorf("error guarding respuesta: %w", err)
+			return nil, 0, fmt.Errorf("error guarding respuesta: %w", err)
 		}
 		respuesta.ID = id
 		resultado = append(resultado, respuesta)
 	}
 
-	return resultado, nil
+	if err := s.autoevaluacionRepo.RegistrarActividad(ctx, tx, idAutoevaluacion); err != nil {
+		return nil, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	confirmada = true
+	papelera.Vaciar()
+
+	return resultado, nuevaVersion, nil
+}
+
+// validarRespuestas verifica que la autoevaluación siga pendiente y que cada respuesta
+// corresponda a un indicador habilitado para su segmento, con un nivel de ese indicador y
+// una nota que no exceda LongitudMaximaNota. Sanea las notas en el lugar.
+// Valida contra la estructura del cuestionario en caché, sin consultas por respuesta.
+// Reporta todas las respuestas inválidas juntas.
+func (s *AutoevaluacionService) validarRespuestas(ctx context.Context, auto *domain.Autoevaluacion, respuestas []domain.GuardarRespuestaRequest) error {
+	if auto.Estado != domain.EstadoPendiente {
+		return domain.ErrAutoevaluacionNoPendiente
+	}
+	if auto.IDSegmento == nil {
+		return validator.ValidationErrors{{Field: "id_segmento", Message: "la autoevaluación no tiene segmento seleccionado"}}
+	}
+
+	estructura, err := s.obtenerEstructura(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
+	if err != nil {
+		return err
+	}
+	habilitados := make(map[int]bool)
+	niveles := make(map[int]*domain.NivelRespuesta)
+	for _, cap := range estructura.Capitulos {
+		for _, ind := range cap.Indicadores {
+			if !ind.Habilitado {
+				continue
+			}
+			habilitados[ind.Indicador.ID] = true
+			for _, n := range ind.NivelesRespuesta {
+				niveles[n.ID] = n
+			}
+		}
+	}
+
+	var errs domain.ErroresRespuestas
+	vistos := make(map[int]bool, len(respuestas))
+	for i, respReq := range respuestas {
+		rechazar := func(mensaje string) {
+			errs = append(errs, domain.ErrorRespuesta{
+				Indice:           i,
+				IDIndicador:      respReq.IDIndicador,
+				IDNivelRespuesta: respReq.IDNivelRespuesta,
+				Mensaje:          mensaje,
+			})
+		}
+
+		if !habilitados[respReq.IDIndicador] {
+			rechazar("el indicador no está habilitado para el segmento de la autoevaluación")
+			continue
+		}
+		if vistos[respReq.IDIndicador] {
+			rechazar("el indicador está repetido")
+			continue
+		}
+		vistos[respReq.IDIndicador] = true
+
+		if respReq.Nota != nil {
+			nota := validator.LimpiarTextoLibre(*respReq.Nota)
+			respuestas[i].Nota = &nota
+			if utf8.RuneCountInString(nota) > domain.LongitudMaximaNota {
+				rechazar(fmt.Sprintf("la nota no puede superar los %d caracteres", domain.LongitudMaximaNota))
+				continue
+			}
+		}
+
+		nivel, ok := niveles[respReq.IDNivelRespuesta]
+		switch {
+		case !ok:
+			rechazar("el nivel de respuesta no existe")
+		case nivel.IDIndicador != respReq.IDIndicador:
+			rechazar("el nivel de respuesta no corresponde al indicador")
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// notaRespuesta resuelve la nota a guardar: si no se envió se conserva la de la respuesta
+// existente, y una nota vacía la borra
+func notaRespuesta(nota *string, existente *domain.Respuesta) *string {
+	if nota == nil {
+		if existente != nil {
+			return existente.Nota
+		}
+		return nil
+	}
+	if *nota == "" {
+		return nil
+	}
+	return nota
 }
 
 // CompletarAutoevaluacion marca la autoevaluación como completada
@@ -301,127 +453,305 @@ func (s *AutoevaluacionService) GuardarRespuestas(ctx context.Context, idAutoeva
 	return nil
 }*/
 
-// CompletarAutoevaluacion marca la autoevaluación como completada
-func (s *AutoevaluacionService) CompletarAutoevaluacion(ctx context.Context, idAutoevaluacion int) error {
+// CompletarAutoevaluacion marca la autoevaluación como completada si la versión coincide con
+// la actual; devuelve la nueva versión
+func (s *AutoevaluacionService) CompletarAutoevaluacion(ctx context.Context, idAutoevaluacion int, version *int) (int, error) {
 	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
 	if err != nil {
-		return fmt.Errorf("error finding autoevaluacion: %w", err)
+		return 0, fmt.Errorf("error finding autoevaluacion: %w", err)
 	}
 
 	if auto == nil {
-		return domain.ErrNotFound
+		return 0, domain.ErrNotFound
+	}
+
+	// Solo se completa una autoevaluación pendiente; una completada o cancelada no se
+	// vuelve a puntuar
+	if auto.Estado != domain.EstadoPendiente {
+		return 0, domain.ErrAutoevaluacionNoPendiente
 	}
 
 	// Verificar que tenga segmento seleccionado
 	if auto.IDSegmento == nil {
-		return fmt.Errorf("autoevaluacion must have segmento selected")
+		return 0, fmt.Errorf("autoevaluacion must have segmento selected")
 	}
 
 	// Obtener respuestas para validar que todas las preguntas fueron respondidas
 	respuestas, err := s.respuestaRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
 	if err != nil {
-		return fmt.Errorf("error getting respuestas: %w", err)
+		return 0, fmt.Errorf("error getting respuestas: %w", err)
 	}
 
 	// Validación básica: debe haber al menos una respuesta
 	if len(respuestas) == 0 {
-		return fmt.Errorf("autoevaluacion must have at least one respuesta")
+		return 0, fmt.Errorf("autoevaluacion must have at least one respuesta")
 	}
 
 	// === VALIDACIÓN ESTRICTA DE COMPLETITUD ===
 	// Obtener los indicadores requeridos para este segmento
-	requiredIndicators, err := s.indicadorRepo.FindBySegmento(ctx, *auto.IDSegmento)
+	requiredIndicators, err := s.indicadorRepo.FindBySegmento(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
 	if err != nil {
-		return fmt.Errorf("error getting required indicators: %w", err)
+		return 0, fmt.Errorf("error getting required indicators: %w", err)
 	}
 
-	// Verificar que coincida la cantidad
-	if len(respuestas) != len(requiredIndicators) {
-		return fmt.Errorf("autoevaluacion incomplete: expected %d answers, got %d", len(requiredIndicators), len(respuestas))
+	// Verificar que coincida la cantidad; solo cuentan las respuestas de indicadores del
+	// segmento (las demás se eliminan al cambiarlo, pero pueden existir de antes)
+	requeridos := make(map[int]bool, len(requiredIndicators))
+	for _, id := range requiredIndicators {
+		requeridos[id] = true
+	}
+	respondidos := 0
+	for _, r := range respuestas {
+		if requeridos[r.IDIndicador] {
+			respondidos++
+		}
+	}
+	if respondidos != len(requiredIndicators) {
+		return 0, fmt.Errorf("autoevaluacion incomplete: expected %d answers, got %d", len(requiredIndicators), respondidos)
 	}
 	// ==========================================
 
-	// Calcular puntaje total
-	puntajeTotal, err := s.respuestaRepo.CalculateTotalScore(ctx, idAutoevaluacion)
+	tx, err := s.txManager.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("error calculating total score: %w", err)
+		return 0, fmt.Errorf("error iniciando transacción: %w", err)
 	}
+	defer tx.Rollback()
 
-	// Obtener niveles de sostenibilidad para el segmento
-	niveles, err := s.segmentoRepo.FindNivelesSostenibilidadBySegmento(ctx, *auto.IDSegmento)
+	nuevaVersion, err := s.avanzarVersion(ctx, tx, idAutoevaluacion, version)
 	if err != nil {
-		return fmt.Errorf("error getting niveles sostenibilidad: %w", err)
+		return 0, err
 	}
 
-	// Si no hay niveles configurados, completar sin asignar nivel
-	if len(niveles) == 0 {
-		// Marcar como completada solo con puntaje (sin nivel de sostenibilidad)
-		err = s.autoevaluacionRepo.CompleteWithScore(ctx, idAutoevaluacion, puntajeTotal, 0)
-		if err != nil {
-			return fmt.Errorf("error completing autoevaluacion: %w", err)
-		}
-		return nil
+	// Calcular y guardar el desglose por capítulo antes de cerrar la autoevaluación;
+	// el puntaje total es la suma de los capítulos según la estrategia de la guía
+	capitulos, err := s.guardarResultadosCapitulo(ctx, tx, idAutoevaluacion, *auto.IDSegmento, auto.IDGuiaVersion)
+	if err != nil {
+		return 0, err
 	}
-
-	// Determinar el nivel de sostenibilidad según el puntaje
-	var nivelAsignado *domain.NivelSostenibilidad
-	for _, nivel := range niveles {
-		if puntajeTotal >= nivel.MinPuntaje && puntajeTotal <= nivel.MaxPuntaje {
-			nivelAsignado = nivel
-			break
-		}
+	puntajeTotal := 0
+	for _, c := range capitulos {
+		puntajeTotal += c.PuntosObtenidos
 	}
 
-	// Si no encontró nivel exacto, asignar el más cercano
-	if nivelAsignado == nil {
-		// Buscar el nivel más alto si el puntaje supera todos los rangos
-		for _, nivel := range niveles {
-			if puntajeTotal >= nivel.MinPuntaje {
-				nivelAsignado = nivel
-			}
-		}
-		// Si aún no hay nivel, usar el primero (puntaje menor al mínimo)
-		if nivelAsignado == nil && len(niveles) > 0 {
-			nivelAsignado = niveles[0]
-		}
+	// Obtener niveles de sostenibilidad para el segmento
+	niveles, err := s.segmentoRepo.FindNivelesSostenibilidadBySegmento(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
+	if err != nil {
+		return 0, fmt.Errorf("error getting niveles sostenibilidad: %w", err)
 	}
 
+	// Determinar el nivel según el puntaje y los requisitos por capítulo
+	// (sin niveles configurados se completa sin asignar nivel)
 	var idNivelSostenibilidad int
+	nivelAsignado := asignarNivel(puntajeTotal, capitulos, niveles)
 	if nivelAsignado != nil {
 		idNivelSostenibilidad = nivelAsignado.ID
 	}
 
 	// Marcar como completada con puntaje y nivel de sostenibilidad
-	err = s.autoevaluacionRepo.CompleteWithScore(ctx, idAutoevaluacion, puntajeTotal, idNivelSostenibilidad)
+	err = s.autoevaluacionRepo.CompleteWithScore(ctx, tx, idAutoevaluacion, puntajeTotal, idNivelSostenibilidad)
 	if err != nil {
-		return fmt.Errorf("error completing autoevaluacion: %w", err)
+		return 0, fmt.Errorf("error completing autoevaluacion: %w", err)
 	}
 
-	return nil
+	// Si había sido reabierta, la enmienda registra el nuevo resultado
+	var idNivelNuevo *int
+	if nivelAsignado != nil {
+		idNivelNuevo = &nivelAsignado.ID
+	}
+	if err := s.enmiendaRepo.RegistrarRecompletado(ctx, tx, idAutoevaluacion, puntajeTotal, idNivelNuevo); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nuevaVersion, nil
 }
 
-// CancelarAutoevaluacion marca la autoevaluación como cancelada
-func (s *AutoevaluacionService) CancelarAutoevaluacion(ctx context.Context, idAutoevaluacion int) error {
+// CancelarAutoevaluacion marca la autoevaluación como cancelada si la versión coincide con
+// la actual; devuelve la nueva versión
+func (s *AutoevaluacionService) CancelarAutoevaluacion(ctx context.Context, idAutoevaluacion int, version *int) (int, error) {
 	// Verificar que la autoevaluación existe
 	auto, err := s.autoevaluacionRepo.FindByID(ctx, idAutoevaluacion)
 	if err != nil {
-		return fmt.Errorf("error finding autoevaluacion: %w", err)
+		return 0, fmt.Errorf("error finding autoevaluacion: %w", err)
 	}
 
 	if auto == nil {
-		return domain.ErrNotFound
+		return 0, domain.ErrNotFound
 	}
 
 	// Verificar que esté en estado PENDIENTE
 	if auto.Estado != domain.EstadoPendiente {
-		return fmt.Errorf("solo se pueden cancelar autoevaluaciones en estado PENDIENTE")
+		return 0, fmt.Errorf("solo se pueden cancelar autoevaluaciones en estado PENDIENTE")
+	}
+
+	return s.cancelar(ctx, idAutoevaluacion, version)
+}
+
+// cancelar marca la autoevaluación como cancelada y avanza su versión en una transacción
+func (s *AutoevaluacionService) cancelar(ctx context.Context, idAutoevaluacion int, version *int) (int, error) {
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	nuevaVersion, err := s.avanzarVersion(ctx, tx, idAutoevaluacion, version)
+	if err != nil {
+		return 0, err
 	}
 
 	// Marcar como cancelada
-	err = s.autoevaluacionRepo.Cancel(ctx, idAutoevaluacion)
+	if err := s.autoevaluacionRepo.Cancel(ctx, tx, idAutoevaluacion); err != nil {
+		return 0, fmt.Errorf("error canceling autoevaluacion: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nuevaVersion, nil
+}
+
+const (
+	historialPorPaginaDefecto = 20
+	historialPorPaginaMaximo  = 100
+)
+
+// GetHistorial devuelve las autoevaluaciones de una bodega según el filtro, paginadas
+func (s *AutoevaluacionService) GetHistorial(ctx context.Context, filtro domain.FiltroAutoevaluaciones) (*domain.HistorialAutoevaluacionesResponse, error) {
+	var errs validator.ValidationErrors
+	if filtro.Estado != nil {
+		switch *filtro.Estado {
+		case domain.EstadoPendiente, domain.EstadoCompletada, domain.EstadoCancelada:
+		default:
+			errs = append(errs, validator.ValidationError{Field: "estado", Message: "estado inválido"})
+		}
+	}
+	if filtro.Desde != nil && filtro.Hasta != nil && !filtro.Desde.Before(*filtro.Hasta) {
+		errs = append(errs, validator.ValidationError{Field: "desde", Message: "debe ser anterior a hasta"})
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if filtro.Pagina < 1 {
+		filtro.Pagina = 1
+	}
+	if filtro.PorPagina < 1 {
+		filtro.PorPagina = historialPorPaginaDefecto
+	}
+	if filtro.PorPagina > historialPorPaginaMaximo {
+		filtro.PorPagina = historialPorPaginaMaximo
+	}
+
+	resumenes, total, err := s.autoevaluacionRepo.FindByBodega(ctx, filtro)
 	if err != nil {
-		return fmt.Errorf("error canceling autoevaluacion: %w", err)
+		return nil, fmt.Errorf("error getting historial: %w", err)
 	}
 
-	return nil
+	return &domain.HistorialAutoevaluacionesResponse{
+		Autoevaluaciones: resumenes,
+		Total:            total,
+		Pagina:           filtro.Pagina,
+		PorPagina:        filtro.PorPagina,
+	}, nil
+}
+
+// GetDetalle devuelve la autoevaluación con todas sus respuestas
+func (s *AutoevaluacionService) GetDetalle(ctx context.Context, idAutoevaluacion int) (*domain.AutoevaluacionDetalle, error) {
+	resumen, err := s.autoevaluacionRepo.FindResumenByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+
+	enmiendas, err := s.enmiendaRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.AutoevaluacionDetalle{
+		AutoevaluacionResumen: resumen,
+		Respuestas:            respuestas,
+		Enmiendas:             enmiendas,
+	}, nil
+}
+
+// calcularResultadosCapitulo calcula el desglose por capítulo con la estrategia de puntaje
+// de la versión de la guía de la autoevaluación
+func (s *AutoevaluacionService) calcularResultadosCapitulo(ctx context.Context, idAutoevaluacion, idSegmento, idGuiaVersion int) ([]*domain.ResultadoCapitulo, error) {
+	version, err := s.guiaVersionRepo.FindByID(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error finding guia_version: %w", err)
+	}
+
+	puntajes, err := s.resultadoRepo.FindPuntajesIndicador(ctx, idAutoevaluacion, idSegmento, idGuiaVersion)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating resultados por capitulo: %w", err)
+	}
+
+	return estrategiaDe(version).CalcularCapitulos(puntajes), nil
+}
+
+func (s *AutoevaluacionService) guardarResultadosCapitulo(ctx context.Context, tx repository.Transaction, idAutoevaluacion, idSegmento, idGuiaVersion int) ([]*domain.ResultadoCapitulo, error) {
+	resultados, err := s.calcularResultadosCapitulo(ctx, idAutoevaluacion, idSegmento, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resultadoRepo.ReplaceByAutoevaluacion(ctx, tx, idAutoevaluacion, resultados); err != nil {
+		return nil, fmt.Errorf("error saving resultados por capitulo: %w", err)
+	}
+	return resultados, nil
+}
+
+// GetResultados devuelve el puntaje total y por capítulo. Para autoevaluaciones completadas
+// se usan los resultados guardados al completarlas; para las pendientes, y para las
+// completadas antes de que se guardaran, se calculan sobre las respuestas sin persistirlos.
+func (s *AutoevaluacionService) GetResultados(ctx context.Context, idAutoevaluacion int) (*domain.ResultadosAutoevaluacion, error) {
+	resumen, err := s.autoevaluacionRepo.FindResumenByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+	if resumen.IDSegmento == nil {
+		return nil, validator.ValidationErrors{{Field: "id_segmento", Message: "la autoevaluación no tiene segmento seleccionado"}}
+	}
+
+	var capitulos []*domain.ResultadoCapitulo
+	if resumen.Estado == domain.EstadoCompletada {
+		capitulos, err = s.resultadoRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
+		if err != nil {
+			return nil, fmt.Errorf("error getting resultados por capitulo: %w", err)
+		}
+	}
+	if len(capitulos) == 0 {
+		capitulos, err = s.calcularResultadosCapitulo(ctx, idAutoevaluacion, *resumen.IDSegmento, resumen.IDGuiaVersion)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resultados := &domain.ResultadosAutoevaluacion{
+		Autoevaluacion: resumen,
+		Capitulos:      capitulos,
+	}
+	for _, c := range capitulos {
+		resultados.PuntajeTotal += c.PuntosObtenidos
+		resultados.PuntajeMaximo += c.PuntosMaximos
+	}
+	resultados.Porcentaje = porcentaje(resultados.PuntajeTotal, resultados.PuntajeMaximo)
+
+	return resultados, nil
+}
+
+// porcentaje devuelve obtenidos/maximos como porcentaje redondeado a dos decimales
+func porcentaje(obtenidos, maximos int) float64 {
+	if maximos <= 0 {
+		return 0
+	}
+	return math.Round(float64(obtenidos)*10000/float64(maximos)) / 100
 }
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"coviar_backend/internal/domain"
+)
+
+// NotificarInactividad avisa a la bodega que su autoevaluación pendiente se cancelará
+// en la fecha indicada si no registra actividad
+type NotificarInactividad func(ctx context.Context, auto *domain.AutoevaluacionInactiva, cancelacion time.Time) error
+
+// VencerInactivas avisa a las bodegas cuyas autoevaluaciones pendientes llevan diasAviso
+// días sin actividad y cancela las que llevan diasCancelacion. Solo se cancela lo que ya
+// fue avisado hace al menos diasCancelacion-diasAviso días, para que la bodega siempre
+// tenga ese plazo después del aviso. La cancelación vuelve a verificar esas condiciones al
+// escribir, así que se omite la autoevaluación si la bodega la retomó o la completó después
+// de leerla. Un error en una autoevaluación no detiene el resto.
+func (s *AutoevaluacionService) VencerInactivas(ctx context.Context, ahora time.Time, diasAviso, diasCancelacion int, notificar NotificarInactividad) (*domain.ResultadoVencimiento, error) {
+	if diasAviso <= 0 || diasCancelacion <= diasAviso {
+		return nil, fmt.Errorf("umbrales de inactividad inválidos: aviso %d días, cancelación %d días", diasAviso, diasCancelacion)
+	}
+	plazo := diasCancelacion - diasAviso
+
+	inactivas, err := s.autoevaluacionRepo.FindPendientesInactivas(ctx, ahora.AddDate(0, 0, -diasAviso))
+	if err != nil {
+		return nil, err
+	}
+
+	limiteCancelacion := ahora.AddDate(0, 0, -diasCancelacion)
+	limiteAviso := ahora.AddDate(0, 0, -plazo)
+	resultado := &domain.ResultadoVencimiento{}
+	for _, auto := range inactivas {
+		switch {
+		case auto.AvisoInactividad == nil:
+			if auto.Email != "" && notificar != nil {
+				if err := notificar(ctx, auto, ahora.AddDate(0, 0, plazo)); err != nil {
+					log.Printf("⚠️  No se pudo avisar la inactividad de la autoevaluación %d: %v", auto.ID, err)
+					continue
+				}
+			}
+			if err := s.autoevaluacionRepo.MarcarAvisoInactividad(ctx, auto.ID); err != nil {
+				log.Printf("⚠️  No se pudo registrar el aviso de la autoevaluación %d: %v", auto.ID, err)
+				continue
+			}
+			resultado.Avisadas++
+
+		case auto.UltimaActividad.Before(limiteCancelacion) && !auto.AvisoInactividad.After(limiteAviso):
+			cancelada, err := s.autoevaluacionRepo.CancelarInactiva(ctx, auto.ID, limiteCancelacion, limiteAviso)
+			if err != nil {
+				log.Printf("⚠️  No se pudo cancelar la autoevaluación inactiva %d: %v", auto.ID, err)
+				continue
+			}
+			if cancelada {
+				resultado.Canceladas++
+			}
+		}
+	}
+
+	return resultado, nil
+}
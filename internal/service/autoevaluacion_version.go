@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+// avanzarVersion incrementa la versión de la autoevaluación dentro de la transacción,
+// verificando antes que coincida con la que envió el cliente (nil omite la verificación).
+// Si no coincide devuelve AutoevaluacionDesactualizada con el estado actual.
+func (s *AutoevaluacionService) avanzarVersion(ctx context.Context, tx repository.Transaction, idAutoevaluacion int, version *int) (int, error) {
+	nueva, err := s.autoevaluacionRepo.AvanzarVersion(ctx, tx, idAutoevaluacion, version)
+	if !errors.Is(err, domain.ErrVersionDesactualizada) {
+		return nueva, err
+	}
+
+	actual, errDetalle := s.GetDetalle(ctx, idAutoevaluacion)
+	if errDetalle != nil {
+		return 0, errDetalle
+	}
+	return 0, &domain.AutoevaluacionDesactualizada{Actual: actual}
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/validator"
+)
+
+// PoliticaVigencia define cuánto tiempo vale el nivel obtenido en una autoevaluación
+// completada y cuándo se recuerda a la bodega que debe reevaluarse
+type PoliticaVigencia struct {
+	MesesPorDefecto  int   // vigencia de los niveles que no definen la suya
+	DiasRecordatorio []int // días antes del vencimiento en que se envía un recordatorio
+}
+
+// diasPorVencer es la anticipación del primer recordatorio: desde ahí la bodega figura
+// como POR_VENCER
+func (p PoliticaVigencia) diasPorVencer() int {
+	maximo := 0
+	for _, d := range p.DiasRecordatorio {
+		maximo = max(maximo, d)
+	}
+	return maximo
+}
+
+// recordatorioPendiente devuelve el recordatorio que corresponde enviar: el de menor
+// anticipación ya alcanzada, si es posterior al último enviado. Así, si el proceso no
+// corrió a tiempo, se envía solo el más cercano en lugar de todos los atrasados.
+func (p PoliticaVigencia) recordatorioPendiente(diasRestantes int, ultimo *int) (int, bool) {
+	elegido := -1
+	for _, d := range p.DiasRecordatorio {
+		if diasRestantes <= d && (elegido < 0 || d < elegido) {
+			elegido = d
+		}
+	}
+	if elegido < 0 || (ultimo != nil && *ultimo <= elegido) {
+		return 0, false
+	}
+	return elegido, true
+}
+
+// NotificarVigencia recuerda a la bodega que su nivel vence y debe reevaluarse
+type NotificarVigencia func(ctx context.Context, vigencia *domain.VigenciaBodega, email string) error
+
+// GetVigenciaBodega calcula la vigencia del nivel de la última autoevaluación completada
+// de la bodega
+func (s *AutoevaluacionService) GetVigenciaBodega(ctx context.Context, idBodega int, ahora time.Time) (*domain.VigenciaBodega, error) {
+	ultima, err := s.autoevaluacionRepo.FindUltimaCompletadaByBodega(ctx, idBodega)
+	if err != nil {
+		return nil, err
+	}
+	return s.calcularVigencia(ultima, ahora), nil
+}
+
+// ListarReevaluaciones devuelve las bodegas en los estados de vigencia pedidos (por
+// defecto POR_VENCER y VENCIDA), de la que vence antes a la que vence después
+func (s *AutoevaluacionService) ListarReevaluaciones(ctx context.Context, ahora time.Time, estados []domain.EstadoVigencia) ([]*domain.VigenciaBodega, error) {
+	if len(estados) == 0 {
+		estados = []domain.EstadoVigencia{domain.VigenciaPorVencer, domain.VigenciaVencida}
+	}
+	incluir := make(map[domain.EstadoVigencia]bool, len(estados))
+	for _, e := range estados {
+		switch e {
+		case domain.VigenciaVigente, domain.VigenciaPorVencer, domain.VigenciaVencida, domain.VigenciaSinEvaluacion:
+			incluir[e] = true
+		default:
+			return nil, validator.ValidationErrors{{Field: "estado", Message: fmt.Sprintf("estado de vigencia %q inválido", e)}}
+		}
+	}
+
+	ultimas, err := s.autoevaluacionRepo.FindUltimasCompletadas(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	vigencias := make([]*domain.VigenciaBodega, 0)
+	for _, u := range ultimas {
+		if v := s.calcularVigencia(u, ahora); incluir[v.Estado] {
+			vigencias = append(vigencias, v)
+		}
+	}
+
+	// Las bodegas sin evaluación van al final, por nombre
+	sort.SliceStable(vigencias, func(i, j int) bool {
+		a, b := vigencias[i].FechaVencimiento, vigencias[j].FechaVencimiento
+		switch {
+		case a == nil && b == nil:
+			return strings.ToLower(vigencias[i].Bodega) < strings.ToLower(vigencias[j].Bodega)
+		case a == nil || b == nil:
+			return b == nil
+		default:
+			return a.Before(*b)
+		}
+	})
+
+	return vigencias, nil
+}
+
+// RecordarVigencias envía los recordatorios de reevaluación que correspondan según
+// DiasRecordatorio. No se recuerda a las bodegas que ya iniciaron una nueva
+// autoevaluación. Un error con una bodega no detiene el resto.
+func (s *AutoevaluacionService) RecordarVigencias(ctx context.Context, ahora time.Time, notificar NotificarVigencia) (int, error) {
+	ultimas, err := s.autoevaluacionRepo.FindUltimasCompletadas(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	enviados := 0
+	for _, u := range ultimas {
+		if u.IDAutoevaluacion == nil || u.TienePendiente || u.Email == "" {
+			continue
+		}
+		vigencia := s.calcularVigencia(u, ahora)
+		if vigencia.DiasRestantes == nil {
+			continue
+		}
+		diasAntes, ok := s.vigencia.recordatorioPendiente(*vigencia.DiasRestantes, u.RecordatorioVigencia)
+		if !ok {
+			continue
+		}
+
+		if err := notificar(ctx, vigencia, u.Email); err != nil {
+			log.Printf("⚠️  No se pudo enviar el recordatorio de vigencia a la bodega %d: %v", u.IDBodega, err)
+			continue
+		}
+		if err := s.autoevaluacionRepo.MarcarRecordatorioVigencia(ctx, *u.IDAutoevaluacion, diasAntes); err != nil {
+			log.Printf("⚠️  No se pudo registrar el recordatorio de la autoevaluación %d: %v", *u.IDAutoevaluacion, err)
+			continue
+		}
+		enviados++
+	}
+
+	return enviados, nil
+}
+
+// calcularVigencia arma el estado de vigencia a partir de la última autoevaluación
+// completada. Los días restantes se redondean hacia abajo: 0 significa que vence hoy.
+func (s *AutoevaluacionService) calcularVigencia(u *domain.UltimaEvaluacionBodega, ahora time.Time) *domain.VigenciaBodega {
+	v := &domain.VigenciaBodega{
+		IDBodega:       u.IDBodega,
+		Bodega:         u.Bodega,
+		Estado:         domain.VigenciaSinEvaluacion,
+		TienePendiente: u.TienePendiente,
+	}
+	if u.IDAutoevaluacion == nil || u.FechaFin == nil {
+		return v
+	}
+
+	v.IDAutoevaluacion = u.IDAutoevaluacion
+	v.FechaFin = u.FechaFin
+	v.IDNivelSostenibilidad = u.IDNivelSostenibilidad
+	v.NivelSostenibilidad = u.NivelSostenibilidad
+	v.MesesVigencia = s.vigencia.MesesPorDefecto
+	if u.MesesVigencia != nil {
+		v.MesesVigencia = *u.MesesVigencia
+	}
+
+	vencimiento := u.FechaFin.AddDate(0, v.MesesVigencia, 0)
+	dias := int(math.Floor(vencimiento.Sub(ahora).Hours() / 24))
+	v.FechaVencimiento = &vencimiento
+	v.DiasRestantes = &dias
+
+	switch {
+	case !ahora.Before(vencimiento):
+		v.Estado = domain.VigenciaVencida
+	case dias <= s.vigencia.diasPorVencer():
+		v.Estado = domain.VigenciaPorVencer
+	default:
+		v.Estado = domain.VigenciaVigente
+	}
+	return v
+}
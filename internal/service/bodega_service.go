@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"coviar_backend/internal/domain"
 	"coviar_backend/internal/repository"
@@ -9,15 +10,26 @@ import (
 )
 
 type BodegaService struct {
-	bodegaRepo repository.BodegaRepository
+	bodegaRepo            repository.BodegaRepository
+	ubicacionService      *UbicacionService
+	autoevaluacionService *AutoevaluacionService
 }
 
-func NewBodegaService(bodegaRepo repository.BodegaRepository) *BodegaService {
-	return &BodegaService{bodegaRepo: bodegaRepo}
+func NewBodegaService(bodegaRepo repository.BodegaRepository, ubicacionService *UbicacionService, autoevaluacionService *AutoevaluacionService) *BodegaService {
+	return &BodegaService{bodegaRepo: bodegaRepo, ubicacionService: ubicacionService, autoevaluacionService: autoevaluacionService}
 }
 
 func (s *BodegaService) GetByID(ctx context.Context, id int) (*domain.Bodega, error) {
-	return s.bodegaRepo.FindByID(ctx, id)
+	bodega, err := s.bodegaRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.ubicacionService.CompletarDireccion(ctx, bodega)
+
+	if bodega.Vigencia, err = s.autoevaluacionService.GetVigenciaBodega(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+	return bodega, nil
 }
 
 func (s *BodegaService) Update(ctx context.Context, id int, dto *domain.BodegaUpdateDTO) error {
@@ -12,14 +12,16 @@ import (
 )
 
 type CuentaService struct {
-	cuentaRepo repository.CuentaRepository
-	bodegaRepo repository.BodegaRepository
+	cuentaRepo       repository.CuentaRepository
+	bodegaRepo       repository.BodegaRepository
+	ubicacionService *UbicacionService
 }
 
-func NewCuentaService(cuentaRepo repository.CuentaRepository, bodegaRepo repository.BodegaRepository) *CuentaService {
+func NewCuentaService(cuentaRepo repository.CuentaRepository, bodegaRepo repository.BodegaRepository, ubicacionService *UbicacionService) *CuentaService {
 	return &CuentaService{
-		cuentaRepo: cuentaRepo,
-		bodegaRepo: bodegaRepo,
+		cuentaRepo:       cuentaRepo,
+		bodegaRepo:       bodegaRepo,
+		ubicacionService: ubicacionService,
 	}
 }
 
@@ -65,6 +67,7 @@ func (s *CuentaService) Login(ctx context.Context, req *domain.CuentaRequest) (*
 	if cuenta.IDBodega != nil {
 		bodega, err := s.bodegaRepo.FindByID(ctx, *cuenta.IDBodega)
 		if err == nil && bodega != nil {
+			s.ubicacionService.CompletarDireccion(ctx, bodega)
 			result.Bodega = bodega
 		}
 	}
@@ -88,6 +91,7 @@ func (s *CuentaService) GetByIDWithBodega(ctx context.Context, id int) (*CuentaC
 	if cuenta.IDBodega != nil {
 		bodega, err := s.bodegaRepo.FindByID(ctx, *cuenta.IDBodega)
 		if err == nil && bodega != nil {
+			s.ubicacionService.CompletarDireccion(ctx, bodega)
 			result.Bodega = bodega
 		}
 	}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"sync"
+
+	"coviar_backend/internal/domain"
+)
+
+// EstructuraCache guarda en memoria el cuestionario armado por segmento y versión de
+// la guía. Las versiones publicadas no cambian, así que las entradas solo se
+// descartan cuando se edita la versión (GuiaEdicionService llama a Invalidar).
+//
+// Las estructuras guardadas se comparten entre pedidos y no deben modificarse.
+type EstructuraCache struct {
+	mu       sync.RWMutex
+	entradas map[claveEstructura]*domain.EstructuraAutoevaluacion
+	// generaciones cuenta las invalidaciones por versión para no guardar una
+	// estructura que se leyó antes de una edición
+	generaciones map[int]int
+}
+
+type claveEstructura struct {
+	idSegmento    int
+	idGuiaVersion int
+}
+
+func NewEstructuraCache() *EstructuraCache {
+	return &EstructuraCache{
+		entradas:     make(map[claveEstructura]*domain.EstructuraAutoevaluacion),
+		generaciones: make(map[int]int),
+	}
+}
+
+// obtener devuelve la estructura guardada o, si no está, la generación actual de la
+// versión para pasársela a guardar
+func (c *EstructuraCache) obtener(idSegmento, idGuiaVersion int) (*domain.EstructuraAutoevaluacion, int) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entradas[claveEstructura{idSegmento, idGuiaVersion}], c.generaciones[idGuiaVersion]
+}
+
+// guardar descarta la estructura si la versión se invalidó mientras se leía
+func (c *EstructuraCache) guardar(idSegmento, idGuiaVersion, generacion int, estructura *domain.EstructuraAutoevaluacion) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generaciones[idGuiaVersion] != generacion {
+		return
+	}
+	c.entradas[claveEstructura{idSegmento, idGuiaVersion}] = estructura
+}
+
+// Invalidar descarta las estructuras de todos los segmentos de la versión. Debe llamarse
+// después de confirmar la edición: las cargas que empezaron antes no se guardan porque
+// cambió la generación.
+func (c *EstructuraCache) Invalidar(idGuiaVersion int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generaciones[idGuiaVersion]++
+	for clave := range c.entradas {
+		if clave.idGuiaVersion == idGuiaVersion {
+			delete(c.entradas, clave)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+)
+
+// Tamaño de la guía de prueba, similar al de la guía real
+const (
+	cantidadCapitulosPrueba      = 12
+	indicadoresPorCapituloPrueba = 10
+	nivelesPorIndicadorPrueba    = 4
+)
+
+// guiaPrueba es una guía en memoria que cuenta las consultas que recibe
+type guiaPrueba struct {
+	capitulos   []*domain.Capitulo
+	indicadores []*domain.Indicador
+	niveles     []*domain.NivelRespuesta
+	consultas   int
+}
+
+func nuevaGuiaPrueba() *guiaPrueba {
+	g := &guiaPrueba{}
+	for c := 1; c <= cantidadCapitulosPrueba; c++ {
+		g.capitulos = append(g.capitulos, &domain.Capitulo{ID: c, IDGuiaVersion: 1, Clave: fmt.Sprintf("C%d", c), Orden: c})
+		for i := 1; i <= indicadoresPorCapituloPrueba; i++ {
+			idIndicador := len(g.indicadores) + 1
+			g.indicadores = append(g.indicadores, &domain.Indicador{ID: idIndicador, IDCapitulo: c, Clave: fmt.Sprintf("C%d.%d", c, i), Orden: i})
+			for n := 1; n <= nivelesPorIndicadorPrueba; n++ {
+				g.niveles = append(g.niveles, &domain.NivelRespuesta{ID: len(g.niveles) + 1, IDIndicador: idIndicador, Puntos: n, Posicion: n})
+			}
+		}
+	}
+	return g
+}
+
+// Repositorios sobre la guía de prueba; solo implementan los métodos que usa la carga de
+// la estructura
+
+type capitulosPrueba struct {
+	repository.CapituloRepository
+	*guiaPrueba
+}
+
+func (c capitulosPrueba) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Capitulo, error) {
+	c.consultas++
+	return c.capitulos, nil
+}
+
+type indicadoresPrueba struct {
+	repository.IndicadorRepository
+	*guiaPrueba
+}
+
+func (i indicadoresPrueba) FindBySegmento(ctx context.Context, idSegmento, idGuiaVersion int) ([]int, error) {
+	i.consultas++
+	ids := make([]int, 0, len(i.indicadores))
+	for _, ind := range i.indicadores {
+		ids = append(ids, ind.ID)
+	}
+	return ids, nil
+}
+
+func (i indicadoresPrueba) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.Indicador, error) {
+	i.consultas++
+	return i.indicadores, nil
+}
+
+func (i indicadoresPrueba) FindByCapitulo(ctx context.Context, idCapitulo int) ([]*domain.Indicador, error) {
+	i.consultas++
+	var indicadores []*domain.Indicador
+	for _, ind := range i.indicadores {
+		if ind.IDCapitulo == idCapitulo {
+			indicadores = append(indicadores, ind)
+		}
+	}
+	return indicadores, nil
+}
+
+type nivelesPrueba struct {
+	repository.NivelRespuestaRepository
+	*guiaPrueba
+}
+
+func (n nivelesPrueba) FindByVersion(ctx context.Context, idGuiaVersion int) ([]*domain.NivelRespuesta, error) {
+	n.consultas++
+	return n.niveles, nil
+}
+
+func (n nivelesPrueba) FindByIndicador(ctx context.Context, idIndicador int) ([]*domain.NivelRespuesta, error) {
+	n.consultas++
+	var niveles []*domain.NivelRespuesta
+	for _, nivel := range n.niveles {
+		if nivel.IDIndicador == idIndicador {
+			niveles = append(niveles, nivel)
+		}
+	}
+	return niveles, nil
+}
+
+type autoevaluacionesPrueba struct {
+	repository.AutoevaluacionRepository
+}
+
+func (autoevaluacionesPrueba) FindByID(ctx context.Context, id int) (*domain.Autoevaluacion, error) {
+	idSegmento := 1
+	return &domain.Autoevaluacion{ID: id, IDSegmento: &idSegmento, IDGuiaVersion: 1, Estado: domain.EstadoPendiente}, nil
+}
+
+// cargarEstructuraPorCapitulo reproduce la carga anterior al caché: una consulta de
+// indicadores por capítulo y una de niveles por indicador
+func cargarEstructuraPorCapitulo(ctx context.Context, g *guiaPrueba, idSegmento, idGuiaVersion int) (*domain.EstructuraAutoevaluacion, error) {
+	indicadorRepo, nivelRepo := indicadoresPrueba{guiaPrueba: g}, nivelesPrueba{guiaPrueba: g}
+
+	habilitados, err := indicadorRepo.FindBySegmento(ctx, idSegmento, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+	habilitadosMap := make(map[int]bool, len(habilitados))
+	for _, id := range habilitados {
+		habilitadosMap[id] = true
+	}
+
+	capitulos, err := capitulosPrueba{guiaPrueba: g}.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	estructura := &domain.EstructuraAutoevaluacion{Capitulos: make([]*domain.CapituloEstructura, 0)}
+	for _, cap := range capitulos {
+		indicadores, err := indicadorRepo.FindByCapitulo(ctx, cap.ID)
+		if err != nil {
+			return nil, err
+		}
+		capEstructura := &domain.CapituloEstructura{Capitulo: cap, Indicadores: make([]*domain.IndicadorConHabilitacion, 0)}
+		for _, ind := range indicadores {
+			niveles, err := nivelRepo.FindByIndicador(ctx, ind.ID)
+			if err != nil {
+				return nil, err
+			}
+			capEstructura.Indicadores = append(capEstructura.Indicadores, &domain.IndicadorConHabilitacion{
+				Indicador:        ind,
+				NivelesRespuesta: niveles,
+				Habilitado:       habilitadosMap[ind.ID],
+			})
+		}
+		estructura.Capitulos = append(estructura.Capitulos, capEstructura)
+	}
+	return estructura, nil
+}
+
+func nuevoServicioPrueba(g *guiaPrueba, cache *EstructuraCache) *AutoevaluacionService {
+	return &AutoevaluacionService{
+		autoevaluacionRepo: autoevaluacionesPrueba{},
+		capituloRepo:       capitulosPrueba{guiaPrueba: g},
+		indicadorRepo:      indicadoresPrueba{guiaPrueba: g},
+		nivelRespuestaRepo: nivelesPrueba{guiaPrueba: g},
+		estructuraCache:    cache,
+	}
+}
+
+// BenchmarkGetEstructura compara las consultas por carga de la estructura: la carga
+// anterior (por capítulo y por indicador), la de consultas fijas y la servida desde caché
+func BenchmarkGetEstructura(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("por_capitulo", func(b *testing.B) {
+		g := nuevaGuiaPrueba()
+		for i := 0; i < b.N; i++ {
+			if _, err := cargarEstructuraPorCapitulo(ctx, g, 1, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(g.consultas)/float64(b.N), "consultas/op")
+	})
+
+	b.Run("consultas_fijas", func(b *testing.B) {
+		g := nuevaGuiaPrueba()
+		s := nuevoServicioPrueba(g, NewEstructuraCache())
+		for i := 0; i < b.N; i++ {
+			if _, err := s.cargarEstructura(ctx, 1, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(g.consultas)/float64(b.N), "consultas/op")
+	})
+
+	b.Run("cache", func(b *testing.B) {
+		g := nuevaGuiaPrueba()
+		s := nuevoServicioPrueba(g, NewEstructuraCache())
+		for i := 0; i < b.N; i++ {
+			if _, err := s.GetEstructura(ctx, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(g.consultas)/float64(b.N), "consultas/op")
+	})
+}
+
+func TestCargarEstructuraEquivalePorCapitulo(t *testing.T) {
+	ctx := context.Background()
+	g := nuevaGuiaPrueba()
+
+	anterior, err := cargarEstructuraPorCapitulo(ctx, g, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consultasAnterior := g.consultas
+
+	g.consultas = 0
+	nueva, err := nuevoServicioPrueba(g, NewEstructuraCache()).cargarEstructura(ctx, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.consultas != 4 {
+		t.Errorf("la carga hizo %d consultas, se esperaban 4", g.consultas)
+	}
+	if consultasAnterior <= g.consultas {
+		t.Errorf("la carga anterior hizo %d consultas, no más que la nueva (%d)", consultasAnterior, g.consultas)
+	}
+
+	if len(nueva.Capitulos) != len(anterior.Capitulos) {
+		t.Fatalf("capítulos: %d, se esperaban %d", len(nueva.Capitulos), len(anterior.Capitulos))
+	}
+	for i, cap := range nueva.Capitulos {
+		esperado := anterior.Capitulos[i]
+		if cap.Capitulo.ID != esperado.Capitulo.ID || len(cap.Indicadores) != len(esperado.Indicadores) {
+			t.Fatalf("capítulo %d distinto", esperado.Capitulo.ID)
+		}
+		for j, ind := range cap.Indicadores {
+			if ind.Indicador.ID != esperado.Indicadores[j].Indicador.ID || len(ind.NivelesRespuesta) != len(esperado.Indicadores[j].NivelesRespuesta) {
+				t.Fatalf("indicador %d distinto", esperado.Indicadores[j].Indicador.ID)
+			}
+		}
+	}
+}
+
+func TestEstructuraCacheDescartaCargaDesactualizada(t *testing.T) {
+	cache := NewEstructuraCache()
+
+	// Una carga empieza antes de que se edite la versión y termina después
+	_, generacion := cache.obtener(1, 1)
+	cache.Invalidar(1)
+	cache.guardar(1, 1, generacion, &domain.EstructuraAutoevaluacion{})
+
+	if e, _ := cache.obtener(1, 1); e != nil {
+		t.Fatal("se guardó una estructura leída antes de invalidar la versión")
+	}
+
+	// Las cargas de otras versiones no se ven afectadas
+	_, generacion = cache.obtener(1, 2)
+	cache.Invalidar(1)
+	cache.guardar(1, 2, generacion, &domain.EstructuraAutoevaluacion{})
+	if e, _ := cache.obtener(1, 2); e == nil {
+		t.Fatal("se descartó la estructura de una versión que no se editó")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"coviar_backend/internal/domain"
@@ -78,8 +79,7 @@ func (s *EvidenciaService) AgregarEvidencia(
 	}
 
 	// 3. Crear directorio si no existe
-	basePath := "evidencias"
-	bodegaPath := filepath.Join(basePath, fmt.Sprintf("%d", bodegaId))
+	bodegaPath := filepath.Join(directorioEvidencias, fmt.Sprintf("%d", bodegaId))
 	if err := os.MkdirAll(bodegaPath, 0755); err != nil {
 		return nil, fmt.Errorf("error creating directory: %w", err)
 	}
@@ -202,20 +202,42 @@ func (s *EvidenciaService) DescargarEvidencia(ctx context.Context, evidencia *do
 	return fileData, evidencia.Nombre, nil
 }
 
+// archivoNotasZip es el nombre de la entrada del ZIP con las notas de las respuestas
+const archivoNotasZip = "notas.txt"
+
 // DescargarTodasEvidenciasZip retorna un ZIP con todas las evidencias de una autoevaluación
+// y, si alguna respuesta tiene nota, un archivo de texto con las notas
 func (s *EvidenciaService) DescargarTodasEvidenciasZip(ctx context.Context, idAutoevaluacion int) ([]byte, error) {
 	evidencias, err := s.evidenciaRepo.FindByAutoevaluacion(ctx, idAutoevaluacion)
 	if err != nil {
 		return nil, fmt.Errorf("error getting evidencias: %w", err)
 	}
 
-	if len(evidencias) == 0 {
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+	notas := notasRespuestas(respuestas)
+
+	if len(evidencias) == 0 && notas == nil {
 		return nil, fmt.Errorf("no evidencias found for this autoevaluacion")
 	}
 
 	zipBuffer := new(writeCounter)
 	zipWriter := zip.NewWriter(zipBuffer)
 
+	if notas != nil {
+		zipEntry, err := zipWriter.Create(archivoNotasZip)
+		if err != nil {
+			zipWriter.Close()
+			return nil, fmt.Errorf("error creating zip entry for %s: %w", archivoNotasZip, err)
+		}
+		if _, err := zipEntry.Write(notas); err != nil {
+			zipWriter.Close()
+			return nil, fmt.Errorf("error writing to zip entry for %s: %w", archivoNotasZip, err)
+		}
+	}
+
 	for _, ev := range evidencias {
 		fileData, err := os.ReadFile(ev.Ubicacion)
 		if err != nil {
@@ -242,6 +264,24 @@ func (s *EvidenciaService) DescargarTodasEvidenciasZip(ctx context.Context, idAu
 	return zipBuffer.Bytes(), nil
 }
 
+// notasRespuestas arma el texto con las notas de las respuestas, en el orden del
+// cuestionario; devuelve nil si ninguna respuesta tiene nota
+func notasRespuestas(respuestas []*domain.RespuestaDetalle) []byte {
+	var b strings.Builder
+	for _, r := range respuestas {
+		if r.Nota == nil {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s\r\n", r.ClaveIndicador, r.Indicador)
+		fmt.Fprintf(&b, "Respuesta: %s (%d puntos)\r\n", r.NivelRespuesta, r.Puntos)
+		fmt.Fprintf(&b, "Nota: %s\r\n\r\n", strings.ReplaceAll(*r.Nota, "\n", "\r\n"))
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	return []byte(b.String())
+}
+
 // validatePDFFile valida que el archivo sea PDF y no exceda 2MB
 // Solo valida extensión y tamaño, NO la firma (magic bytes)
 func (s *EvidenciaService) validatePDFFile(fileBytes []byte, fileName string) error {
@@ -284,7 +324,7 @@ func (s *EvidenciaService) updateAutoevaluacionEvidenciaStatus(ctx context.Conte
 		return fmt.Errorf("segmento not selected in autoevaluacion")
 	}
 
-	indicadores, err := s.indicadorRepo.FindBySegmento(ctx, *auto.IDSegmento)
+	indicadores, err := s.indicadorRepo.FindBySegmento(ctx, *auto.IDSegmento, auto.IDGuiaVersion)
 	if err != nil {
 		return fmt.Errorf("error getting indicadores: %w", err)
 	}
@@ -317,16 +357,17 @@ func (s *EvidenciaService) updateAutoevaluacionEvidenciaStatus(ctx context.Conte
 		}
 	}
 
-	var estado domain.EstadoEvidencia
-	if evidenciasSegmento == 0 {
-		estado = domain.EstadoSinEvidencia
-	} else if evidenciasSegmento == respuestasSegmento && respuestasSegmento > 0 {
-		estado = domain.EstadoCompleta
-	} else {
-		estado = domain.EstadoParcial
-	}
+	return s.autoevaluacionRepo.UpdateEvidenciaStatus(ctx, nil, idAutoevaluacion, estadoEvidencia(respuestasSegmento, evidenciasSegmento))
+}
 
-	return s.autoevaluacionRepo.UpdateEvidenciaStatus(ctx, idAutoevaluacion, estado)
+// estadoEvidencia indica si todas, algunas o ninguna de las respuestas tienen evidencia
+func estadoEvidencia(respuestas, evidencias int) domain.EstadoEvidencia {
+	if evidencias == 0 {
+		return domain.EstadoSinEvidencia
+	} else if evidencias == respuestas && respuestas > 0 {
+		return domain.EstadoCompleta
+	}
+	return domain.EstadoParcial
 }
 
 type writeCounter struct {
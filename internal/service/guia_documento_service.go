@@ -0,0 +1,620 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/guia"
+	"coviar_backend/pkg/validator"
+)
+
+const (
+	cambioTipoCapitulo              = "CAPITULO"
+	cambioTipoIndicador             = "INDICADOR"
+	cambioTipoNivelesSostenibilidad = "NIVELES_SOSTENIBILIDAD"
+	cambioTipoEstrategiaPuntaje     = "ESTRATEGIA_PUNTAJE"
+)
+
+// GuiaDocumentoService exporta el cuestionario de una versión de la guía a un documento
+// portable (JSON o YAML) e importa documentos como una nueva versión en borrador,
+// mostrando antes las diferencias con la versión publicada
+type GuiaDocumentoService struct {
+	edicionService     *GuiaEdicionService
+	guiaVersionRepo    repository.GuiaVersionRepository
+	capituloRepo       repository.CapituloRepository
+	indicadorRepo      repository.IndicadorRepository
+	nivelRespuestaRepo repository.NivelRespuestaRepository
+	segmentoRepo       repository.SegmentoRepository
+	txManager          repository.TransactionManager
+}
+
+func NewGuiaDocumentoService(
+	edicionService *GuiaEdicionService,
+	guiaVersionRepo repository.GuiaVersionRepository,
+	capituloRepo repository.CapituloRepository,
+	indicadorRepo repository.IndicadorRepository,
+	nivelRespuestaRepo repository.NivelRespuestaRepository,
+	segmentoRepo repository.SegmentoRepository,
+	txManager repository.TransactionManager,
+) *GuiaDocumentoService {
+	return &GuiaDocumentoService{
+		edicionService:     edicionService,
+		guiaVersionRepo:    guiaVersionRepo,
+		capituloRepo:       capituloRepo,
+		indicadorRepo:      indicadorRepo,
+		nivelRespuestaRepo: nivelRespuestaRepo,
+		segmentoRepo:       segmentoRepo,
+		txManager:          txManager,
+	}
+}
+
+// Exportar arma el documento de una versión de la guía; con idGuiaVersion 0 exporta la publicada
+func (s *GuiaDocumentoService) Exportar(ctx context.Context, idGuiaVersion int) (*guia.Documento, error) {
+	if idGuiaVersion == 0 {
+		publicada, err := s.guiaVersionRepo.FindPublicada(ctx)
+		if err != nil {
+			return nil, err
+		}
+		idGuiaVersion = publicada.ID
+	}
+
+	contenido, err := s.edicionService.GetContenido(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	segmentos, err := s.segmentoRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nombres := make(map[int]string, len(segmentos))
+	for _, seg := range segmentos {
+		nombres[seg.ID] = seg.Nombre
+	}
+
+	doc := &guia.Documento{
+		Version:               contenido.Version.Numero,
+		Descripcion:           contenido.Version.Descripcion,
+		EstrategiaPuntaje:     string(contenido.Version.EstrategiaPuntaje),
+		Capitulos:             make([]guia.Capitulo, 0, len(contenido.Capitulos)),
+		NivelesSostenibilidad: make([]guia.NivelesSegmento, 0),
+	}
+	clavesCapitulo := make(map[int]string, len(contenido.Capitulos))
+	for _, c := range contenido.Capitulos {
+		clavesCapitulo[c.Capitulo.ID] = c.Capitulo.Clave
+		capitulo := guia.Capitulo{
+			Clave:       c.Capitulo.Clave,
+			Nombre:      c.Capitulo.Nombre,
+			Descripcion: c.Capitulo.Descripcion,
+			Peso:        c.Capitulo.Peso,
+			Indicadores: make([]guia.Indicador, 0, len(c.Indicadores)),
+		}
+		for _, i := range c.Indicadores {
+			indicador := guia.Indicador{
+				Clave:            i.Indicador.Clave,
+				Nombre:           i.Indicador.Nombre,
+				Descripcion:      i.Indicador.Descripcion,
+				Peso:             i.Indicador.Peso,
+				Segmentos:        make([]string, 0, len(i.Segmentos)),
+				NivelesRespuesta: make([]guia.NivelRespuesta, 0, len(i.NivelesRespuesta)),
+			}
+			for _, idSegmento := range i.Segmentos {
+				indicador.Segmentos = append(indicador.Segmentos, nombres[idSegmento])
+			}
+			for _, n := range i.NivelesRespuesta {
+				indicador.NivelesRespuesta = append(indicador.NivelesRespuesta, guia.NivelRespuesta{
+					Nombre:      n.Nombre,
+					Descripcion: n.Descripcion,
+					Puntos:      n.Puntos,
+				})
+			}
+			capitulo.Indicadores = append(capitulo.Indicadores, indicador)
+		}
+		doc.Capitulos = append(doc.Capitulos, capitulo)
+	}
+
+	// Los niveles vienen ordenados por segmento y puntaje mínimo
+	for _, n := range contenido.NivelesSostenibilidad {
+		ultimo := len(doc.NivelesSostenibilidad) - 1
+		if ultimo < 0 || doc.NivelesSostenibilidad[ultimo].Segmento != nombres[n.IDSegmento] {
+			doc.NivelesSostenibilidad = append(doc.NivelesSostenibilidad, guia.NivelesSegmento{Segmento: nombres[n.IDSegmento]})
+			ultimo++
+		}
+		nivel := guia.NivelSostenibilidad{
+			Nombre:     n.Nombre,
+			MinPuntaje: n.MinPuntaje,
+			MaxPuntaje: n.MaxPuntaje,
+		}
+		if n.MesesVigencia != nil {
+			nivel.MesesVigencia = *n.MesesVigencia
+		}
+		for _, req := range n.Requisitos {
+			nivel.Requisitos = append(nivel.Requisitos, guia.RequisitoCapitulo{Capitulo: clavesCapitulo[req.IDCapitulo], MinPorcentaje: req.MinPorcentaje})
+		}
+		doc.NivelesSostenibilidad[ultimo].Niveles = append(doc.NivelesSostenibilidad[ultimo].Niveles, nivel)
+	}
+
+	return doc, nil
+}
+
+// Importar valida el documento y lo compara con la versión publicada. Con aplicar=true
+// lo guarda, en una sola transacción, como una nueva versión en borrador que luego
+// debe publicarse.
+func (s *GuiaDocumentoService) Importar(ctx context.Context, doc *guia.Documento, descripcion string, aplicar bool) (*domain.ReporteImportacionGuia, error) {
+	segmentos, err := s.segmentoRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idsSegmento := make(map[string]int, len(segmentos))
+	for _, seg := range segmentos {
+		idsSegmento[seg.Nombre] = seg.ID
+	}
+
+	normalizarDocumento(doc)
+	if err := validarDocumento(doc, idsSegmento); err != nil {
+		return nil, err
+	}
+
+	publicada, err := s.guiaVersionRepo.FindPublicada(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actual, err := s.Exportar(ctx, publicada.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	reporte := compararDocumentos(actual, doc)
+	reporte.VersionComparada = publicada
+	if !aplicar {
+		return reporte, nil
+	}
+
+	borrador, err := s.guiaVersionRepo.FindBorrador(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if borrador != nil {
+		return nil, domain.ErrGuiaBorradorExistente
+	}
+
+	descripcion = strings.TrimSpace(descripcion)
+	if descripcion == "" {
+		descripcion = doc.Descripcion
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	version, err := s.guiaVersionRepo.CrearBorradorVacio(ctx, tx, publicada.ID, descripcion)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.guardarDocumento(ctx, tx, version.ID, doc, idsSegmento); err != nil {
+		return nil, err
+	}
+	version.EstrategiaPuntaje = domain.EstrategiaPuntaje(doc.EstrategiaPuntaje)
+	if err := s.guiaVersionRepo.UpdateEstrategia(ctx, tx, version.ID, version.EstrategiaPuntaje); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+
+	reporte.Aplicado = true
+	reporte.Borrador = version
+	return reporte, nil
+}
+
+func (s *GuiaDocumentoService) guardarDocumento(ctx context.Context, tx repository.Transaction, idGuiaVersion int, doc *guia.Documento, idsSegmento map[string]int) error {
+	idsCapitulo := make(map[string]int, len(doc.Capitulos))
+	for i, c := range doc.Capitulos {
+		capitulo := &domain.Capitulo{
+			IDGuiaVersion: idGuiaVersion,
+			Clave:         c.Clave,
+			Nombre:        c.Nombre,
+			Descripcion:   c.Descripcion,
+			Peso:          c.Peso,
+			Orden:         i + 1,
+		}
+		if _, err := s.capituloRepo.Create(ctx, tx, capitulo); err != nil {
+			return err
+		}
+		idsCapitulo[c.Clave] = capitulo.ID
+
+		for j, ind := range c.Indicadores {
+			indicador := &domain.Indicador{
+				IDCapitulo:  capitulo.ID,
+				Clave:       ind.Clave,
+				Nombre:      ind.Nombre,
+				Descripcion: ind.Descripcion,
+				Peso:        ind.Peso,
+				Orden:       j + 1,
+			}
+			if _, err := s.indicadorRepo.Create(ctx, tx, indicador); err != nil {
+				return err
+			}
+
+			for k, n := range ind.NivelesRespuesta {
+				nivel := &domain.NivelRespuesta{
+					IDIndicador: indicador.ID,
+					Nombre:      n.Nombre,
+					Descripcion: n.Descripcion,
+					Puntos:      n.Puntos,
+					Posicion:    k + 1,
+				}
+				if _, err := s.nivelRespuestaRepo.Create(ctx, tx, nivel); err != nil {
+					return err
+				}
+			}
+
+			segmentos := make([]int, 0, len(ind.Segmentos))
+			for _, nombre := range ind.Segmentos {
+				segmentos = append(segmentos, idsSegmento[nombre])
+			}
+			if err := s.indicadorRepo.ReplaceSegmentos(ctx, tx, indicador.ID, segmentos); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, ns := range doc.NivelesSostenibilidad {
+		niveles := make([]*domain.NivelSostenibilidad, 0, len(ns.Niveles))
+		for _, n := range ns.Niveles {
+			nivel := &domain.NivelSostenibilidad{Nombre: n.Nombre, MinPuntaje: n.MinPuntaje, MaxPuntaje: n.MaxPuntaje, MesesVigencia: mesesVigenciaNivel(n)}
+			for _, req := range n.Requisitos {
+				nivel.Requisitos = append(nivel.Requisitos, &domain.RequisitoCapitulo{IDCapitulo: idsCapitulo[req.Capitulo], MinPorcentaje: req.MinPorcentaje})
+			}
+			niveles = append(niveles, nivel)
+		}
+		if err := s.segmentoRepo.ReplaceNivelesSostenibilidad(ctx, tx, idsSegmento[ns.Segmento], idGuiaVersion, niveles); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// normalizarDocumento recorta los textos y completa los valores omitidos
+// (estrategia SUMA y pesos 1)
+func normalizarDocumento(doc *guia.Documento) {
+	doc.Descripcion = strings.TrimSpace(doc.Descripcion)
+	doc.EstrategiaPuntaje = strings.ToUpper(strings.TrimSpace(doc.EstrategiaPuntaje))
+	if doc.EstrategiaPuntaje == "" {
+		doc.EstrategiaPuntaje = string(domain.EstrategiaSuma)
+	}
+	for i := range doc.Capitulos {
+		c := &doc.Capitulos[i]
+		c.Clave, c.Nombre, c.Descripcion = strings.TrimSpace(c.Clave), strings.TrimSpace(c.Nombre), strings.TrimSpace(c.Descripcion)
+		if c.Peso == 0 {
+			c.Peso = 1
+		}
+		for j := range c.Indicadores {
+			ind := &c.Indicadores[j]
+			ind.Clave, ind.Nombre, ind.Descripcion = strings.TrimSpace(ind.Clave), strings.TrimSpace(ind.Nombre), strings.TrimSpace(ind.Descripcion)
+			if ind.Peso == 0 {
+				ind.Peso = 1
+			}
+			for k := range ind.Segmentos {
+				ind.Segmentos[k] = strings.TrimSpace(ind.Segmentos[k])
+			}
+			for k := range ind.NivelesRespuesta {
+				n := &ind.NivelesRespuesta[k]
+				n.Nombre, n.Descripcion = strings.TrimSpace(n.Nombre), strings.TrimSpace(n.Descripcion)
+			}
+		}
+	}
+	for i := range doc.NivelesSostenibilidad {
+		ns := &doc.NivelesSostenibilidad[i]
+		ns.Segmento = strings.TrimSpace(ns.Segmento)
+		for k := range ns.Niveles {
+			ns.Niveles[k].Nombre = strings.TrimSpace(ns.Niveles[k].Nombre)
+			for r := range ns.Niveles[k].Requisitos {
+				ns.Niveles[k].Requisitos[r].Capitulo = strings.TrimSpace(ns.Niveles[k].Requisitos[r].Capitulo)
+			}
+		}
+		sort.SliceStable(ns.Niveles, func(a, b int) bool { return ns.Niveles[a].MinPuntaje < ns.Niveles[b].MinPuntaje })
+	}
+}
+
+// validarDocumento aplica las mismas reglas que la edición de la guía; los errores
+// indican la ruta del elemento dentro del documento
+func validarDocumento(doc *guia.Documento, idsSegmento map[string]int) error {
+	var errs validator.ValidationErrors
+	agregar := func(campo, mensaje string) {
+		errs = append(errs, validator.ValidationError{Field: campo, Message: mensaje})
+	}
+
+	if _, ok := estrategiasPuntaje[domain.EstrategiaPuntaje(doc.EstrategiaPuntaje)]; !ok {
+		agregar("estrategia_puntaje", fmt.Sprintf("estrategia %q desconocida", doc.EstrategiaPuntaje))
+	}
+	if len(doc.Capitulos) == 0 {
+		agregar("capitulos", "el documento no tiene capítulos")
+	}
+
+	clavesCapitulo := make(map[string]bool)
+	clavesIndicador := make(map[string]bool)
+	for i, c := range doc.Capitulos {
+		campo := fmt.Sprintf("capitulos[%d]", i)
+		if c.Clave != "" {
+			if clavesCapitulo[c.Clave] {
+				agregar(campo+".clave", fmt.Sprintf("clave %q repetida", c.Clave))
+			}
+			clavesCapitulo[c.Clave] = true
+		}
+		if c.Nombre == "" {
+			agregar(campo+".nombre", "el nombre no puede estar vacío")
+		}
+		if c.Descripcion == "" {
+			agregar(campo+".descripcion", "la descripción no puede estar vacía")
+		}
+		if c.Peso < 0 {
+			agregar(campo+".peso", "el peso debe ser mayor que cero")
+		}
+
+		for j, ind := range c.Indicadores {
+			campoInd := fmt.Sprintf("%s.indicadores[%d]", campo, j)
+			if ind.Clave != "" {
+				if clavesIndicador[ind.Clave] {
+					agregar(campoInd+".clave", fmt.Sprintf("clave %q repetida", ind.Clave))
+				}
+				clavesIndicador[ind.Clave] = true
+			}
+			if ind.Nombre == "" {
+				agregar(campoInd+".nombre", "el nombre no puede estar vacío")
+			}
+			if ind.Descripcion == "" {
+				agregar(campoInd+".descripcion", "la descripción no puede estar vacía")
+			}
+			if ind.Peso < 0 {
+				agregar(campoInd+".peso", "el peso debe ser mayor que cero")
+			}
+
+			vistos := make(map[string]bool)
+			for _, nombre := range ind.Segmentos {
+				if _, ok := idsSegmento[nombre]; !ok {
+					agregar(campoInd+".segmentos", fmt.Sprintf("el segmento %q no existe", nombre))
+				} else if vistos[nombre] {
+					agregar(campoInd+".segmentos", fmt.Sprintf("segmento %q repetido", nombre))
+				}
+				vistos[nombre] = true
+			}
+
+			if len(ind.NivelesRespuesta) == 0 {
+				agregar(campoInd+".niveles_respuesta", "el indicador no tiene niveles de respuesta")
+			}
+			for k, n := range ind.NivelesRespuesta {
+				campoNivel := fmt.Sprintf("%s.niveles_respuesta[%d]", campoInd, k)
+				if n.Nombre == "" {
+					agregar(campoNivel+".nombre", "el nombre no puede estar vacío")
+				}
+				if n.Descripcion == "" {
+					agregar(campoNivel+".descripcion", "la descripción no puede estar vacía")
+				}
+				if n.Puntos < 0 {
+					agregar(campoNivel+".puntos", "los puntos no pueden ser negativos")
+				}
+			}
+		}
+	}
+
+	segmentosConNiveles := make(map[string]bool)
+	for i, ns := range doc.NivelesSostenibilidad {
+		campo := fmt.Sprintf("niveles_sostenibilidad[%d]", i)
+		if _, ok := idsSegmento[ns.Segmento]; !ok {
+			agregar(campo+".segmento", fmt.Sprintf("el segmento %q no existe", ns.Segmento))
+		} else if segmentosConNiveles[ns.Segmento] {
+			agregar(campo+".segmento", fmt.Sprintf("segmento %q repetido", ns.Segmento))
+		}
+		segmentosConNiveles[ns.Segmento] = true
+
+		niveles := make([]*domain.NivelSostenibilidad, 0, len(ns.Niveles))
+		for k, n := range ns.Niveles {
+			niveles = append(niveles, &domain.NivelSostenibilidad{Nombre: n.Nombre, MinPuntaje: n.MinPuntaje, MaxPuntaje: n.MaxPuntaje, MesesVigencia: mesesVigenciaNivel(n)})
+
+			vistos := make(map[string]bool)
+			for r, req := range n.Requisitos {
+				campoReq := fmt.Sprintf("%s.niveles[%d].requisitos[%d]", campo, k, r)
+				switch {
+				case req.Capitulo == "" || !clavesCapitulo[req.Capitulo]:
+					agregar(campoReq+".capitulo", fmt.Sprintf("el capítulo %q no está en el documento", req.Capitulo))
+				case vistos[req.Capitulo]:
+					agregar(campoReq+".capitulo", fmt.Sprintf("capítulo %q repetido", req.Capitulo))
+				case req.MinPorcentaje < 0 || req.MinPorcentaje > 100:
+					agregar(campoReq+".min_porcentaje", "debe estar entre 0 y 100")
+				}
+				vistos[req.Capitulo] = true
+			}
+		}
+		if err := ValidarRangosNiveles(niveles); err != nil {
+			if rangos, ok := err.(validator.ValidationErrors); ok {
+				for _, e := range rangos {
+					agregar(campo+"."+e.Field, e.Message)
+				}
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// indicadorUbicado es un indicador del documento junto con su capítulo y posición
+type indicadorUbicado struct {
+	guia.Indicador
+	capitulo string
+	orden    int
+}
+
+func indicadoresPorClave(doc *guia.Documento) map[string]indicadorUbicado {
+	indicadores := make(map[string]indicadorUbicado)
+	for _, c := range doc.Capitulos {
+		for j, ind := range c.Indicadores {
+			if ind.Clave != "" {
+				indicadores[ind.Clave] = indicadorUbicado{Indicador: ind, capitulo: c.Clave, orden: j + 1}
+			}
+		}
+	}
+	return indicadores
+}
+
+// compararDocumentos informa qué capítulos, indicadores y niveles de sostenibilidad
+// agrega, modifica o retira el documento nuevo respecto del actual. Los elementos
+// se asocian por clave (los segmentos, por nombre); sin clave se consideran nuevos.
+func compararDocumentos(actual, nuevo *guia.Documento) *domain.ReporteImportacionGuia {
+	reporte := &domain.ReporteImportacionGuia{
+		Agregados:   make([]domain.CambioGuia, 0),
+		Modificados: make([]domain.CambioGuia, 0),
+		Retirados:   make([]domain.CambioGuia, 0),
+	}
+	registrar := func(cambio domain.CambioGuia, existia bool) {
+		switch {
+		case !existia:
+			reporte.Agregados = append(reporte.Agregados, cambio)
+		case len(cambio.Detalle) > 0:
+			reporte.Modificados = append(reporte.Modificados, cambio)
+		default:
+			reporte.SinCambios++
+		}
+	}
+
+	if actual.EstrategiaPuntaje != nuevo.EstrategiaPuntaje {
+		cambio := domain.CambioGuia{Tipo: cambioTipoEstrategiaPuntaje, Nombre: nuevo.EstrategiaPuntaje}
+		cambio.Detalle = detalleCambio(nil, "estrategia", actual.EstrategiaPuntaje, nuevo.EstrategiaPuntaje)
+		reporte.Modificados = append(reporte.Modificados, cambio)
+	}
+
+	// Capítulos
+	capitulosActuales := make(map[string]int)
+	for i, c := range actual.Capitulos {
+		capitulosActuales[c.Clave] = i
+	}
+	capitulosNuevos := make(map[string]bool)
+	for i, c := range nuevo.Capitulos {
+		cambio := domain.CambioGuia{Tipo: cambioTipoCapitulo, Clave: c.Clave, Nombre: c.Nombre}
+		pos, existia := capitulosActuales[c.Clave]
+		if existia && c.Clave != "" {
+			capitulosNuevos[c.Clave] = true
+			anterior := actual.Capitulos[pos]
+			cambio.Detalle = detalleCambio(cambio.Detalle, "nombre", anterior.Nombre, c.Nombre)
+			cambio.Detalle = detalleCambio(cambio.Detalle, "descripción", anterior.Descripcion, c.Descripcion)
+			cambio.Detalle = detalleCambio(cambio.Detalle, "orden", fmt.Sprint(pos+1), fmt.Sprint(i+1))
+			cambio.Detalle = detalleCambio(cambio.Detalle, "peso", fmt.Sprint(anterior.Peso), fmt.Sprint(c.Peso))
+		}
+		registrar(cambio, existia && c.Clave != "")
+	}
+	for _, c := range actual.Capitulos {
+		if !capitulosNuevos[c.Clave] {
+			reporte.Retirados = append(reporte.Retirados, domain.CambioGuia{Tipo: cambioTipoCapitulo, Clave: c.Clave, Nombre: c.Nombre})
+		}
+	}
+
+	// Indicadores
+	indicadoresActuales := indicadoresPorClave(actual)
+	indicadoresNuevos := indicadoresPorClave(nuevo)
+	for _, c := range nuevo.Capitulos {
+		for _, ind := range c.Indicadores {
+			cambio := domain.CambioGuia{Tipo: cambioTipoIndicador, Clave: ind.Clave, Nombre: ind.Nombre}
+			anterior, existia := indicadoresActuales[ind.Clave]
+			if existia && ind.Clave != "" {
+				ubicado := indicadoresNuevos[ind.Clave]
+				cambio.Detalle = detalleCambio(cambio.Detalle, "nombre", anterior.Nombre, ind.Nombre)
+				cambio.Detalle = detalleCambio(cambio.Detalle, "descripción", anterior.Descripcion, ind.Descripcion)
+				cambio.Detalle = detalleCambio(cambio.Detalle, "capítulo", anterior.capitulo, ubicado.capitulo)
+				cambio.Detalle = detalleCambio(cambio.Detalle, "orden", fmt.Sprint(anterior.orden), fmt.Sprint(ubicado.orden))
+				cambio.Detalle = detalleCambio(cambio.Detalle, "peso", fmt.Sprint(anterior.Peso), fmt.Sprint(ind.Peso))
+				cambio.Detalle = detalleCambio(cambio.Detalle, "segmentos", listaSegmentos(anterior.Segmentos), listaSegmentos(ind.Segmentos))
+				cambio.Detalle = detalleCambio(cambio.Detalle, "niveles de respuesta", listaNivelesRespuesta(anterior.NivelesRespuesta), listaNivelesRespuesta(ind.NivelesRespuesta))
+			}
+			registrar(cambio, existia && ind.Clave != "")
+		}
+	}
+	for _, c := range actual.Capitulos {
+		for _, ind := range c.Indicadores {
+			if _, ok := indicadoresNuevos[ind.Clave]; !ok {
+				reporte.Retirados = append(reporte.Retirados, domain.CambioGuia{Tipo: cambioTipoIndicador, Clave: ind.Clave, Nombre: ind.Nombre})
+			}
+		}
+	}
+
+	// Niveles de sostenibilidad por segmento
+	nivelesActuales := make(map[string][]guia.NivelSostenibilidad)
+	for _, ns := range actual.NivelesSostenibilidad {
+		nivelesActuales[ns.Segmento] = ns.Niveles
+	}
+	nivelesNuevos := make(map[string]bool)
+	for _, ns := range nuevo.NivelesSostenibilidad {
+		nivelesNuevos[ns.Segmento] = true
+		cambio := domain.CambioGuia{Tipo: cambioTipoNivelesSostenibilidad, Clave: ns.Segmento, Nombre: ns.Segmento}
+		anteriores, existia := nivelesActuales[ns.Segmento]
+		if existia {
+			cambio.Detalle = detalleCambio(cambio.Detalle, "rangos", listaRangos(anteriores), listaRangos(ns.Niveles))
+		}
+		registrar(cambio, existia)
+	}
+	for _, ns := range actual.NivelesSostenibilidad {
+		if !nivelesNuevos[ns.Segmento] {
+			reporte.Retirados = append(reporte.Retirados, domain.CambioGuia{Tipo: cambioTipoNivelesSostenibilidad, Clave: ns.Segmento, Nombre: ns.Segmento})
+		}
+	}
+
+	return reporte
+}
+
+func detalleCambio(detalle []string, campo, anterior, nuevo string) []string {
+	if anterior == nuevo {
+		return detalle
+	}
+	return append(detalle, fmt.Sprintf("%s: %q → %q", campo, anterior, nuevo))
+}
+
+func listaSegmentos(segmentos []string) string {
+	ordenados := append([]string(nil), segmentos...)
+	sort.Strings(ordenados)
+	return strings.Join(ordenados, ", ")
+}
+
+func listaNivelesRespuesta(niveles []guia.NivelRespuesta) string {
+	partes := make([]string, 0, len(niveles))
+	for _, n := range niveles {
+		partes = append(partes, fmt.Sprintf("%s (%d): %s", n.Nombre, n.Puntos, n.Descripcion))
+	}
+	return strings.Join(partes, " | ")
+}
+
+func listaRangos(niveles []guia.NivelSostenibilidad) string {
+	partes := make([]string, 0, len(niveles))
+	for _, n := range niveles {
+		parte := fmt.Sprintf("%s %d-%d", n.Nombre, n.MinPuntaje, n.MaxPuntaje)
+		if len(n.Requisitos) > 0 {
+			requisitos := make([]string, 0, len(n.Requisitos))
+			for _, req := range n.Requisitos {
+				requisitos = append(requisitos, fmt.Sprintf("%s ≥ %v%%", req.Capitulo, req.MinPorcentaje))
+			}
+			sort.Strings(requisitos)
+			parte += " [" + strings.Join(requisitos, ", ") + "]"
+		}
+		partes = append(partes, parte)
+	}
+	return strings.Join(partes, ", ")
+}
+
+// mesesVigenciaNivel traduce la vigencia del documento, donde 0 (omitida) usa la vigencia
+// por defecto
+func mesesVigenciaNivel(n guia.NivelSostenibilidad) *int {
+	if n.MesesVigencia == 0 {
+		return nil
+	}
+	meses := n.MesesVigencia
+	return &meses
+}
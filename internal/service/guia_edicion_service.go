@@ -0,0 +1,785 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
+)
+
+// GuiaEdicionService permite consultar el contenido de una versión de la guía y
+// editar el cuestionario de la versión en borrador. Las versiones publicadas y
+// archivadas no se modifican: retirar un elemento del borrador lo elimina solo de
+// esa versión, las anteriores lo conservan.
+//
+// Cada cambio en capítulos, indicadores o niveles de respuesta invalida la
+// estructura de la versión guardada en estructuraCache.
+type GuiaEdicionService struct {
+	guiaVersionRepo    repository.GuiaVersionRepository
+	capituloRepo       repository.CapituloRepository
+	indicadorRepo      repository.IndicadorRepository
+	nivelRespuestaRepo repository.NivelRespuestaRepository
+	segmentoRepo       repository.SegmentoRepository
+	txManager          repository.TransactionManager
+	estructuraCache    *EstructuraCache
+}
+
+func NewGuiaEdicionService(
+	guiaVersionRepo repository.GuiaVersionRepository,
+	capituloRepo repository.CapituloRepository,
+	indicadorRepo repository.IndicadorRepository,
+	nivelRespuestaRepo repository.NivelRespuestaRepository,
+	segmentoRepo repository.SegmentoRepository,
+	txManager repository.TransactionManager,
+	estructuraCache *EstructuraCache,
+) *GuiaEdicionService {
+	return &GuiaEdicionService{
+		guiaVersionRepo:    guiaVersionRepo,
+		capituloRepo:       capituloRepo,
+		indicadorRepo:      indicadorRepo,
+		nivelRespuestaRepo: nivelRespuestaRepo,
+		segmentoRepo:       segmentoRepo,
+		txManager:          txManager,
+		estructuraCache:    estructuraCache,
+	}
+}
+
+// GetContenido devuelve el cuestionario completo de una versión
+func (s *GuiaEdicionService) GetContenido(ctx context.Context, idGuiaVersion int) (*domain.GuiaVersionContenido, error) {
+	version, err := s.guiaVersionRepo.FindByID(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	contenido := &domain.GuiaVersionContenido{
+		Version:   version,
+		Capitulos: make([]*domain.CapituloContenido, 0, len(capitulos)),
+	}
+	for _, cap := range capitulos {
+		indicadores, err := s.indicadorRepo.FindByCapitulo(ctx, cap.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		capContenido := &domain.CapituloContenido{
+			Capitulo:    cap,
+			Indicadores: make([]*domain.IndicadorContenido, 0, len(indicadores)),
+		}
+		for _, ind := range indicadores {
+			niveles, err := s.nivelRespuestaRepo.FindByIndicador(ctx, ind.ID)
+			if err != nil {
+				return nil, err
+			}
+			if niveles == nil {
+				niveles = []*domain.NivelRespuesta{}
+			}
+			segmentos, err := s.indicadorRepo.FindSegmentos(ctx, ind.ID)
+			if err != nil {
+				return nil, err
+			}
+			capContenido.Indicadores = append(capContenido.Indicadores, &domain.IndicadorContenido{
+				Indicador:        ind,
+				NivelesRespuesta: niveles,
+				Segmentos:        segmentos,
+			})
+		}
+		contenido.Capitulos = append(contenido.Capitulos, capContenido)
+	}
+
+	contenido.NivelesSostenibilidad, err = s.segmentoRepo.FindNivelesSostenibilidadByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return contenido, nil
+}
+
+// ============================================
+// CAPÍTULOS
+// ============================================
+
+func (s *GuiaEdicionService) CrearCapitulo(ctx context.Context, idGuiaVersion int, req *domain.CapituloRequest) (*domain.Capitulo, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if err := validarNombreDescripcion(req.Nombre, req.Descripcion); err != nil {
+		return nil, err
+	}
+	peso, err := pesoSolicitado(req.Peso, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	clave := strings.TrimSpace(req.Clave)
+	orden := 0
+	for _, c := range capitulos {
+		if clave != "" && c.Clave == clave {
+			return nil, validator.ValidationErrors{{Field: "clave", Message: "ya existe un capítulo con esa clave en la versión"}}
+		}
+		if c.Orden > orden {
+			orden = c.Orden
+		}
+	}
+
+	capitulo := &domain.Capitulo{
+		IDGuiaVersion: idGuiaVersion,
+		Clave:         clave,
+		Nombre:        strings.TrimSpace(req.Nombre),
+		Descripcion:   strings.TrimSpace(req.Descripcion),
+		Orden:         orden + 1,
+		Peso:          peso,
+	}
+	if err := s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		_, err := s.capituloRepo.Create(ctx, tx, capitulo)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return capitulo, nil
+}
+
+func (s *GuiaEdicionService) ModificarCapitulo(ctx context.Context, idGuiaVersion, idCapitulo int, req *domain.CapituloRequest) (*domain.Capitulo, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	capitulo, err := s.capituloDeVersion(ctx, idGuiaVersion, idCapitulo)
+	if err != nil {
+		return nil, err
+	}
+	if err := validarNombreDescripcion(req.Nombre, req.Descripcion); err != nil {
+		return nil, err
+	}
+
+	capitulo.Peso, err = pesoSolicitado(req.Peso, capitulo.Peso)
+	if err != nil {
+		return nil, err
+	}
+
+	capitulo.Nombre = strings.TrimSpace(req.Nombre)
+	capitulo.Descripcion = strings.TrimSpace(req.Descripcion)
+	if err := s.capituloRepo.Update(ctx, nil, capitulo); err != nil {
+		return nil, err
+	}
+
+	return capitulo, nil
+}
+
+// ReordenarCapitulos asigna el orden de los capítulos según la lista de IDs recibida
+func (s *GuiaEdicionService) ReordenarCapitulos(ctx context.Context, idGuiaVersion int, ids []int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return err
+	}
+	actuales := make([]int, 0, len(capitulos))
+	for _, c := range capitulos {
+		actuales = append(actuales, c.ID)
+	}
+	if err := validarOrden(ids, actuales); err != nil {
+		return err
+	}
+
+	return s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		for i, id := range ids {
+			if err := s.capituloRepo.UpdateOrden(ctx, tx, id, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RetirarCapitulo elimina el capítulo del borrador junto con sus indicadores
+func (s *GuiaEdicionService) RetirarCapitulo(ctx context.Context, idGuiaVersion, idCapitulo int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.capituloDeVersion(ctx, idGuiaVersion, idCapitulo); err != nil {
+		return err
+	}
+
+	return s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		return s.capituloRepo.Delete(ctx, tx, idCapitulo)
+	})
+}
+
+// ============================================
+// INDICADORES
+// ============================================
+
+func (s *GuiaEdicionService) CrearIndicador(ctx context.Context, idGuiaVersion, idCapitulo int, req *domain.IndicadorRequest) (*domain.Indicador, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.capituloDeVersion(ctx, idGuiaVersion, idCapitulo); err != nil {
+		return nil, err
+	}
+	if err := validarNombreDescripcion(req.Nombre, req.Descripcion); err != nil {
+		return nil, err
+	}
+
+	peso, err := pesoSolicitado(req.Peso, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	clave := strings.TrimSpace(req.Clave)
+	if clave != "" {
+		existentes, err := s.indicadorRepo.FindByVersion(ctx, idGuiaVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, ind := range existentes {
+			if ind.Clave == clave {
+				return nil, validator.ValidationErrors{{Field: "clave", Message: "ya existe un indicador con esa clave en la versión"}}
+			}
+		}
+	}
+
+	indicadores, err := s.indicadorRepo.FindByCapitulo(ctx, idCapitulo)
+	if err != nil {
+		return nil, err
+	}
+
+	indicador := &domain.Indicador{
+		IDCapitulo:  idCapitulo,
+		Clave:       clave,
+		Nombre:      strings.TrimSpace(req.Nombre),
+		Descripcion: strings.TrimSpace(req.Descripcion),
+		Orden:       siguienteOrdenIndicador(indicadores),
+		Peso:        peso,
+	}
+	if err := s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		_, err := s.indicadorRepo.Create(ctx, tx, indicador)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return indicador, nil
+}
+
+// ModificarIndicador actualiza nombre y descripción; si cambia id_capitulo, el indicador
+// se mueve al final del capítulo indicado (que debe ser de la misma versión)
+func (s *GuiaEdicionService) ModificarIndicador(ctx context.Context, idGuiaVersion, idIndicador int, req *domain.IndicadorRequest) (*domain.Indicador, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	indicador, err := s.indicadorDeVersion(ctx, idGuiaVersion, idIndicador)
+	if err != nil {
+		return nil, err
+	}
+	if err := validarNombreDescripcion(req.Nombre, req.Descripcion); err != nil {
+		return nil, err
+	}
+
+	if req.IDCapitulo != 0 && req.IDCapitulo != indicador.IDCapitulo {
+		if _, err := s.capituloDeVersion(ctx, idGuiaVersion, req.IDCapitulo); err != nil {
+			return nil, err
+		}
+		destino, err := s.indicadorRepo.FindByCapitulo(ctx, req.IDCapitulo)
+		if err != nil {
+			return nil, err
+		}
+		indicador.IDCapitulo = req.IDCapitulo
+		indicador.Orden = siguienteOrdenIndicador(destino)
+	}
+
+	indicador.Peso, err = pesoSolicitado(req.Peso, indicador.Peso)
+	if err != nil {
+		return nil, err
+	}
+
+	indicador.Nombre = strings.TrimSpace(req.Nombre)
+	indicador.Descripcion = strings.TrimSpace(req.Descripcion)
+	if err := s.indicadorRepo.Update(ctx, nil, indicador); err != nil {
+		return nil, err
+	}
+
+	return indicador, nil
+}
+
+// ReordenarIndicadores asigna el orden de los indicadores de un capítulo
+func (s *GuiaEdicionService) ReordenarIndicadores(ctx context.Context, idGuiaVersion, idCapitulo int, ids []int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.capituloDeVersion(ctx, idGuiaVersion, idCapitulo); err != nil {
+		return err
+	}
+
+	indicadores, err := s.indicadorRepo.FindByCapitulo(ctx, idCapitulo)
+	if err != nil {
+		return err
+	}
+	actuales := make([]int, 0, len(indicadores))
+	for _, ind := range indicadores {
+		actuales = append(actuales, ind.ID)
+	}
+	if err := validarOrden(ids, actuales); err != nil {
+		return err
+	}
+
+	return s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		for i, id := range ids {
+			if err := s.indicadorRepo.UpdateOrden(ctx, tx, id, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RetirarIndicador elimina el indicador del borrador junto con sus niveles de respuesta
+func (s *GuiaEdicionService) RetirarIndicador(ctx context.Context, idGuiaVersion, idIndicador int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.indicadorDeVersion(ctx, idGuiaVersion, idIndicador); err != nil {
+		return err
+	}
+
+	return s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		return s.indicadorRepo.Delete(ctx, tx, idIndicador)
+	})
+}
+
+// AsignarSegmentos define para qué segmentos aplica el indicador
+func (s *GuiaEdicionService) AsignarSegmentos(ctx context.Context, idGuiaVersion, idIndicador int, segmentos []int) ([]int, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.indicadorDeVersion(ctx, idGuiaVersion, idIndicador); err != nil {
+		return nil, err
+	}
+
+	unicos := make([]int, 0, len(segmentos))
+	vistos := make(map[int]bool, len(segmentos))
+	for _, idSegmento := range segmentos {
+		if vistos[idSegmento] {
+			continue
+		}
+		if _, err := s.segmentoRepo.FindByID(ctx, idSegmento); err != nil {
+			if err == domain.ErrNotFound {
+				return nil, validator.ValidationErrors{{Field: "segmentos", Message: fmt.Sprintf("el segmento %d no existe", idSegmento)}}
+			}
+			return nil, err
+		}
+		vistos[idSegmento] = true
+		unicos = append(unicos, idSegmento)
+	}
+	sort.Ints(unicos)
+
+	if err := s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		return s.indicadorRepo.ReplaceSegmentos(ctx, tx, idIndicador, unicos)
+	}); err != nil {
+		return nil, err
+	}
+
+	return unicos, nil
+}
+
+// ============================================
+// NIVELES DE RESPUESTA
+// ============================================
+
+func (s *GuiaEdicionService) CrearNivelRespuesta(ctx context.Context, idGuiaVersion, idIndicador int, req *domain.NivelRespuestaRequest) (*domain.NivelRespuesta, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.indicadorDeVersion(ctx, idGuiaVersion, idIndicador); err != nil {
+		return nil, err
+	}
+	if err := validarNivelRespuesta(req); err != nil {
+		return nil, err
+	}
+
+	niveles, err := s.nivelRespuestaRepo.FindByIndicador(ctx, idIndicador)
+	if err != nil {
+		return nil, err
+	}
+	posicion := 0
+	for _, n := range niveles {
+		if n.Posicion > posicion {
+			posicion = n.Posicion
+		}
+	}
+
+	nivel := &domain.NivelRespuesta{
+		IDIndicador: idIndicador,
+		Nombre:      strings.TrimSpace(req.Nombre),
+		Descripcion: strings.TrimSpace(req.Descripcion),
+		Puntos:      req.Puntos,
+		Posicion:    posicion + 1,
+	}
+	if _, err := s.nivelRespuestaRepo.Create(ctx, nil, nivel); err != nil {
+		return nil, err
+	}
+
+	return nivel, nil
+}
+
+func (s *GuiaEdicionService) ModificarNivelRespuesta(ctx context.Context, idGuiaVersion, idNivelRespuesta int, req *domain.NivelRespuestaRequest) (*domain.NivelRespuesta, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	nivel, err := s.nivelRespuestaDeVersion(ctx, idGuiaVersion, idNivelRespuesta)
+	if err != nil {
+		return nil, err
+	}
+	if err := validarNivelRespuesta(req); err != nil {
+		return nil, err
+	}
+
+	nivel.Nombre = strings.TrimSpace(req.Nombre)
+	nivel.Descripcion = strings.TrimSpace(req.Descripcion)
+	nivel.Puntos = req.Puntos
+	if err := s.nivelRespuestaRepo.Update(ctx, nil, nivel); err != nil {
+		return nil, err
+	}
+
+	return nivel, nil
+}
+
+// ReordenarNivelesRespuesta asigna la posición de los niveles de respuesta de un indicador
+func (s *GuiaEdicionService) ReordenarNivelesRespuesta(ctx context.Context, idGuiaVersion, idIndicador int, ids []int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.indicadorDeVersion(ctx, idGuiaVersion, idIndicador); err != nil {
+		return err
+	}
+
+	niveles, err := s.nivelRespuestaRepo.FindByIndicador(ctx, idIndicador)
+	if err != nil {
+		return err
+	}
+	actuales := make([]int, 0, len(niveles))
+	for _, n := range niveles {
+		actuales = append(actuales, n.ID)
+	}
+	if err := validarOrden(ids, actuales); err != nil {
+		return err
+	}
+
+	return s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		for i, id := range ids {
+			if err := s.nivelRespuestaRepo.UpdatePosicion(ctx, tx, id, i+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *GuiaEdicionService) RetirarNivelRespuesta(ctx context.Context, idGuiaVersion, idNivelRespuesta int) error {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return err
+	}
+	defer s.estructuraCache.Invalidar(idGuiaVersion)
+	if _, err := s.nivelRespuestaDeVersion(ctx, idGuiaVersion, idNivelRespuesta); err != nil {
+		return err
+	}
+
+	return s.nivelRespuestaRepo.Delete(ctx, nil, idNivelRespuesta)
+}
+
+// ============================================
+// NIVELES DE SOSTENIBILIDAD
+// ============================================
+
+// ReemplazarNivelesSostenibilidad reemplaza los rangos de puntaje de un segmento.
+// Los rangos deben ser contiguos y no superponerse (el mínimo de cada nivel es el
+// máximo del anterior más uno), empezando en 0.
+func (s *GuiaEdicionService) ReemplazarNivelesSostenibilidad(ctx context.Context, idGuiaVersion, idSegmento int, niveles []*domain.NivelSostenibilidad) ([]*domain.NivelSostenibilidad, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	if _, err := s.segmentoRepo.FindByID(ctx, idSegmento); err != nil {
+		return nil, err
+	}
+
+	for i, n := range niveles {
+		if n == nil {
+			return nil, validator.ValidationErrors{{Field: fmt.Sprintf("niveles[%d]", i), Message: "nivel vacío"}}
+		}
+		n.Nombre = strings.TrimSpace(n.Nombre)
+	}
+	sort.SliceStable(niveles, func(i, j int) bool { return niveles[i].MinPuntaje < niveles[j].MinPuntaje })
+	if err := ValidarRangosNiveles(niveles); err != nil {
+		return nil, err
+	}
+
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, idGuiaVersion)
+	if err != nil {
+		return nil, err
+	}
+	idsCapitulo := make(map[int]bool, len(capitulos))
+	for _, c := range capitulos {
+		idsCapitulo[c.ID] = true
+	}
+	if err := validarRequisitos(niveles, idsCapitulo); err != nil {
+		return nil, err
+	}
+
+	if err := s.enTransaccion(ctx, func(tx repository.Transaction) error {
+		return s.segmentoRepo.ReplaceNivelesSostenibilidad(ctx, tx, idSegmento, idGuiaVersion, niveles)
+	}); err != nil {
+		return nil, err
+	}
+
+	return niveles, nil
+}
+
+// ValidarRangosNiveles verifica que los niveles (ordenados por puntaje mínimo) cubran
+// rangos contiguos y sin superposición a partir de 0
+func ValidarRangosNiveles(niveles []*domain.NivelSostenibilidad) error {
+	if len(niveles) == 0 {
+		return validator.ValidationErrors{{Field: "niveles", Message: "debe haber al menos un nivel"}}
+	}
+
+	var errs validator.ValidationErrors
+	for i, n := range niveles {
+		campo := fmt.Sprintf("niveles[%d]", i)
+		if n.Nombre == "" {
+			errs = append(errs, validator.ValidationError{Field: campo + ".nombre", Message: "el nombre no puede estar vacío"})
+		}
+		if n.MaxPuntaje < n.MinPuntaje {
+			errs = append(errs, validator.ValidationError{Field: campo, Message: "el puntaje máximo es menor al mínimo"})
+		}
+		if n.MesesVigencia != nil && *n.MesesVigencia <= 0 {
+			errs = append(errs, validator.ValidationError{Field: campo + ".meses_vigencia", Message: "la vigencia debe ser de al menos un mes"})
+		}
+		if i == 0 {
+			if n.MinPuntaje != 0 {
+				errs = append(errs, validator.ValidationError{Field: campo + ".min_puntaje", Message: "el primer nivel debe empezar en 0"})
+			}
+			continue
+		}
+		anterior := niveles[i-1]
+		switch {
+		case n.MinPuntaje <= anterior.MaxPuntaje:
+			errs = append(errs, validator.ValidationError{Field: campo, Message: fmt.Sprintf("se superpone con el nivel %q", anterior.Nombre)})
+		case n.MinPuntaje > anterior.MaxPuntaje+1:
+			errs = append(errs, validator.ValidationError{Field: campo, Message: fmt.Sprintf("deja puntajes sin nivel después de %q", anterior.Nombre)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validarRequisitos verifica que los porcentajes mínimos por capítulo de cada nivel
+// refieran a capítulos de la versión y estén entre 0 y 100
+func validarRequisitos(niveles []*domain.NivelSostenibilidad, idsCapitulo map[int]bool) error {
+	var errs validator.ValidationErrors
+	for i, n := range niveles {
+		if n.Requisitos == nil {
+			n.Requisitos = []*domain.RequisitoCapitulo{}
+		}
+		vistos := make(map[int]bool)
+		for j, req := range n.Requisitos {
+			campo := fmt.Sprintf("niveles[%d].requisitos[%d]", i, j)
+			switch {
+			case req == nil:
+				errs = append(errs, validator.ValidationError{Field: campo, Message: "requisito vacío"})
+			case !idsCapitulo[req.IDCapitulo]:
+				errs = append(errs, validator.ValidationError{Field: campo + ".id_capitulo", Message: "el capítulo no pertenece a la versión"})
+			case vistos[req.IDCapitulo]:
+				errs = append(errs, validator.ValidationError{Field: campo + ".id_capitulo", Message: "capítulo repetido"})
+			case req.MinPorcentaje < 0 || req.MinPorcentaje > 100:
+				errs = append(errs, validator.ValidationError{Field: campo + ".min_porcentaje", Message: "debe estar entre 0 y 100"})
+			}
+			if req != nil {
+				vistos[req.IDCapitulo] = true
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ============================================
+// ESTRATEGIA DE PUNTAJE
+// ============================================
+
+// CambiarEstrategiaPuntaje define cómo se calculará el puntaje de las autoevaluaciones
+// que usen la versión
+func (s *GuiaEdicionService) CambiarEstrategiaPuntaje(ctx context.Context, idGuiaVersion int, estrategia domain.EstrategiaPuntaje) (*domain.GuiaVersion, error) {
+	if err := s.verificarBorrador(ctx, idGuiaVersion); err != nil {
+		return nil, err
+	}
+	if _, ok := estrategiasPuntaje[estrategia]; !ok {
+		return nil, validator.ValidationErrors{{Field: "estrategia_puntaje", Message: "estrategia inválida: se esperaba SUMA o PONDERADA"}}
+	}
+
+	if err := s.guiaVersionRepo.UpdateEstrategia(ctx, nil, idGuiaVersion, estrategia); err != nil {
+		return nil, err
+	}
+
+	return s.guiaVersionRepo.FindByID(ctx, idGuiaVersion)
+}
+
+// ============================================
+// HELPERS
+// ============================================
+
+func (s *GuiaEdicionService) verificarBorrador(ctx context.Context, idGuiaVersion int) error {
+	version, err := s.guiaVersionRepo.FindByID(ctx, idGuiaVersion)
+	if err != nil {
+		return err
+	}
+	if version.Estado != domain.GuiaVersionBorrador {
+		return domain.ErrGuiaVersionNoEditable
+	}
+	return nil
+}
+
+// capituloDeVersion busca el capítulo y verifica que pertenezca a la versión indicada
+func (s *GuiaEdicionService) capituloDeVersion(ctx context.Context, idGuiaVersion, idCapitulo int) (*domain.Capitulo, error) {
+	capitulo, err := s.capituloRepo.FindByID(ctx, idCapitulo)
+	if err != nil {
+		return nil, err
+	}
+	if capitulo.IDGuiaVersion != idGuiaVersion {
+		return nil, domain.ErrNotFound
+	}
+	return capitulo, nil
+}
+
+func (s *GuiaEdicionService) indicadorDeVersion(ctx context.Context, idGuiaVersion, idIndicador int) (*domain.Indicador, error) {
+	indicador, err := s.indicadorRepo.FindByID(ctx, idIndicador)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.capituloDeVersion(ctx, idGuiaVersion, indicador.IDCapitulo); err != nil {
+		return nil, err
+	}
+	return indicador, nil
+}
+
+func (s *GuiaEdicionService) nivelRespuestaDeVersion(ctx context.Context, idGuiaVersion, idNivelRespuesta int) (*domain.NivelRespuesta, error) {
+	nivel, err := s.nivelRespuestaRepo.FindByID(ctx, idNivelRespuesta)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.indicadorDeVersion(ctx, idGuiaVersion, nivel.IDIndicador); err != nil {
+		return nil, err
+	}
+	return nivel, nil
+}
+
+func (s *GuiaEdicionService) enTransaccion(ctx context.Context, fn func(tx repository.Transaction) error) error {
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nil
+}
+
+func siguienteOrdenIndicador(indicadores []*domain.Indicador) int {
+	orden := 0
+	for _, ind := range indicadores {
+		if ind.Orden > orden {
+			orden = ind.Orden
+		}
+	}
+	return orden + 1
+}
+
+// validarOrden verifica que la lista de IDs sea una permutación de los elementos actuales
+func validarOrden(ids, actuales []int) error {
+	if len(ids) != len(actuales) {
+		return validator.ValidationErrors{{Field: "ids", Message: "debe incluir todos los elementos exactamente una vez"}}
+	}
+	pendientes := make(map[int]bool, len(actuales))
+	for _, id := range actuales {
+		pendientes[id] = true
+	}
+	for _, id := range ids {
+		if !pendientes[id] {
+			return validator.ValidationErrors{{Field: "ids", Message: fmt.Sprintf("el elemento %d no corresponde o está repetido", id)}}
+		}
+		delete(pendientes, id)
+	}
+	return nil
+}
+
+func validarNombreDescripcion(nombre, descripcion string) error {
+	var errs validator.ValidationErrors
+	if err := validator.ValidateNotEmpty(nombre, "nombre"); err != nil {
+		errs = append(errs, validator.ValidationError{Field: "nombre", Message: err.Error()})
+	}
+	if err := validator.ValidateNotEmpty(descripcion, "descripción"); err != nil {
+		errs = append(errs, validator.ValidationError{Field: "descripcion", Message: err.Error()})
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// pesoSolicitado devuelve el peso recibido o el valor por defecto si no vino; debe ser positivo
+func pesoSolicitado(peso *float64, porDefecto float64) (float64, error) {
+	if peso == nil {
+		return porDefecto, nil
+	}
+	if *peso <= 0 {
+		return 0, validator.ValidationErrors{{Field: "peso", Message: "el peso debe ser mayor a 0"}}
+	}
+	return *peso, nil
+}
+
+func validarNivelRespuesta(req *domain.NivelRespuestaRequest) error {
+	if err := validarNombreDescripcion(req.Nombre, req.Descripcion); err != nil {
+		return err
+	}
+	if req.Puntos < 0 {
+		return validator.ValidationErrors{{Field: "puntos", Message: "los puntos no pueden ser negativos"}}
+	}
+	return nil
+}
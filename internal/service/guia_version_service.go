@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
+)
+
+// GuiaVersionService administra las versiones de la guía de autoevaluación.
+// Las autoevaluaciones nuevas usan la versión publicada; los cambios al cuestionario
+// se hacen sobre un borrador que, al publicarse, reemplaza a la versión vigente.
+type GuiaVersionService struct {
+	guiaVersionRepo repository.GuiaVersionRepository
+	capituloRepo    repository.CapituloRepository
+	txManager       repository.TransactionManager
+}
+
+func NewGuiaVersionService(guiaVersionRepo repository.GuiaVersionRepository, capituloRepo repository.CapituloRepository, txManager repository.TransactionManager) *GuiaVersionService {
+	return &GuiaVersionService{
+		guiaVersionRepo: guiaVersionRepo,
+		capituloRepo:    capituloRepo,
+		txManager:       txManager,
+	}
+}
+
+func (s *GuiaVersionService) GetVersiones(ctx context.Context) ([]*domain.GuiaVersion, error) {
+	return s.guiaVersionRepo.FindAll(ctx)
+}
+
+func (s *GuiaVersionService) GetVersionByID(ctx context.Context, id int) (*domain.GuiaVersion, error) {
+	return s.guiaVersionRepo.FindByID(ctx, id)
+}
+
+// CrearBorrador crea un borrador copiando la versión publicada. Solo puede haber un borrador a la vez.
+func (s *GuiaVersionService) CrearBorrador(ctx context.Context, descripcion string) (*domain.GuiaVersion, error) {
+	borrador, err := s.guiaVersionRepo.FindBorrador(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if borrador != nil {
+		return nil, domain.ErrGuiaBorradorExistente
+	}
+
+	publicada, err := s.guiaVersionRepo.FindPublicada(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	nueva, err := s.guiaVersionRepo.CrearBorrador(ctx, tx, publicada.ID, strings.TrimSpace(descripcion))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nueva, nil
+}
+
+// Publicar publica el borrador; la versión publicada anterior queda archivada.
+// Las autoevaluaciones ya iniciadas conservan la versión con la que empezaron.
+func (s *GuiaVersionService) Publicar(ctx context.Context, id int) (*domain.GuiaVersion, error) {
+	version, err := s.guiaVersionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version.Estado != domain.GuiaVersionBorrador {
+		return nil, domain.ErrGuiaVersionNoEditable
+	}
+
+	capitulos, err := s.capituloRepo.FindByVersion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(capitulos) == 0 {
+		return nil, validator.ValidationErrors{{Field: "capitulos", Message: "la versión no tiene capítulos"}}
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.guiaVersionRepo.Publicar(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return s.guiaVersionRepo.FindByID(ctx, id)
+}
+
+// DescartarBorrador elimina el borrador y todo su contenido
+func (s *GuiaVersionService) DescartarBorrador(ctx context.Context, id int) error {
+	version, err := s.guiaVersionRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if version.Estado != domain.GuiaVersionBorrador {
+		return domain.ErrGuiaVersionNoEditable
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := s.guiaVersionRepo.Delete(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error confirmando transacción: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/georef"
+)
+
+const (
+	tipoUbicacionProvincia    = "PROVINCIA"
+	tipoUbicacionDepartamento = "DEPARTAMENTO"
+	tipoUbicacionLocalidad    = "LOCALIDAD"
+)
+
+// catalogoRecargable es un catálogo en memoria que debe recargarse luego de importar
+type catalogoRecargable interface {
+	Recargar(ctx context.Context) error
+}
+
+type ImportacionUbicacionesService struct {
+	repo      repository.UbicacionRepository
+	txManager repository.TransactionManager
+	catalogo  catalogoRecargable // opcional
+}
+
+// NewImportacionUbicacionesService crea el servicio de importación. Si catalogo no es nil,
+// se recarga cada vez que se aplica una importación.
+func NewImportacionUbicacionesService(repo repository.UbicacionRepository, txManager repository.TransactionManager, catalogo catalogoRecargable) *ImportacionUbicacionesService {
+	return &ImportacionUbicacionesService{
+		repo:      repo,
+		txManager: txManager,
+		catalogo:  catalogo,
+	}
+}
+
+// importacion mantiene el estado de una importación en curso
+type importacion struct {
+	ctx     context.Context
+	tx      repository.Transaction
+	repo    repository.UbicacionRepository
+	aplicar bool
+	reporte *domain.ReporteImportacionUbicaciones
+
+	// IDs provisorios para las entidades nuevas cuando solo se previsualiza
+	siguienteIDProvisorio int
+}
+
+// ImportarGeoref actualiza el catálogo de ubicaciones a partir de los archivos oficiales.
+// Las entidades se identifican por su ID oficial; las que todavía no lo tienen se asocian
+// por nombre (sin tildes ni mayúsculas) dentro de su provincia o departamento.
+// Las localidades referenciadas por bodegas nunca se eliminan.
+// Si aplicar es false solo se calcula el reporte, sin modificar la base de datos.
+func (s *ImportacionUbicacionesService) ImportarGeoref(ctx context.Context, catalogo *georef.Catalogo, aplicar bool) (*domain.ReporteImportacionUbicaciones, error) {
+	if catalogo == nil || len(catalogo.Provincias) == 0 {
+		return nil, fmt.Errorf("el catálogo a importar no contiene provincias")
+	}
+
+	provincias, err := s.repo.GetProvincias(ctx)
+	if err != nil {
+		return nil, err
+	}
+	departamentos, err := s.repo.GetDepartamentos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	localidades, err := s.repo.GetLocalidades(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.txManager.BeginTx(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error iniciando transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Se lee dentro de la transacción, con las localidades bloqueadas, para no eliminar
+	// una que una bodega empiece a usar mientras dura la importación
+	enUsoIDs, err := s.repo.GetLocalidadesEnUso(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	enUso := make(map[int]bool, len(enUsoIDs))
+	for _, id := range enUsoIDs {
+		enUso[id] = true
+	}
+
+	imp := &importacion{
+		ctx:     ctx,
+		tx:      tx,
+		repo:    s.repo,
+		aplicar: aplicar,
+		reporte: &domain.ReporteImportacionUbicaciones{
+			Agregadas:   make([]domain.CambioUbicacion, 0),
+			Renombradas: make([]domain.CambioUbicacion, 0),
+			Eliminadas:  make([]domain.CambioUbicacion, 0),
+			Conservadas: make([]domain.CambioUbicacion, 0),
+			Omitidas:    make([]domain.CambioUbicacion, 0),
+		},
+	}
+
+	provinciasPorGeoref, provinciasSobrantes, err := imp.importarProvincias(catalogo.Provincias, provincias)
+	if err != nil {
+		return nil, err
+	}
+	departamentosPorGeoref, departamentosSobrantes, err := imp.importarDepartamentos(catalogo.Departamentos, departamentos, provinciasPorGeoref)
+	if err != nil {
+		return nil, err
+	}
+	localidadesVigentes, localidadesSobrantes, err := imp.importarLocalidades(catalogo.Localidades, localidades, departamentosPorGeoref)
+	if err != nil {
+		return nil, err
+	}
+
+	// Eliminar de abajo hacia arriba, conservando lo que sigue referenciado
+	departamentosConLocalidades := make(map[int]bool)
+	for _, loc := range localidadesVigentes {
+		departamentosConLocalidades[loc.IDDepartamento] = true
+	}
+	for _, loc := range localidadesSobrantes {
+		if enUso[loc.ID] {
+			departamentosConLocalidades[loc.IDDepartamento] = true
+			imp.conservar(tipoUbicacionLocalidad, loc.ID, loc.IDGeoref, loc.Nombre, "referenciada por bodegas")
+			continue
+		}
+		if err := imp.eliminar(tipoUbicacionLocalidad, loc.ID, loc.IDGeoref, loc.Nombre, imp.repo.DeleteLocalidad); err != nil {
+			return nil, err
+		}
+	}
+
+	provinciasConDepartamentos := make(map[int]bool)
+	for _, dep := range departamentosPorGeoref {
+		provinciasConDepartamentos[dep.IDProvincia] = true
+	}
+	for _, dep := range departamentosSobrantes {
+		if departamentosConLocalidades[dep.ID] {
+			provinciasConDepartamentos[dep.IDProvincia] = true
+			imp.conservar(tipoUbicacionDepartamento, dep.ID, dep.IDGeoref, dep.Nombre, "tiene localidades referenciadas por bodegas")
+			continue
+		}
+		if err := imp.eliminar(tipoUbicacionDepartamento, dep.ID, dep.IDGeoref, dep.Nombre, imp.repo.DeleteDepartamento); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, prov := range provinciasSobrantes {
+		if provinciasConDepartamentos[prov.ID] {
+			imp.conservar(tipoUbicacionProvincia, prov.ID, prov.IDGeoref, prov.Nombre, "tiene localidades referenciadas por bodegas")
+			continue
+		}
+		if err := imp.eliminar(tipoUbicacionProvincia, prov.ID, prov.IDGeoref, prov.Nombre, imp.repo.DeleteProvincia); err != nil {
+			return nil, err
+		}
+	}
+
+	if aplicar {
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("error confirmando transacción: %w", err)
+		}
+		if s.catalogo != nil {
+			if err := s.catalogo.Recargar(ctx); err != nil {
+				log.Printf("⚠️  Catálogo importado pero no se pudo recargar la caché: %v", err)
+			}
+		}
+	}
+
+	imp.reporte.Aplicado = aplicar
+	return imp.reporte, nil
+}
+
+func (imp *importacion) importarProvincias(oficiales []georef.Provincia, existentes []*domain.Provincia) (map[string]int, []*domain.Provincia, error) {
+	porGeoref := make(map[string]*domain.Provincia)
+	porNombre := make(map[string]*domain.Provincia)
+	for _, p := range existentes {
+		if p.IDGeoref != nil {
+			porGeoref[*p.IDGeoref] = p
+		} else {
+			porNombre[normalizarBusqueda(p.Nombre)] = p
+		}
+	}
+
+	ids := make(map[string]int)
+	vistas := make(map[int]bool)
+	for _, of := range oficiales {
+		if of.ID == "" || of.Nombre == "" {
+			imp.omitir(tipoUbicacionProvincia, of.ID, of.Nombre, "id o nombre vacío")
+			continue
+		}
+		if _, dup := ids[of.ID]; dup {
+			imp.omitir(tipoUbicacionProvincia, of.ID, of.Nombre, "id duplicado en el archivo")
+			continue
+		}
+
+		actual, ok := porGeoref[of.ID]
+		if !ok {
+			clave := normalizarBusqueda(of.Nombre)
+			if actual, ok = porNombre[clave]; ok {
+				delete(porNombre, clave)
+			}
+		}
+
+		idGeoref := of.ID
+		if !ok {
+			nueva := &domain.Provincia{Nombre: of.Nombre, IDGeoref: &idGeoref}
+			id, err := imp.crear(func() (int, error) { return imp.repo.CreateProvincia(imp.ctx, imp.tx, nueva) })
+			if err != nil {
+				return nil, nil, err
+			}
+			ids[of.ID] = id
+			imp.agregar(tipoUbicacionProvincia, id, of.ID, of.Nombre)
+			continue
+		}
+
+		ids[of.ID] = actual.ID
+		vistas[actual.ID] = true
+		if actual.Nombre == of.Nombre && actual.IDGeoref != nil && *actual.IDGeoref == of.ID {
+			continue
+		}
+
+		if actual.Nombre != of.Nombre {
+			imp.renombrar(tipoUbicacionProvincia, actual.ID, of.ID, of.Nombre, actual.Nombre)
+		}
+		actual.Nombre = of.Nombre
+		actual.IDGeoref = &idGeoref
+		if imp.aplicar {
+			if err := imp.repo.UpdateProvincia(imp.ctx, imp.tx, actual); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	sobrantes := make([]*domain.Provincia, 0)
+	for _, p := range existentes {
+		if !vistas[p.ID] {
+			sobrantes = append(sobrantes, p)
+		}
+	}
+	return ids, sobrantes, nil
+}
+
+func (imp *importacion) importarDepartamentos(oficiales []georef.Departamento, existentes []*domain.Departamento, provincias map[string]int) (map[string]*domain.Departamento, []*domain.Departamento, error) {
+	porGeoref := make(map[string]*domain.Departamento)
+	porNombre := make(map[string]*domain.Departamento)
+	for _, d := range existentes {
+		if d.IDGeoref != nil {
+			porGeoref[*d.IDGeoref] = d
+		} else {
+			porNombre[claveUbicacion(d.IDProvincia, d.Nombre)] = d
+		}
+	}
+
+	vigentes := make(map[string]*domain.Departamento)
+	vistos := make(map[int]bool)
+	for _, of := range oficiales {
+		if of.ID == "" || of.Nombre == "" {
+			imp.omitir(tipoUbicacionDepartamento, of.ID, of.Nombre, "id o nombre vacío")
+			continue
+		}
+		if _, dup := vigentes[of.ID]; dup {
+			imp.omitir(tipoUbicacionDepartamento, of.ID, of.Nombre, "id duplicado en el archivo")
+			continue
+		}
+		idProvincia, ok := provincias[of.IDProvincia]
+		if !ok {
+			imp.omitir(tipoUbicacionDepartamento, of.ID, of.Nombre, "provincia inexistente: "+of.IDProvincia)
+			continue
+		}
+
+		actual, ok := porGeoref[of.ID]
+		if !ok {
+			clave := claveUbicacion(idProvincia, of.Nombre)
+			if actual, ok = porNombre[clave]; ok {
+				delete(porNombre, clave)
+			}
+		}
+
+		idGeoref := of.ID
+		if !ok {
+			nuevo := &domain.Departamento{IDProvincia: idProvincia, Nombre: of.Nombre, IDGeoref: &idGeoref}
+			id, err := imp.crear(func() (int, error) { return imp.repo.CreateDepartamento(imp.ctx, imp.tx, nuevo) })
+			if err != nil {
+				return nil, nil, err
+			}
+			nuevo.ID = id
+			vigentes[of.ID] = nuevo
+			imp.agregar(tipoUbicacionDepartamento, id, of.ID, of.Nombre)
+			continue
+		}
+
+		vigentes[of.ID] = actual
+		vistos[actual.ID] = true
+		if actual.Nombre == of.Nombre && actual.IDProvincia == idProvincia && actual.IDGeoref != nil && *actual.IDGeoref == of.ID {
+			continue
+		}
+
+		if actual.Nombre != of.Nombre {
+			imp.renombrar(tipoUbicacionDepartamento, actual.ID, of.ID, of.Nombre, actual.Nombre)
+		}
+		actual.Nombre = of.Nombre
+		actual.IDProvincia = idProvincia
+		actual.IDGeoref = &idGeoref
+		if imp.aplicar {
+			if err := imp.repo.UpdateDepartamento(imp.ctx, imp.tx, actual); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	sobrantes := make([]*domain.Departamento, 0)
+	for _, d := range existentes {
+		if !vistos[d.ID] {
+			sobrantes = append(sobrantes, d)
+		}
+	}
+	return vigentes, sobrantes, nil
+}
+
+func (imp *importacion) importarLocalidades(oficiales []georef.Localidad, existentes []*domain.Localidad, departamentos map[string]*domain.Departamento) ([]*domain.Localidad, []*domain.Localidad, error) {
+	porGeoref := make(map[string]*domain.Localidad)
+	porNombre := make(map[string]*domain.Localidad)
+	for _, l := range existentes {
+		if l.IDGeoref != nil {
+			porGeoref[*l.IDGeoref] = l
+		} else {
+			porNombre[claveUbicacion(l.IDDepartamento, l.Nombre)] = l
+		}
+	}
+
+	vigentes := make([]*domain.Localidad, 0, len(oficiales))
+	importadas := make(map[string]bool)
+	vistas := make(map[int]bool)
+	for _, of := range oficiales {
+		if of.ID == "" || of.Nombre == "" {
+			imp.omitir(tipoUbicacionLocalidad, of.ID, of.Nombre, "id o nombre vacío")
+			continue
+		}
+		if importadas[of.ID] {
+			imp.omitir(tipoUbicacionLocalidad, of.ID, of.Nombre, "id duplicado en el archivo")
+			continue
+		}
+		departamento, ok := departamentos[of.IDDepartamento]
+		if !ok {
+			imp.omitir(tipoUbicacionLocalidad, of.ID, of.Nombre, "departamento inexistente: "+of.IDDepartamento)
+			continue
+		}
+		importadas[of.ID] = true
+
+		actual, ok := porGeoref[of.ID]
+		if !ok {
+			clave := claveUbicacion(departamento.ID, of.Nombre)
+			if actual, ok = porNombre[clave]; ok {
+				delete(porNombre, clave)
+			}
+		}
+
+		idGeoref := of.ID
+		if !ok {
+			nueva := &domain.Localidad{IDDepartamento: departamento.ID, Nombre: of.Nombre, IDGeoref: &idGeoref}
+			id, err := imp.crear(func() (int, error) { return imp.repo.CreateLocalidad(imp.ctx, imp.tx, nueva) })
+			if err != nil {
+				return nil, nil, err
+			}
+			nueva.ID = id
+			vigentes = append(vigentes, nueva)
+			imp.agregar(tipoUbicacionLocalidad, id, of.ID, of.Nombre)
+			continue
+		}
+
+		vigentes = append(vigentes, actual)
+		vistas[actual.ID] = true
+		if actual.Nombre == of.Nombre && actual.IDDepartamento == departamento.ID && actual.IDGeoref != nil && *actual.IDGeoref == of.ID {
+			continue
+		}
+
+		if actual.Nombre != of.Nombre {
+			imp.renombrar(tipoUbicacionLocalidad, actual.ID, of.ID, of.Nombre, actual.Nombre)
+		}
+		actual.Nombre = of.Nombre
+		actual.IDDepartamento = departamento.ID
+		actual.IDGeoref = &idGeoref
+		if imp.aplicar {
+			if err := imp.repo.UpdateLocalidad(imp.ctx, imp.tx, actual); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	sobrantes := make([]*domain.Localidad, 0)
+	for _, l := range existentes {
+		if !vistas[l.ID] {
+			sobrantes = append(sobrantes, l)
+		}
+	}
+	return vigentes, sobrantes, nil
+}
+
+// crear ejecuta la inserción solo si se está aplicando la importación;
+// en modo previsualización devuelve un ID provisorio negativo
+func (imp *importacion) crear(insertar func() (int, error)) (int, error) {
+	if !imp.aplicar {
+		imp.siguienteIDProvisorio--
+		return imp.siguienteIDProvisorio, nil
+	}
+	return insertar()
+}
+
+func (imp *importacion) eliminar(tipo string, id int, idGeoref *string, nombre string, borrar func(context.Context, repository.Transaction, int) error) error {
+	if imp.aplicar {
+		if err := borrar(imp.ctx, imp.tx, id); err != nil {
+			return err
+		}
+	}
+	imp.reporte.Eliminadas = append(imp.reporte.Eliminadas, domain.CambioUbicacion{
+		Tipo: tipo, ID: id, IDGeoref: valorOVacio(idGeoref), Nombre: nombre,
+	})
+	return nil
+}
+
+func (imp *importacion) agregar(tipo string, id int, idGeoref, nombre string) {
+	cambio := domain.CambioUbicacion{Tipo: tipo, IDGeoref: idGeoref, Nombre: nombre}
+	if id > 0 {
+		cambio.ID = id
+	}
+	imp.reporte.Agregadas = append(imp.reporte.Agregadas, cambio)
+}
+
+func (imp *importacion) renombrar(tipo string, id int, idGeoref, nombre, nombreAnterior string) {
+	imp.reporte.Renombradas = append(imp.reporte.Renombradas, domain.CambioUbicacion{
+		Tipo: tipo, ID: id, IDGeoref: idGeoref, Nombre: nombre, NombreAnterior: nombreAnterior,
+	})
+}
+
+func (imp *importacion) conservar(tipo string, id int, idGeoref *string, nombre, motivo string) {
+	imp.reporte.Conservadas = append(imp.reporte.Conservadas, domain.CambioUbicacion{
+		Tipo: tipo, ID: id, IDGeoref: valorOVacio(idGeoref), Nombre: nombre, Motivo: motivo,
+	})
+}
+
+func (imp *importacion) omitir(tipo string, idGeoref, nombre, motivo string) {
+	imp.reporte.Omitidas = append(imp.reporte.Omitidas, domain.CambioUbicacion{
+		Tipo: tipo, IDGeoref: idGeoref, Nombre: nombre, Motivo: motivo,
+	})
+}
+
+// claveUbicacion identifica una entidad por su padre y su nombre normalizado
+func claveUbicacion(idPadre int, nombre string) string {
+	return fmt.Sprintf("%d|%s", idPadre, normalizarBusqueda(nombre))
+}
+
+func valorOVacio(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
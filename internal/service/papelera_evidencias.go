@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// directorioEvidencias es la carpeta raíz donde se guardan los archivos de evidencia
+const directorioEvidencias = "evidencias"
+
+// papeleraEvidencias aparta los archivos de evidencia que se eliminan dentro de una
+// transacción: se borran definitivamente recién al confirmarla (Vaciar) y vuelven a
+// su ubicación si se revierte (Restaurar), para que la base nunca apunte a archivos
+// que ya no existen.
+type papeleraEvidencias struct {
+	dir     string
+	movidos []archivoApartado
+}
+
+type archivoApartado struct {
+	original   string
+	enPapelera string
+}
+
+func newPapeleraEvidencias() *papeleraEvidencias {
+	return &papeleraEvidencias{dir: filepath.Join(directorioEvidencias, ".papelera")}
+}
+
+// Apartar mueve el archivo a la papelera; si ya no existe no hay nada que apartar
+func (p *papeleraEvidencias) Apartar(ruta string) error {
+	if err := os.MkdirAll(p.dir, 0755); err != nil {
+		return fmt.Errorf("error creating papelera: %w", err)
+	}
+
+	destino := filepath.Join(p.dir, fmt.Sprintf("%d_%s", time.Now().UnixNano(), filepath.Base(ruta)))
+	if err := os.Rename(ruta, destino); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error moving evidencia a la papelera: %w", err)
+	}
+
+	p.movidos = append(p.movidos, archivoApartado{original: ruta, enPapelera: destino})
+	return nil
+}
+
+// Vaciar elimina los archivos apartados; se llama después de confirmar la transacción
+func (p *papeleraEvidencias) Vaciar() {
+	for _, a := range p.movidos {
+		if err := os.Remove(a.enPapelera); err != nil && !os.IsNotExist(err) {
+			log.Printf("⚠️  No se pudo eliminar %s de la papelera: %v", a.enPapelera, err)
+		}
+	}
+	p.movidos = nil
+}
+
+// Restaurar devuelve los archivos apartados a su ubicación original, en orden inverso
+func (p *papeleraEvidencias) Restaurar() {
+	for i := len(p.movidos) - 1; i >= 0; i-- {
+		a := p.movidos[i]
+		if err := os.Rename(a.enPapelera, a.original); err != nil {
+			log.Printf("⚠️  No se pudo restaurar la evidencia %s (queda en %s): %v", a.original, a.enPapelera, err)
+		}
+	}
+	p.movidos = nil
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
+)
+
+// PlanAccionService administra el plan de acciones de mejora de cada bodega y verifica, en
+// la autoevaluación siguiente, cuáles se lograron
+type PlanAccionService struct {
+	accionRepo            repository.AccionMejoraRepository
+	bodegaRepo            repository.BodegaRepository
+	indicadorRepo         repository.IndicadorRepository
+	nivelRespuestaRepo    repository.NivelRespuestaRepository
+	responsableRepo       repository.ResponsableRepository
+	cuentaRepo            repository.CuentaRepository
+	autoevaluacionRepo    repository.AutoevaluacionRepository
+	autoevaluacionService *AutoevaluacionService
+}
+
+func NewPlanAccionService(
+	accionRepo repository.AccionMejoraRepository,
+	bodegaRepo repository.BodegaRepository,
+	indicadorRepo repository.IndicadorRepository,
+	nivelRespuestaRepo repository.NivelRespuestaRepository,
+	responsableRepo repository.ResponsableRepository,
+	cuentaRepo repository.CuentaRepository,
+	autoevaluacionRepo repository.AutoevaluacionRepository,
+	autoevaluacionService *AutoevaluacionService,
+) *PlanAccionService {
+	return &PlanAccionService{
+		accionRepo:            accionRepo,
+		bodegaRepo:            bodegaRepo,
+		indicadorRepo:         indicadorRepo,
+		nivelRespuestaRepo:    nivelRespuestaRepo,
+		responsableRepo:       responsableRepo,
+		cuentaRepo:            cuentaRepo,
+		autoevaluacionRepo:    autoevaluacionRepo,
+		autoevaluacionService: autoevaluacionService,
+	}
+}
+
+// Listar devuelve el plan de acción de la bodega; las acciones sin terminar con la fecha
+// límite anterior al día de ahora se marcan como vencidas
+func (s *PlanAccionService) Listar(ctx context.Context, filtro domain.FiltroAccionesMejora, ahora time.Time) ([]*domain.AccionMejora, error) {
+	if filtro.Estado != nil && !estadoAccionValido(*filtro.Estado) {
+		return nil, validator.ValidationErrors{{Field: "estado", Message: fmt.Sprintf("estado de acción %q inválido", *filtro.Estado)}}
+	}
+	if _, err := s.bodegaRepo.FindByID(ctx, filtro.IDBodega); err != nil {
+		return nil, err
+	}
+
+	hoy := inicioDelDia(ahora)
+	if filtro.VencidasAl != nil {
+		vencidasAl := inicioDelDia(*filtro.VencidasAl)
+		filtro.VencidasAl = &vencidasAl
+	}
+
+	acciones, err := s.accionRepo.FindByBodega(ctx, filtro)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range acciones {
+		marcarVencida(a, hoy)
+	}
+	return acciones, nil
+}
+
+// Crear agrega una acción al plan de la bodega
+func (s *PlanAccionService) Crear(ctx context.Context, idBodega int, req *domain.AccionMejoraRequest, ahora time.Time) (*domain.AccionMejora, error) {
+	if _, err := s.bodegaRepo.FindByID(ctx, idBodega); err != nil {
+		return nil, err
+	}
+
+	accion := &domain.AccionMejora{IDBodega: idBodega, Estado: domain.AccionPendiente}
+	if err := s.aplicarRequest(ctx, accion, req, ahora); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.accionRepo.Create(ctx, nil, accion); err != nil {
+		return nil, err
+	}
+
+	return s.buscar(ctx, idBodega, accion.ID, ahora)
+}
+
+// Modificar reemplaza los datos de una acción del plan de la bodega
+func (s *PlanAccionService) Modificar(ctx context.Context, idBodega, idAccion int, req *domain.AccionMejoraRequest, ahora time.Time) (*domain.AccionMejora, error) {
+	accion, err := s.buscar(ctx, idBodega, idAccion, ahora)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.aplicarRequest(ctx, accion, req, ahora); err != nil {
+		return nil, err
+	}
+
+	if err := s.accionRepo.Update(ctx, nil, accion); err != nil {
+		return nil, err
+	}
+
+	return s.buscar(ctx, idBodega, idAccion, ahora)
+}
+
+// GetSeguimiento compara con las respuestas de la autoevaluación las acciones que la bodega
+// planificó desde el inicio de su autoevaluación anterior (sin contar las canceladas) hasta
+// el inicio de esta. Una acción se logra si el indicador, identificado por su clave para
+// abarcar cambios de versión de la guía, alcanza un nivel igual o superior al objetivo.
+func (s *PlanAccionService) GetSeguimiento(ctx context.Context, idAutoevaluacion int, ahora time.Time) (*domain.SeguimientoPlanAccion, error) {
+	detalle, err := s.autoevaluacionService.GetDetalle(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	filtro := domain.FiltroAccionesMejora{
+		IDBodega:       detalle.IDBodega,
+		CreadasHasta:   &detalle.FechaInicio,
+		SinDescartadas: true,
+	}
+	anterior, err := s.autoevaluacionAnterior(ctx, detalle.IDBodega, detalle.FechaInicio)
+	if err != nil {
+		return nil, err
+	}
+	if anterior != nil {
+		filtro.CreadasDesde = &anterior.FechaInicio
+	}
+
+	acciones, err := s.accionRepo.FindByBodega(ctx, filtro)
+	if err != nil {
+		return nil, err
+	}
+
+	respuestas := make(map[string]*domain.RespuestaDetalle, len(detalle.Respuestas))
+	for _, r := range detalle.Respuestas {
+		respuestas[r.ClaveIndicador] = r
+	}
+
+	hoy := inicioDelDia(ahora)
+	seguimiento := &domain.SeguimientoPlanAccion{
+		IDAutoevaluacion: detalle.ID,
+		Estado:           detalle.Estado,
+		Acciones:         make([]*domain.SeguimientoAccion, 0, len(acciones)),
+	}
+	for _, a := range acciones {
+		marcarVencida(a, hoy)
+		item := &domain.SeguimientoAccion{AccionMejora: a, Resultado: domain.ResultadoAccionSinRespuesta}
+
+		if r, ok := respuestas[a.ClaveIndicador]; ok {
+			nivel, puntos := r.NivelRespuesta, r.Puntos
+			item.NivelAlcanzado = &nivel
+			item.PuntosAlcanzados = &puntos
+			if r.PosicionNivel >= a.PosicionObjetivo {
+				item.Resultado = domain.ResultadoAccionLograda
+			} else {
+				item.Resultado = domain.ResultadoAccionNoLograda
+			}
+		}
+
+		switch item.Resultado {
+		case domain.ResultadoAccionLograda:
+			seguimiento.Logradas++
+		case domain.ResultadoAccionNoLograda:
+			seguimiento.NoLogradas++
+		default:
+			seguimiento.SinRespuesta++
+		}
+		seguimiento.Acciones = append(seguimiento.Acciones, item)
+	}
+
+	return seguimiento, nil
+}
+
+// autoevaluacionAnterior devuelve la última autoevaluación no cancelada de la bodega
+// iniciada antes de la fecha, o nil si no hay
+func (s *PlanAccionService) autoevaluacionAnterior(ctx context.Context, idBodega int, antesDe time.Time) (*domain.AutoevaluacionResumen, error) {
+	anteriores, _, err := s.autoevaluacionRepo.FindByBodega(ctx, domain.FiltroAutoevaluaciones{
+		IDBodega:  idBodega,
+		Hasta:     &antesDe,
+		Pagina:    1,
+		PorPagina: 100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range anteriores {
+		if a.Estado != domain.EstadoCancelada {
+			return a, nil
+		}
+	}
+	return nil, nil
+}
+
+// buscar devuelve la acción si pertenece a la bodega
+func (s *PlanAccionService) buscar(ctx context.Context, idBodega, idAccion int, ahora time.Time) (*domain.AccionMejora, error) {
+	accion, err := s.accionRepo.FindByID(ctx, idAccion)
+	if err != nil {
+		return nil, err
+	}
+	if accion.IDBodega != idBodega {
+		return nil, domain.ErrNotFound
+	}
+
+	marcarVencida(accion, inicioDelDia(ahora))
+	return accion, nil
+}
+
+// aplicarRequest valida el pedido y copia sus datos en la acción. Al pasar a COMPLETADA se
+// registra la fecha; cualquier otro estado la borra.
+func (s *PlanAccionService) aplicarRequest(ctx context.Context, accion *domain.AccionMejora, req *domain.AccionMejoraRequest, ahora time.Time) error {
+	var errs validator.ValidationErrors
+
+	descripcion := validator.LimpiarTextoLibre(req.Descripcion)
+	switch {
+	case descripcion == "":
+		errs = append(errs, validator.ValidationError{Field: "descripcion", Message: "la descripción es obligatoria"})
+	case utf8.RuneCountInString(descripcion) > domain.LongitudMaximaDescripcionAccion:
+		errs = append(errs, validator.ValidationError{Field: "descripcion", Message: fmt.Sprintf("la descripción no puede superar los %d caracteres", domain.LongitudMaximaDescripcionAccion)})
+	}
+
+	fechaLimite, err := time.Parse("2006-01-02", req.FechaLimite)
+	if err != nil {
+		errs = append(errs, validator.ValidationError{Field: "fecha_limite", Message: "fecha límite inválida (formato YYYY-MM-DD)"})
+	}
+
+	estado := accion.Estado
+	if req.Estado != nil {
+		estado = *req.Estado
+		if !estadoAccionValido(estado) {
+			errs = append(errs, validator.ValidationError{Field: "estado", Message: fmt.Sprintf("estado de acción %q inválido", estado)})
+		}
+	}
+
+	if err := s.validarIndicadorYNivel(ctx, req.IDIndicador, req.IDNivelObjetivo, &errs); err != nil {
+		return err
+	}
+	if req.IDResponsable != nil {
+		if err := s.validarResponsable(ctx, accion.IDBodega, *req.IDResponsable, &errs); err != nil {
+			return err
+		}
+	}
+	if req.IDAutoevaluacionOrigen != nil {
+		auto, err := s.autoevaluacionRepo.FindByID(ctx, *req.IDAutoevaluacionOrigen)
+		switch {
+		case errors.Is(err, domain.ErrNotFound), err == nil && auto.IDBodega != accion.IDBodega:
+			errs = append(errs, validator.ValidationError{Field: "id_autoevaluacion_origen", Message: "la autoevaluación no pertenece a la bodega"})
+		case err != nil:
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if estado == domain.AccionCompletada {
+		if accion.Estado != domain.AccionCompletada || accion.FechaCompletada == nil {
+			accion.FechaCompletada = &ahora
+		}
+	} else {
+		accion.FechaCompletada = nil
+	}
+
+	accion.IDIndicador = req.IDIndicador
+	accion.IDNivelObjetivo = req.IDNivelObjetivo
+	accion.IDResponsable = req.IDResponsable
+	accion.IDAutoevaluacionOrigen = req.IDAutoevaluacionOrigen
+	accion.Descripcion = descripcion
+	accion.FechaLimite = fechaLimite
+	accion.Estado = estado
+	return nil
+}
+
+// validarIndicadorYNivel agrega a errs los problemas del indicador o del nivel objetivo, que
+// debe ser uno de los niveles de respuesta del indicador
+func (s *PlanAccionService) validarIndicadorYNivel(ctx context.Context, idIndicador, idNivel int, errs *validator.ValidationErrors) error {
+	if _, err := s.indicadorRepo.FindByID(ctx, idIndicador); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			*errs = append(*errs, validator.ValidationError{Field: "id_indicador", Message: "el indicador no existe"})
+			return nil
+		}
+		return err
+	}
+
+	nivel, err := s.nivelRespuestaRepo.FindByID(ctx, idNivel)
+	switch {
+	case errors.Is(err, domain.ErrNotFound), err == nil && nivel.IDIndicador != idIndicador:
+		*errs = append(*errs, validator.ValidationError{Field: "id_nivel_objetivo", Message: "el nivel objetivo no pertenece al indicador"})
+	case err != nil:
+		return err
+	}
+	return nil
+}
+
+// validarResponsable agrega a errs el problema si el responsable no es uno activo de la bodega
+func (s *PlanAccionService) validarResponsable(ctx context.Context, idBodega, idResponsable int, errs *validator.ValidationErrors) error {
+	responsable, err := s.responsableRepo.FindByID(ctx, idResponsable)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			*errs = append(*errs, validator.ValidationError{Field: "id_responsable", Message: "el responsable no existe"})
+			return nil
+		}
+		return err
+	}
+
+	cuenta, err := s.cuentaRepo.FindByID(ctx, responsable.IDCuenta)
+	if err != nil {
+		return fmt.Errorf("error al buscar la cuenta del responsable: %w", err)
+	}
+
+	switch {
+	case cuenta.IDBodega == nil || *cuenta.IDBodega != idBodega:
+		*errs = append(*errs, validator.ValidationError{Field: "id_responsable", Message: "el responsable no pertenece a la bodega"})
+	case !responsable.Activo:
+		*errs = append(*errs, validator.ValidationError{Field: "id_responsable", Message: "el responsable está dado de baja"})
+	}
+	return nil
+}
+
+func estadoAccionValido(estado domain.EstadoAccionMejora) bool {
+	switch estado {
+	case domain.AccionPendiente, domain.AccionEnCurso, domain.AccionCompletada, domain.AccionDescartada:
+		return true
+	}
+	return false
+}
+
+// marcarVencida indica si la acción sigue sin terminar después de su fecha límite
+func marcarVencida(accion *domain.AccionMejora, hoy time.Time) {
+	sinTerminar := accion.Estado == domain.AccionPendiente || accion.Estado == domain.AccionEnCurso
+	accion.Vencida = sinTerminar && accion.FechaLimite.Before(hoy)
+}
+
+// inicioDelDia trunca la fecha al día, en UTC como las fechas límite que devuelve la base
+func inicioDelDia(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"math"
+
+	"coviar_backend/internal/domain"
+)
+
+// ScoringStrategy calcula los resultados por capítulo de una autoevaluación a partir de
+// los puntajes de los indicadores habilitados para su segmento. El puntaje total es la
+// suma de los puntos obtenidos en los capítulos.
+type ScoringStrategy interface {
+	CalcularCapitulos(puntajes []*domain.PuntajeIndicador) []*domain.ResultadoCapitulo
+}
+
+// EstrategiaSuma suma los puntos de las respuestas (comportamiento original)
+type EstrategiaSuma struct{}
+
+func (EstrategiaSuma) CalcularCapitulos(puntajes []*domain.PuntajeIndicador) []*domain.ResultadoCapitulo {
+	return agruparPorCapitulo(puntajes, func(p *domain.PuntajeIndicador) float64 { return 1 }, func(p *domain.PuntajeIndicador) float64 { return 1 })
+}
+
+// EstrategiaPonderada multiplica los puntos de cada indicador por su peso y el subtotal de
+// cada capítulo por el peso del capítulo; los subtotales se redondean al entero más cercano
+type EstrategiaPonderada struct{}
+
+func (EstrategiaPonderada) CalcularCapitulos(puntajes []*domain.PuntajeIndicador) []*domain.ResultadoCapitulo {
+	return agruparPorCapitulo(puntajes,
+		func(p *domain.PuntajeIndicador) float64 { return p.PesoIndicador },
+		func(p *domain.PuntajeIndicador) float64 { return p.PesoCapitulo },
+	)
+}
+
+// estrategiasPuntaje asocia cada estrategia configurable en la guía con su implementación
+var estrategiasPuntaje = map[domain.EstrategiaPuntaje]ScoringStrategy{
+	domain.EstrategiaSuma:      EstrategiaSuma{},
+	domain.EstrategiaPonderada: EstrategiaPonderada{},
+}
+
+// estrategiaDe devuelve la estrategia de puntaje de la versión; SUMA si no está configurada
+func estrategiaDe(version *domain.GuiaVersion) ScoringStrategy {
+	if version != nil {
+		if estrategia, ok := estrategiasPuntaje[version.EstrategiaPuntaje]; ok {
+			return estrategia
+		}
+	}
+	return EstrategiaSuma{}
+}
+
+// agruparPorCapitulo acumula los puntos (ponderados) de los indicadores en su capítulo,
+// respetando el orden en que llegan
+func agruparPorCapitulo(puntajes []*domain.PuntajeIndicador, pesoIndicador, pesoCapitulo func(*domain.PuntajeIndicador) float64) []*domain.ResultadoCapitulo {
+	type acumulado struct {
+		resultado *domain.ResultadoCapitulo
+		peso      float64
+		obtenidos float64
+		maximos   float64
+	}
+
+	orden := make([]*acumulado, 0)
+	porCapitulo := make(map[int]*acumulado)
+	for _, p := range puntajes {
+		acc, ok := porCapitulo[p.IDCapitulo]
+		if !ok {
+			acc = &acumulado{
+				resultado: &domain.ResultadoCapitulo{IDCapitulo: p.IDCapitulo, Capitulo: p.Capitulo},
+				peso:      pesoCapitulo(p),
+			}
+			porCapitulo[p.IDCapitulo] = acc
+			orden = append(orden, acc)
+		}
+		peso := pesoIndicador(p)
+		acc.obtenidos += float64(p.Puntos) * peso
+		acc.maximos += float64(p.PuntosMaximos) * peso
+	}
+
+	resultados := make([]*domain.ResultadoCapitulo, 0, len(orden))
+	for _, acc := range orden {
+		acc.resultado.PuntosObtenidos = int(math.Round(acc.obtenidos * acc.peso))
+		acc.resultado.PuntosMaximos = int(math.Round(acc.maximos * acc.peso))
+		acc.resultado.Porcentaje = porcentaje(acc.resultado.PuntosObtenidos, acc.resultado.PuntosMaximos)
+		resultados = append(resultados, acc.resultado)
+	}
+	return resultados
+}
+
+// asignarNivel elige el nivel de sostenibilidad (ordenados por puntaje mínimo) que
+// corresponde al puntaje. Si el puntaje supera todos los rangos se asigna el más alto y
+// si queda por debajo, el primero. Cuando el nivel exige porcentajes mínimos por capítulo
+// que no se cumplen, se baja al nivel anterior cuyos requisitos sí se cumplan.
+func asignarNivel(puntaje int, capitulos []*domain.ResultadoCapitulo, niveles []*domain.NivelSostenibilidad) *domain.NivelSostenibilidad {
+	if len(niveles) == 0 {
+		return nil
+	}
+
+	pos := -1
+	for i, nivel := range niveles {
+		if puntaje >= nivel.MinPuntaje && puntaje <= nivel.MaxPuntaje {
+			pos = i
+			break
+		}
+	}
+	if pos < 0 {
+		for i, nivel := range niveles {
+			if puntaje >= nivel.MinPuntaje {
+				pos = i
+			}
+		}
+	}
+	if pos < 0 {
+		return niveles[0]
+	}
+
+	porcentajes := make(map[int]float64, len(capitulos))
+	for _, c := range capitulos {
+		porcentajes[c.IDCapitulo] = c.Porcentaje
+	}
+	for ; pos > 0; pos-- {
+		if cumpleRequisitos(niveles[pos], porcentajes) {
+			break
+		}
+	}
+	return niveles[pos]
+}
+
+func cumpleRequisitos(nivel *domain.NivelSostenibilidad, porcentajes map[int]float64) bool {
+	for _, req := range nivel.Requisitos {
+		if porcentajes[req.IDCapitulo] < req.MinPorcentaje {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/reporte"
+)
+
+type ReporteService struct {
+	autoevaluacionService *AutoevaluacionService
+	bodegaService         *BodegaService
+}
+
+func NewReporteService(autoevaluacionService *AutoevaluacionService, bodegaService *BodegaService) *ReporteService {
+	return &ReporteService{
+		autoevaluacionService: autoevaluacionService,
+		bodegaService:         bodegaService,
+	}
+}
+
+// GenerarReporte genera el PDF con los resultados completos de la autoevaluación
+func (s *ReporteService) GenerarReporte(ctx context.Context, idAutoevaluacion int) ([]byte, error) {
+	return s.generar(ctx, idAutoevaluacion, reporte.GenerarReporte)
+}
+
+// GenerarCertificado genera el certificado de una página con el nivel alcanzado
+func (s *ReporteService) GenerarCertificado(ctx context.Context, idAutoevaluacion int) ([]byte, error) {
+	return s.generar(ctx, idAutoevaluacion, reporte.GenerarCertificado)
+}
+
+func (s *ReporteService) generar(ctx context.Context, idAutoevaluacion int, generador func(w io.Writer, datos *reporte.Datos) error) ([]byte, error) {
+	datos, err := s.obtenerDatos(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := generador(&buf, datos); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// obtenerDatos reúne la información del reporte; solo se emite para autoevaluaciones completadas
+func (s *ReporteService) obtenerDatos(ctx context.Context, idAutoevaluacion int) (*reporte.Datos, error) {
+	detalle, err := s.autoevaluacionService.GetDetalle(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+	if detalle.Estado != domain.EstadoCompletada {
+		return nil, domain.ErrAutoevaluacionNoCompletada
+	}
+
+	resultados, err := s.autoevaluacionService.GetResultados(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	bodega, err := s.bodegaService.GetByID(ctx, detalle.IDBodega)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bodega: %w", err)
+	}
+
+	return &reporte.Datos{
+		Bodega:     bodega,
+		Detalle:    detalle,
+		Resultados: resultados,
+	}, nil
+}
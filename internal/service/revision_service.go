@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/validator"
+)
+
+// RevisionService implementa la revisión de autoevaluaciones completadas por los auditores
+// asignados a cada bodega: COMPLETADA → EN_REVISION → VALIDADA u OBSERVADA.
+type RevisionService struct {
+	revisionRepo       repository.RevisionRepository
+	auditorRepo        repository.AuditorRepository
+	autoevaluacionRepo repository.AutoevaluacionRepository
+	respuestaRepo      repository.RespuestaRepository
+	resultadoRepo      repository.ResultadoCapituloRepository
+	segmentoRepo       repository.SegmentoRepository
+	guiaVersionRepo    repository.GuiaVersionRepository
+}
+
+func NewRevisionService(
+	revisionRepo repository.RevisionRepository,
+	auditorRepo repository.AuditorRepository,
+	autoevaluacionRepo repository.AutoevaluacionRepository,
+	respuestaRepo repository.RespuestaRepository,
+	resultadoRepo repository.ResultadoCapituloRepository,
+	segmentoRepo repository.SegmentoRepository,
+	guiaVersionRepo repository.GuiaVersionRepository,
+) *RevisionService {
+	return &RevisionService{
+		revisionRepo:       revisionRepo,
+		auditorRepo:        auditorRepo,
+		autoevaluacionRepo: autoevaluacionRepo,
+		respuestaRepo:      respuestaRepo,
+		resultadoRepo:      resultadoRepo,
+		segmentoRepo:       segmentoRepo,
+		guiaVersionRepo:    guiaVersionRepo,
+	}
+}
+
+// ListarAsignadas devuelve las autoevaluaciones completadas de las bodegas del auditor
+func (s *RevisionService) ListarAsignadas(ctx context.Context, idCuenta int, estadoRevision *domain.EstadoRevisionAutoevaluacion) ([]*domain.AutoevaluacionResumen, error) {
+	if estadoRevision != nil {
+		switch *estadoRevision {
+		case domain.RevisionEnCurso, domain.RevisionValidada, domain.RevisionAutoevaluacionObservada:
+		default:
+			return nil, validator.ValidationErrors{{Field: "estado_revision", Message: "estado de revisión inválido"}}
+		}
+	}
+	return s.auditorRepo.FindAutoevaluacionesAsignadas(ctx, idCuenta, estadoRevision)
+}
+
+// GetRevision devuelve las respuestas con su revisión y el puntaje que resulta de excluir
+// las rechazadas hasta el momento
+func (s *RevisionService) GetRevision(ctx context.Context, idAutoevaluacion, idCuenta int) (*domain.RevisionAutoevaluacion, error) {
+	resumen, err := s.autoevaluacionAsignada(ctx, idAutoevaluacion, idCuenta)
+	if err != nil {
+		return nil, err
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+
+	capitulos, puntaje, nivel, err := s.puntajeValidado(ctx, resumen)
+	if err != nil {
+		return nil, err
+	}
+
+	revision := &domain.RevisionAutoevaluacion{
+		Autoevaluacion:  resumen,
+		Respuestas:      respuestas,
+		Capitulos:       capitulos,
+		PuntajeValidado: puntaje,
+	}
+	revision.Pendientes, _ = contarRevisiones(respuestas)
+	if nivel != nil {
+		revision.IDNivelSostenibilidad = &nivel.ID
+		revision.NivelSostenibilidad = &nivel.Nombre
+	}
+
+	return revision, nil
+}
+
+// IniciarRevision toma una autoevaluación completada que todavía no fue revisada
+func (s *RevisionService) IniciarRevision(ctx context.Context, idAutoevaluacion, idCuenta int) (*domain.RevisionAutoevaluacion, error) {
+	if _, err := s.autoevaluacionAsignada(ctx, idAutoevaluacion, idCuenta); err != nil {
+		return nil, err
+	}
+	if err := s.revisionRepo.Iniciar(ctx, idAutoevaluacion, idCuenta); err != nil {
+		return nil, err
+	}
+	return s.GetRevision(ctx, idAutoevaluacion, idCuenta)
+}
+
+// RevisarRespuesta registra la revisión de una respuesta de la autoevaluación en revisión
+func (s *RevisionService) RevisarRespuesta(ctx context.Context, idAutoevaluacion, idRespuesta, idCuenta int, req *domain.RevisarItemRequest) error {
+	comentario, err := s.validarRevisionItem(ctx, idAutoevaluacion, idCuenta, req)
+	if err != nil {
+		return err
+	}
+	return s.revisionRepo.RevisarRespuesta(ctx, idAutoevaluacion, idRespuesta, idCuenta, req.Estado, comentario)
+}
+
+// RevisarEvidencia registra la revisión de la evidencia de una respuesta de la
+// autoevaluación en revisión
+func (s *RevisionService) RevisarEvidencia(ctx context.Context, idAutoevaluacion, idRespuesta, idCuenta int, req *domain.RevisarItemRequest) error {
+	comentario, err := s.validarRevisionItem(ctx, idAutoevaluacion, idCuenta, req)
+	if err != nil {
+		return err
+	}
+	return s.revisionRepo.RevisarEvidencia(ctx, idAutoevaluacion, idRespuesta, idCuenta, req.Estado, comentario)
+}
+
+// FinalizarRevision cierra la revisión cuando todas las respuestas y evidencias fueron
+// revisadas. Solo puede validarse si nada quedó observado. El puntaje y el nivel validados
+// se recalculan sin las respuestas rechazadas.
+func (s *RevisionService) FinalizarRevision(ctx context.Context, idAutoevaluacion, idCuenta int, req *domain.FinalizarRevisionRequest) (*domain.AutoevaluacionResumen, error) {
+	resumen, err := s.autoevaluacionAsignada(ctx, idAutoevaluacion, idCuenta)
+	if err != nil {
+		return nil, err
+	}
+	if err := revisionEnCursoDe(resumen, idCuenta); err != nil {
+		return nil, err
+	}
+
+	req.Resultado = domain.EstadoRevisionAutoevaluacion(strings.ToUpper(strings.TrimSpace(string(req.Resultado))))
+	comentario := comentarioRevision(req.Comentario)
+	switch req.Resultado {
+	case domain.RevisionValidada:
+	case domain.RevisionAutoevaluacionObservada:
+		if comentario == nil {
+			return nil, validator.ValidationErrors{{Field: "comentario", Message: "indique qué debe corregir la bodega"}}
+		}
+	default:
+		return nil, validator.ValidationErrors{{Field: "resultado", Message: "debe ser VALIDADA u OBSERVADA"}}
+	}
+
+	respuestas, err := s.respuestaRepo.FindDetalleByAutoevaluacion(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, fmt.Errorf("error getting respuestas: %w", err)
+	}
+	pendientes, observadas := contarRevisiones(respuestas)
+	if pendientes > 0 {
+		return nil, validator.ValidationErrors{{Field: "respuestas", Message: fmt.Sprintf("quedan %d respuestas o evidencias sin revisar", pendientes)}}
+	}
+	if req.Resultado == domain.RevisionValidada && observadas > 0 {
+		return nil, validator.ValidationErrors{{Field: "resultado", Message: fmt.Sprintf("hay %d respuestas o evidencias observadas: la revisión debe cerrarse como OBSERVADA", observadas)}}
+	}
+
+	_, puntaje, nivel, err := s.puntajeValidado(ctx, resumen)
+	if err != nil {
+		return nil, err
+	}
+	var idNivel *int
+	if nivel != nil {
+		idNivel = &nivel.ID
+	}
+
+	if err := s.revisionRepo.Finalizar(ctx, idAutoevaluacion, idCuenta, req.Resultado, comentario, puntaje, idNivel); err != nil {
+		return nil, err
+	}
+
+	return s.autoevaluacionRepo.FindResumenByID(ctx, idAutoevaluacion)
+}
+
+// autoevaluacionAsignada devuelve la autoevaluación completada si su bodega está asignada
+// al auditor
+func (s *RevisionService) autoevaluacionAsignada(ctx context.Context, idAutoevaluacion, idCuenta int) (*domain.AutoevaluacionResumen, error) {
+	resumen, err := s.autoevaluacionRepo.FindResumenByID(ctx, idAutoevaluacion)
+	if err != nil {
+		return nil, err
+	}
+
+	asignado, err := s.auditorRepo.EstaAsignado(ctx, idCuenta, resumen.IDBodega)
+	if err != nil {
+		return nil, err
+	}
+	if !asignado {
+		return nil, domain.ErrAuditorNoAsignado
+	}
+
+	if resumen.Estado != domain.EstadoCompletada || resumen.IDSegmento == nil {
+		return nil, domain.ErrAutoevaluacionNoCompletada
+	}
+	return resumen, nil
+}
+
+// revisionEnCursoDe verifica que la autoevaluación esté en revisión por el auditor
+func revisionEnCursoDe(resumen *domain.AutoevaluacionResumen, idCuenta int) error {
+	if resumen.EstadoRevision == nil || *resumen.EstadoRevision != domain.RevisionEnCurso {
+		return domain.ErrRevisionNoEnCurso
+	}
+	if resumen.IDCuentaRevision == nil || *resumen.IDCuentaRevision != idCuenta {
+		return domain.ErrRevisionDeOtroAuditor
+	}
+	return nil
+}
+
+// validarRevisionItem verifica que la autoevaluación esté en revisión por el auditor y que el estado sea
+// un resultado válido; devuelve el comentario normalizado (obligatorio si no se aprueba)
+func (s *RevisionService) validarRevisionItem(ctx context.Context, idAutoevaluacion, idCuenta int, req *domain.RevisarItemRequest) (*string, error) {
+	resumen, err := s.autoevaluacionAsignada(ctx, idAutoevaluacion, idCuenta)
+	if err != nil {
+		return nil, err
+	}
+	if err := revisionEnCursoDe(resumen, idCuenta); err != nil {
+		return nil, err
+	}
+
+	req.Estado = domain.EstadoRevision(strings.ToUpper(strings.TrimSpace(string(req.Estado))))
+	comentario := comentarioRevision(req.Comentario)
+	switch req.Estado {
+	case domain.RevisionAprobada:
+	case domain.RevisionObservada, domain.RevisionRechazada:
+		if comentario == nil {
+			return nil, validator.ValidationErrors{{Field: "comentario", Message: "el comentario es obligatorio al observar o rechazar"}}
+		}
+	default:
+		return nil, validator.ValidationErrors{{Field: "estado", Message: "debe ser APROBADA, OBSERVADA o RECHAZADA"}}
+	}
+
+	return comentario, nil
+}
+
+// puntajeValidado recalcula los resultados con 0 puntos en las respuestas rechazadas
+func (s *RevisionService) puntajeValidado(ctx context.Context, resumen *domain.AutoevaluacionResumen) ([]*domain.ResultadoCapitulo, int, *domain.NivelSostenibilidad, error) {
+	version, err := s.guiaVersionRepo.FindByID(ctx, resumen.IDGuiaVersion)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error finding guia_version: %w", err)
+	}
+
+	puntajes, err := s.resultadoRepo.FindPuntajesIndicador(ctx, resumen.ID, *resumen.IDSegmento, resumen.IDGuiaVersion)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error calculating resultados por capitulo: %w", err)
+	}
+	for _, p := range puntajes {
+		if p.Rechazada {
+			p.Puntos = 0
+		}
+	}
+
+	capitulos := estrategiaDe(version).CalcularCapitulos(puntajes)
+	total := 0
+	for _, c := range capitulos {
+		total += c.PuntosObtenidos
+	}
+
+	niveles, err := s.segmentoRepo.FindNivelesSostenibilidadBySegmento(ctx, *resumen.IDSegmento, resumen.IDGuiaVersion)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("error getting niveles sostenibilidad: %w", err)
+	}
+
+	return capitulos, total, asignarNivel(total, capitulos, niveles), nil
+}
+
+// comentarioRevision devuelve el comentario sin espacios sobrantes, o nil si está vacío
+func comentarioRevision(comentario string) *string {
+	comentario = strings.TrimSpace(comentario)
+	if comentario == "" {
+		return nil
+	}
+	return &comentario
+}
+
+// contarRevisiones cuenta las respuestas y evidencias sin revisar y las observadas
+func contarRevisiones(respuestas []*domain.RespuestaDetalle) (pendientes, observadas int) {
+	contar := func(estado domain.EstadoRevision) {
+		switch estado {
+		case domain.RevisionPendiente:
+			pendientes++
+		case domain.RevisionObservada:
+			observadas++
+		}
+	}
+	for _, r := range respuestas {
+		contar(r.EstadoRevision)
+		if r.EstadoRevisionEvidencia != nil {
+			contar(*r.EstadoRevisionEvidencia)
+		}
+	}
+	return pendientes, observadas
+}
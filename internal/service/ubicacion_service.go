@@ -3,74 +3,491 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"coviar_backend/internal/domain"
 	"coviar_backend/internal/repository"
+	"coviar_backend/pkg/httputil"
+	"coviar_backend/pkg/validator"
+)
+
+const (
+	// Largo mínimo de la búsqueda de localidades
+	busquedaMinCaracteres = 2
+	// Cantidad de resultados por defecto y máxima del autocompletado
+	busquedaLimiteDefecto = 10
+	busquedaLimiteMaximo  = 50
 )
 
 type UbicacionService struct {
 	repo repository.UbicacionRepository
+
+	// Catálogo completo en memoria: cambia muy pocas veces, por eso se carga
+	// una sola vez y se recarga luego de cada importación
+	mu       sync.RWMutex
+	catalogo *catalogoUbicaciones
+}
+
+// catalogoUbicaciones es una copia inmutable del catálogo con índices por ID. Nunca se
+// entregan sus punteros: los métodos del servicio devuelven copias.
+type catalogoUbicaciones struct {
+	provincias              []*domain.Provincia
+	departamentos           []*domain.Departamento
+	localidades             []*domain.Localidad
+	localidadesConUbicacion []*domain.LocalidadConUbicacion
+
+	// Listados completos ya serializados, con su ETag
+	provinciasJSON    *httputil.JSONCacheable
+	departamentosJSON *httputil.JSONCacheable
+	localidadesJSON   *httputil.JSONCacheable
+
+	provinciaPorID             map[int]*domain.Provincia
+	departamentoPorID          map[int]*domain.Departamento
+	localidadPorID             map[int]*domain.Localidad
+	ubicacionPorLocalidad      map[int]*domain.LocalidadConUbicacion
+	departamentosPorProvincia  map[int][]*domain.Departamento
+	localidadesPorDepartamento map[int][]*domain.Localidad
 }
 
 func NewUbicacionService(repo repository.UbicacionRepository) *UbicacionService {
 	return &UbicacionService{repo: repo}
 }
 
+// Recargar vuelve a leer el catálogo completo desde la base de datos
+func (s *UbicacionService) Recargar(ctx context.Context) error {
+	provincias, err := s.repo.GetProvincias(ctx)
+	if err != nil {
+		return err
+	}
+	departamentos, err := s.repo.GetDepartamentos(ctx)
+	if err != nil {
+		return err
+	}
+	localidades, err := s.repo.GetLocalidades(ctx)
+	if err != nil {
+		return err
+	}
+
+	cat := &catalogoUbicaciones{
+		provincias:                 noNil(provincias),
+		departamentos:              noNil(departamentos),
+		localidades:                noNil(localidades),
+		localidadesConUbicacion:    make([]*domain.LocalidadConUbicacion, 0, len(localidades)),
+		provinciaPorID:             make(map[int]*domain.Provincia, len(provincias)),
+		departamentoPorID:          make(map[int]*domain.Departamento, len(departamentos)),
+		localidadPorID:             make(map[int]*domain.Localidad, len(localidades)),
+		ubicacionPorLocalidad:      make(map[int]*domain.LocalidadConUbicacion, len(localidades)),
+		departamentosPorProvincia:  make(map[int][]*domain.Departamento),
+		localidadesPorDepartamento: make(map[int][]*domain.Localidad),
+	}
+
+	for _, p := range cat.provincias {
+		cat.provinciaPorID[p.ID] = p
+	}
+	for _, d := range cat.departamentos {
+		cat.departamentoPorID[d.ID] = d
+		cat.departamentosPorProvincia[d.IDProvincia] = append(cat.departamentosPorProvincia[d.IDProvincia], d)
+	}
+	for _, l := range cat.localidades {
+		cat.localidadPorID[l.ID] = l
+		cat.localidadesPorDepartamento[l.IDDepartamento] = append(cat.localidadesPorDepartamento[l.IDDepartamento], l)
+
+		dep, ok := cat.departamentoPorID[l.IDDepartamento]
+		if !ok {
+			continue
+		}
+		prov, ok := cat.provinciaPorID[dep.IDProvincia]
+		if !ok {
+			continue
+		}
+		ubicacion := &domain.LocalidadConUbicacion{
+			IDLocalidad:    l.ID,
+			Localidad:      l.Nombre,
+			IDDepartamento: dep.ID,
+			Departamento:   dep.Nombre,
+			IDProvincia:    prov.ID,
+			Provincia:      prov.Nombre,
+			NombreCompleto: l.Nombre + ", " + dep.Nombre + ", " + prov.Nombre,
+		}
+		cat.localidadesConUbicacion = append(cat.localidadesConUbicacion, ubicacion)
+		cat.ubicacionPorLocalidad[l.ID] = ubicacion
+	}
+
+	if cat.provinciasJSON, err = httputil.NuevoJSONCacheable(cat.provincias); err != nil {
+		return fmt.Errorf("error serializando provincias: %w", err)
+	}
+	if cat.departamentosJSON, err = httputil.NuevoJSONCacheable(cat.departamentos); err != nil {
+		return fmt.Errorf("error serializando departamentos: %w", err)
+	}
+	if cat.localidadesJSON, err = httputil.NuevoJSONCacheable(cat.localidades); err != nil {
+		return fmt.Errorf("error serializando localidades: %w", err)
+	}
+
+	s.mu.Lock()
+	s.catalogo = cat
+	s.mu.Unlock()
+	return nil
+}
+
+// obtenerCatalogo devuelve el catálogo en memoria, cargándolo si todavía no se hizo
+func (s *UbicacionService) obtenerCatalogo(ctx context.Context) (*catalogoUbicaciones, error) {
+	s.mu.RLock()
+	cat := s.catalogo
+	s.mu.RUnlock()
+	if cat != nil {
+		return cat, nil
+	}
+
+	if err := s.Recargar(ctx); err != nil {
+		return nil, fmt.Errorf("error cargando catálogo de ubicaciones: %w", err)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.catalogo, nil
+}
+
+// noNil evita devolver null en JSON para listas vacías
+func noNil[T any](items []*T) []*T {
+	if items == nil {
+		return make([]*T, 0)
+	}
+	return items
+}
+
+// copiar devuelve una copia del elemento del catálogo, para que quien lo reciba no pueda
+// modificar el compartido
+func copiar[T any](item *T) *T {
+	if item == nil {
+		return nil
+	}
+	c := *item
+	return &c
+}
+
+// copiarLista devuelve una lista nueva (nunca nil) con copias de los elementos
+func copiarLista[T any](items []*T) []*T {
+	copia := make([]*T, len(items))
+	for i, item := range items {
+		copia[i] = copiar(item)
+	}
+	return copia
+}
+
 // ===== PROVINCIAS =====
 
 func (s *UbicacionService) GetProvincias(ctx context.Context) ([]*domain.Provincia, error) {
-	return s.repo.GetProvincias(ctx)
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copiarLista(cat.provincias), nil
+}
+
+// GetProvinciasJSON devuelve el listado de provincias ya serializado
+func (s *UbicacionService) GetProvinciasJSON(ctx context.Context) (*httputil.JSONCacheable, error) {
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cat.provinciasJSON, nil
 }
 
 func (s *UbicacionService) GetProvinciaByID(ctx context.Context, id int) (*domain.Provincia, error) {
-	provincia, err := s.repo.GetProvinciaByID(ctx, id)
+	cat, err := s.obtenerCatalogo(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if provincia == nil {
-		return nil, fmt.Errorf("provincia no encontrada")
+	provincia, ok := cat.provinciaPorID[id]
+	if !ok {
+		return nil, domain.ErrNotFound
 	}
-	return provincia, nil
+	return copiar(provincia), nil
 }
 
 // ===== DEPARTAMENTOS =====
 
 func (s *UbicacionService) GetDepartamentos(ctx context.Context) ([]*domain.Departamento, error) {
-	return s.repo.GetDepartamentos(ctx)
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copiarLista(cat.departamentos), nil
+}
+
+// GetDepartamentosJSON devuelve el listado de departamentos ya serializado
+func (s *UbicacionService) GetDepartamentosJSON(ctx context.Context) (*httputil.JSONCacheable, error) {
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cat.departamentosJSON, nil
 }
 
 func (s *UbicacionService) GetDepartamentoByID(ctx context.Context, id int) (*domain.Departamento, error) {
-	departamento, err := s.repo.GetDepartamentoByID(ctx, id)
+	cat, err := s.obtenerCatalogo(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if departamento == nil {
-		return nil, fmt.Errorf("departamento no encontrado")
+	departamento, ok := cat.departamentoPorID[id]
+	if !ok {
+		return nil, domain.ErrNotFound
 	}
-	return departamento, nil
+	return copiar(departamento), nil
 }
 
 func (s *UbicacionService) GetDepartamentosByProvinciaID(ctx context.Context, provinciaID int) ([]*domain.Departamento, error) {
-	return s.repo.GetDepartamentosByProvinciaID(ctx, provinciaID)
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copiarLista(cat.departamentosPorProvincia[provinciaID]), nil
 }
 
 // ===== LOCALIDADES =====
 
 func (s *UbicacionService) GetLocalidades(ctx context.Context) ([]*domain.Localidad, error) {
-	return s.repo.GetLocalidades(ctx)
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copiarLista(cat.localidades), nil
+}
+
+// GetLocalidadesJSON devuelve el listado de localidades ya serializado
+func (s *UbicacionService) GetLocalidadesJSON(ctx context.Context) (*httputil.JSONCacheable, error) {
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return cat.localidadesJSON, nil
 }
 
 func (s *UbicacionService) GetLocalidadByID(ctx context.Context, id int) (*domain.Localidad, error) {
-	localidad, err := s.repo.GetLocalidadByID(ctx, id)
+	cat, err := s.obtenerCatalogo(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if localidad == nil {
-		return nil, fmt.Errorf("localidad no encontrada")
+	localidad, ok := cat.localidadPorID[id]
+	if !ok {
+		return nil, domain.ErrNotFound
 	}
-	return localidad, nil
+	return copiar(localidad), nil
+}
+
+// GetLocalidadDetalle devuelve la localidad con su departamento y provincia
+func (s *UbicacionService) GetLocalidadDetalle(ctx context.Context, id int) (*domain.LocalidadDetalle, error) {
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	localidad, ok := cat.localidadPorID[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+
+	detalle := &domain.LocalidadDetalle{
+		ID:           localidad.ID,
+		Nombre:       localidad.Nombre,
+		IDGeoref:     localidad.IDGeoref,
+		Departamento: copiar(cat.departamentoPorID[localidad.IDDepartamento]),
+	}
+	if detalle.Departamento != nil {
+		detalle.Provincia = copiar(cat.provinciaPorID[detalle.Departamento.IDProvincia])
+	}
+	return detalle, nil
+}
+
+// CompletarDireccion agrega a la bodega su ubicación y la dirección legible.
+// Si la localidad no figura en el catálogo la bodega queda sin cambios.
+func (s *UbicacionService) CompletarDireccion(ctx context.Context, bodega *domain.Bodega) {
+	if s == nil || bodega == nil {
+		return
+	}
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return
+	}
+	ubicacion, ok := cat.ubicacionPorLocalidad[bodega.IDLocalidad]
+	if !ok {
+		return
+	}
+
+	bodega.Ubicacion = copiar(ubicacion)
+	calle := strings.TrimSpace(bodega.Calle + " " + bodega.Numeracion)
+	if calle == "" {
+		bodega.Direccion = ubicacion.NombreCompleto
+		return
+	}
+	bodega.Direccion = calle + ", " + ubicacion.NombreCompleto
 }
 
 func (s *UbicacionService) GetLocalidadesByDepartamentoID(ctx context.Context, departamentoID int) ([]*domain.Localidad, error) {
-	return s.repo.GetLocalidadesByDepartamentoID(ctx, departamentoID)
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return copiarLista(cat.localidadesPorDepartamento[departamentoID]), nil
+}
+
+// ===== BÚSQUEDA DE LOCALIDADES =====
+
+// Rangos de coincidencia del autocompletado (menor es mejor)
+const (
+	rangoExacto = iota
+	rangoPrefijo
+	rangoPrefijoPalabra
+	rangoContiene
+	rangoAproximado
+	rangoSinCoincidencia
+)
+
+type localidadRankeada struct {
+	localidad *domain.LocalidadConUbicacion
+	nombre    string
+	rango     int
+}
+
+// BuscarLocalidades busca localidades por nombre ignorando tildes y mayúsculas.
+// Prioriza coincidencias exactas, luego por prefijo, por prefijo de palabra, por contenido
+// y finalmente coincidencias aproximadas (tolerando errores de tipeo).
+// Si provinciaID es distinto de 0 solo se buscan localidades de esa provincia.
+func (s *UbicacionService) BuscarLocalidades(ctx context.Context, q string, provinciaID int, limite int) ([]*domain.LocalidadConUbicacion, error) {
+	termino := normalizarBusqueda(q)
+	if len([]rune(termino)) < busquedaMinCaracteres {
+		return nil, validator.ValidationErrors{{
+			Field:   "q",
+			Message: fmt.Sprintf("la búsqueda debe tener al menos %d caracteres", busquedaMinCaracteres),
+		}}
+	}
+
+	if limite <= 0 {
+		limite = busquedaLimiteDefecto
+	}
+	if limite > busquedaLimiteMaximo {
+		limite = busquedaLimiteMaximo
+	}
+
+	cat, err := s.obtenerCatalogo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidatas := make([]localidadRankeada, 0)
+	for _, loc := range cat.localidadesConUbicacion {
+		if provinciaID != 0 && loc.IDProvincia != provinciaID {
+			continue
+		}
+
+		nombre := normalizarBusqueda(loc.Localidad)
+		rango := rangoCoincidencia(nombre, termino)
+		if rango == rangoSinCoincidencia {
+			continue
+		}
+		candidatas = append(candidatas, localidadRankeada{localidad: loc, nombre: nombre, rango: rango})
+	}
+
+	sort.SliceStable(candidatas, func(i, j int) bool {
+		if candidatas[i].rango != candidatas[j].rango {
+			return candidatas[i].rango < candidatas[j].rango
+		}
+		if len(candidatas[i].nombre) != len(candidatas[j].nombre) {
+			return len(candidatas[i].nombre) < len(candidatas[j].nombre)
+		}
+		return candidatas[i].localidad.NombreCompleto < candidatas[j].localidad.NombreCompleto
+	})
+
+	if len(candidatas) > limite {
+		candidatas = candidatas[:limite]
+	}
+
+	resultado := make([]*domain.LocalidadConUbicacion, len(candidatas))
+	for i, c := range candidatas {
+		resultado[i] = copiar(c.localidad)
+	}
+	return resultado, nil
+}
+
+// normalizarBusqueda pasa el texto a mayúsculas sin tildes y colapsa los espacios
+func normalizarBusqueda(s string) string {
+	return strings.Join(strings.Fields(validator.NormalizarTextoSinTildes(s)), " ")
+}
+
+// rangoCoincidencia clasifica qué tan bien coincide un nombre normalizado con el término buscado
+func rangoCoincidencia(nombre, termino string) int {
+	switch {
+	case nombre == termino:
+		return rangoExacto
+	case strings.HasPrefix(nombre, termino):
+		return rangoPrefijo
+	case strings.Contains(" "+nombre, " "+termino):
+		return rangoPrefijoPalabra
+	case strings.Contains(nombre, termino):
+		return rangoContiene
+	}
+
+	// Coincidencia aproximada: se compara el término contra el comienzo del nombre
+	// y de cada una de sus palabras, tolerando errores según el largo del término
+	tolerancia := toleranciaErrores(termino)
+	if tolerancia == 0 {
+		return rangoSinCoincidencia
+	}
+
+	palabras := strings.Fields(nombre)
+	candidatos := append([]string{nombre}, palabras...)
+	for _, candidato := range candidatos {
+		if distanciaPrefijo(candidato, termino) <= tolerancia {
+			return rangoAproximado
+		}
+	}
+
+	return rangoSinCoincidencia
+}
+
+// toleranciaErrores define cuántos errores de tipeo se aceptan según el largo del término
+func toleranciaErrores(termino string) int {
+	n := len([]rune(termino))
+	switch {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// distanciaPrefijo calcula la menor distancia de Levenshtein entre el término
+// y cualquier prefijo del texto
+func distanciaPrefijo(texto, termino string) int {
+	a := []rune(termino)
+	b := []rune(texto)
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			costo := 1
+			if a[i-1] == b[j-1] {
+				costo = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+costo)
+		}
+		prev, curr = curr, prev
+	}
+
+	// La última fila tiene la distancia del término contra cada prefijo del texto
+	mejor := prev[0]
+	for _, d := range prev {
+		if d < mejor {
+			mejor = d
+		}
+	}
+	return mejor
 }
@@ -0,0 +1,11 @@
+-- Migración: Identificadores oficiales (INDEC/Georef) en el catálogo de ubicaciones
+-- Permite actualizar provincias, departamentos y localidades desde los archivos oficiales
+-- sin perder los IDs internos referenciados por bodegas.id_localidad
+
+ALTER TABLE provincias ADD COLUMN IF NOT EXISTS id_georef VARCHAR(20);
+ALTER TABLE departamentos ADD COLUMN IF NOT EXISTS id_georef VARCHAR(20);
+ALTER TABLE localidades ADD COLUMN IF NOT EXISTS id_georef VARCHAR(20);
+
+CREATE UNIQUE INDEX IF NOT EXISTS un_provincias_id_georef ON provincias (id_georef) WHERE id_georef IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS un_departamentos_id_georef ON departamentos (id_georef) WHERE id_georef IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS un_localidades_id_georef ON localidades (id_georef) WHERE id_georef IS NOT NULL;
@@ -0,0 +1,13 @@
+-- Migración: Resultados por capítulo de cada autoevaluación completada
+-- Se calculan al completar la autoevaluación para que el frontend no tenga que recalcularlos
+
+CREATE TABLE IF NOT EXISTS resultados_capitulo (
+    id_autoevaluacion integer not null,
+    id_capitulo integer not null,
+    puntos_obtenidos integer not null,
+    puntos_maximos integer not null,
+    porcentaje numeric(5,2) not null,
+    constraint resultados_capitulo_pk primary key (id_autoevaluacion, id_capitulo),
+    constraint resultados_capitulo_autoevaluacion_fk foreign key (id_autoevaluacion) references autoevaluaciones (id_autoevaluacion) on delete cascade,
+    constraint resultados_capitulo_capitulo_fk foreign key (id_capitulo) references capitulos (id_capitulo)
+);
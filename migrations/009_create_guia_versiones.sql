@@ -0,0 +1,58 @@
+-- Migración: Versionado de la guía de autoevaluación
+-- Cada autoevaluación queda asociada a la versión de la guía con la que se inició.
+-- Los cambios al cuestionario se hacen sobre una versión BORRADOR (copia de la publicada)
+-- que luego se publica; las versiones anteriores quedan archivadas sin modificarse.
+-- Capítulos e indicadores tienen una clave estable entre versiones para poder compararlas.
+
+CREATE TYPE estado_guia_version AS ENUM (
+  'BORRADOR',
+  'PUBLICADA',
+  'ARCHIVADA'
+);
+
+CREATE TABLE IF NOT EXISTS guia_versiones (
+    id_guia_version integer generated always as identity,
+    numero integer not null,
+    estado estado_guia_version not null default 'BORRADOR',
+    descripcion text not null default '',
+    id_version_origen integer,
+    fecha_creacion timestamptz not null default now(),
+    fecha_publicacion timestamptz,
+    constraint guia_versiones_pk primary key (id_guia_version),
+    constraint guia_versiones_numero_un unique (numero),
+    constraint guia_versiones_origen_fk foreign key (id_version_origen) references guia_versiones (id_guia_version)
+);
+
+-- Solo puede haber una versión publicada y un borrador a la vez
+CREATE UNIQUE INDEX IF NOT EXISTS un_guia_versiones_publicada ON guia_versiones (estado) WHERE estado = 'PUBLICADA';
+CREATE UNIQUE INDEX IF NOT EXISTS un_guia_versiones_borrador ON guia_versiones (estado) WHERE estado = 'BORRADOR';
+
+-- La guía actual pasa a ser la versión 1
+INSERT INTO guia_versiones (numero, estado, descripcion, fecha_publicacion)
+VALUES (1, 'PUBLICADA', 'Versión inicial', now());
+
+ALTER TABLE capitulos ADD COLUMN IF NOT EXISTS id_guia_version integer;
+ALTER TABLE capitulos ADD COLUMN IF NOT EXISTS clave text;
+UPDATE capitulos SET id_guia_version = (SELECT id_guia_version FROM guia_versiones WHERE numero = 1) WHERE id_guia_version IS NULL;
+UPDATE capitulos SET clave = 'CAP-' || id_capitulo WHERE clave IS NULL;
+ALTER TABLE capitulos ALTER COLUMN id_guia_version SET NOT NULL;
+ALTER TABLE capitulos ALTER COLUMN clave SET NOT NULL;
+ALTER TABLE capitulos ADD CONSTRAINT capitulos_guia_version_fk foreign key (id_guia_version) references guia_versiones (id_guia_version);
+CREATE UNIQUE INDEX IF NOT EXISTS un_capitulos_version_clave ON capitulos (id_guia_version, clave);
+
+ALTER TABLE indicadores ADD COLUMN IF NOT EXISTS clave text;
+UPDATE indicadores SET clave = 'IND-' || id_indicador WHERE clave IS NULL;
+ALTER TABLE indicadores ALTER COLUMN clave SET NOT NULL;
+
+ALTER TABLE niveles_sostenibilidad ADD COLUMN IF NOT EXISTS id_guia_version integer;
+UPDATE niveles_sostenibilidad SET id_guia_version = (SELECT id_guia_version FROM guia_versiones WHERE numero = 1) WHERE id_guia_version IS NULL;
+ALTER TABLE niveles_sostenibilidad ALTER COLUMN id_guia_version SET NOT NULL;
+ALTER TABLE niveles_sostenibilidad ADD CONSTRAINT niveles_sostenibilidad_guia_version_fk foreign key (id_guia_version) references guia_versiones (id_guia_version);
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS id_guia_version integer;
+UPDATE autoevaluaciones SET id_guia_version = (SELECT id_guia_version FROM guia_versiones WHERE numero = 1) WHERE id_guia_version IS NULL;
+ALTER TABLE autoevaluaciones ALTER COLUMN id_guia_version SET NOT NULL;
+ALTER TABLE autoevaluaciones ADD CONSTRAINT autoevaluaciones_guia_version_fk foreign key (id_guia_version) references guia_versiones (id_guia_version);
+
+CREATE INDEX IF NOT EXISTS idx_capitulos_guia_version ON capitulos(id_guia_version);
+CREATE INDEX IF NOT EXISTS idx_niveles_sostenibilidad_guia_version ON niveles_sostenibilidad(id_guia_version);
@@ -0,0 +1,12 @@
+-- Migración: Edición de la guía desde la administración
+-- Los niveles de respuesta se ordenan por posición dentro de cada indicador.
+
+ALTER TABLE niveles_respuesta ADD COLUMN IF NOT EXISTS posicion integer;
+UPDATE niveles_respuesta nr SET posicion = o.posicion
+FROM (
+    SELECT id_nivel_respuesta, ROW_NUMBER() OVER (PARTITION BY id_indicador ORDER BY puntos, id_nivel_respuesta) AS posicion
+    FROM niveles_respuesta
+) o
+WHERE nr.id_nivel_respuesta = o.id_nivel_respuesta AND nr.posicion IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_segmento_indicador_indicador ON segmento_indicador(id_indicador);
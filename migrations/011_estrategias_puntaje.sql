@@ -0,0 +1,33 @@
+-- Migración: Estrategias de puntaje
+-- Cada versión de la guía define cómo se calcula el puntaje:
+--   SUMA       suma de los puntos de las respuestas (comportamiento original)
+--   PONDERADA  los puntos de cada indicador se multiplican por su peso y el subtotal
+--              de cada capítulo por el peso del capítulo
+-- Además, un nivel de sostenibilidad puede exigir un porcentaje mínimo en ciertos capítulos.
+
+DO $$ BEGIN
+    CREATE TYPE estrategia_puntaje AS ENUM (
+      'SUMA',
+      'PONDERADA'
+    );
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE guia_versiones ADD COLUMN IF NOT EXISTS estrategia_puntaje estrategia_puntaje not null default 'SUMA';
+ALTER TABLE capitulos ADD COLUMN IF NOT EXISTS peso numeric(6,2) not null default 1;
+ALTER TABLE indicadores ADD COLUMN IF NOT EXISTS peso numeric(6,2) not null default 1;
+
+ALTER TABLE capitulos DROP CONSTRAINT IF EXISTS capitulos_peso_ck;
+ALTER TABLE capitulos ADD CONSTRAINT capitulos_peso_ck check (peso > 0);
+ALTER TABLE indicadores DROP CONSTRAINT IF EXISTS indicadores_peso_ck;
+ALTER TABLE indicadores ADD CONSTRAINT indicadores_peso_ck check (peso > 0);
+
+CREATE TABLE IF NOT EXISTS requisitos_nivel_capitulo (
+    id_nivel_sostenibilidad integer not null,
+    id_capitulo integer not null,
+    min_porcentaje numeric(5,2) not null,
+    constraint requisitos_nivel_capitulo_pk primary key (id_nivel_sostenibilidad, id_capitulo),
+    constraint requisitos_nivel_capitulo_nivel_fk foreign key (id_nivel_sostenibilidad) references niveles_sostenibilidad (id_nivel_sostenibilidad) on delete cascade,
+    constraint requisitos_nivel_capitulo_capitulo_fk foreign key (id_capitulo) references capitulos (id_capitulo) on delete cascade,
+    constraint requisitos_nivel_capitulo_porcentaje_ck check (min_porcentaje >= 0 and min_porcentaje <= 100)
+);
@@ -0,0 +1,25 @@
+-- Migración: Reapertura de autoevaluaciones completadas
+-- Un administrador puede reabrir una autoevaluación COMPLETADA para corregir errores de carga.
+-- Cada reapertura queda registrada con su motivo y una copia de las respuestas, el puntaje
+-- y el nivel anteriores; al volver a completarla se guarda el nuevo resultado.
+
+CREATE TABLE IF NOT EXISTS enmiendas_autoevaluacion (
+    id_enmienda integer generated always as identity,
+    id_autoevaluacion integer not null,
+    id_cuenta integer not null,
+    motivo text not null,
+    fecha_reapertura timestamptz not null default now(),
+    snapshot jsonb not null,
+    fecha_recompletado timestamptz,
+    puntaje_nuevo integer,
+    id_nivel_sostenibilidad_nuevo integer,
+    constraint enmiendas_autoevaluacion_pk primary key (id_enmienda),
+    constraint enmiendas_autoevaluacion_autoevaluacion_fk foreign key (id_autoevaluacion) references autoevaluaciones (id_autoevaluacion) on delete cascade,
+    constraint enmiendas_autoevaluacion_cuenta_fk foreign key (id_cuenta) references cuentas (id_cuenta),
+    constraint enmiendas_autoevaluacion_motivo_ck check (length(trim(motivo)) > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_enmiendas_autoevaluacion ON enmiendas_autoevaluacion (id_autoevaluacion);
+
+-- Solo puede haber una enmienda abierta (sin recompletar) por autoevaluación
+CREATE UNIQUE INDEX IF NOT EXISTS un_enmiendas_autoevaluacion_abierta ON enmiendas_autoevaluacion (id_autoevaluacion) WHERE fecha_recompletado IS NULL;
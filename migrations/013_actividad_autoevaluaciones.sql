@@ -0,0 +1,13 @@
+-- Migración: Vencimiento de autoevaluaciones pendientes abandonadas
+-- ultima_actividad se actualiza al guardar respuestas. Un proceso en segundo plano avisa
+-- por email a la bodega luego de N días sin actividad (aviso_inactividad guarda cuándo)
+-- y cancela la autoevaluación luego de M días.
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS ultima_actividad timestamptz;
+UPDATE autoevaluaciones SET ultima_actividad = COALESCE(fecha_fin, fecha_inicio) WHERE ultima_actividad IS NULL;
+ALTER TABLE autoevaluaciones ALTER COLUMN ultima_actividad SET DEFAULT now();
+ALTER TABLE autoevaluaciones ALTER COLUMN ultima_actividad SET NOT NULL;
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS aviso_inactividad timestamptz;
+
+CREATE INDEX IF NOT EXISTS idx_autoevaluaciones_pendientes_actividad ON autoevaluaciones (ultima_actividad) WHERE estado = 'PENDIENTE';
@@ -0,0 +1,13 @@
+-- Migración: Vigencia de los niveles de sostenibilidad
+-- Cada nivel puede definir cuántos meses es válido desde que se completa la
+-- autoevaluación; si no lo define se usa AUTOEVALUACION_MESES_VIGENCIA.
+-- recordatorio_vigencia guarda a cuántos días del vencimiento se envió el último
+-- recordatorio de reevaluación de la autoevaluación (NULL si no se envió ninguno).
+
+ALTER TABLE niveles_sostenibilidad ADD COLUMN IF NOT EXISTS meses_vigencia integer;
+ALTER TABLE niveles_sostenibilidad DROP CONSTRAINT IF EXISTS niveles_sostenibilidad_meses_vigencia_check;
+ALTER TABLE niveles_sostenibilidad ADD CONSTRAINT niveles_sostenibilidad_meses_vigencia_check CHECK (meses_vigencia > 0);
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS recordatorio_vigencia integer;
+
+CREATE INDEX IF NOT EXISTS idx_autoevaluaciones_bodega_completadas ON autoevaluaciones (id_bodega, fecha_fin DESC) WHERE estado = 'COMPLETADA';
@@ -0,0 +1,55 @@
+-- Migración: Revisión de autoevaluaciones por auditores
+-- Las cuentas AUDITOR no pertenecen a una bodega; un administrador les asigna las bodegas
+-- que revisan. El auditor toma una autoevaluación COMPLETADA (estado_revision EN_REVISION),
+-- revisa cada respuesta y evidencia (aprobada, observada o rechazada, con comentario) y
+-- cierra la revisión como VALIDADA u OBSERVADA. Al cerrarla se recalcula el puntaje sin
+-- las respuestas rechazadas (puntaje_validado / id_nivel_sostenibilidad_validado); el
+-- resultado autodeclarado no se modifica.
+
+ALTER TYPE tipo_cuenta ADD VALUE IF NOT EXISTS 'AUDITOR';
+
+-- Solo las cuentas BODEGA tienen bodega (se evita nombrar el valor nuevo del enum en la
+-- misma transacción en que se agrega)
+ALTER TABLE cuentas DROP CONSTRAINT IF EXISTS cuenta_bodega_requerida;
+ALTER TABLE cuentas ADD CONSTRAINT cuenta_bodega_requerida CHECK ((tipo = 'BODEGA') = (id_bodega IS NOT NULL));
+
+CREATE TABLE IF NOT EXISTS auditores_bodega (
+    id_cuenta integer not null,
+    id_bodega integer not null,
+    fecha_asignacion timestamptz not null default now(),
+    constraint auditores_bodega_pk primary key (id_cuenta, id_bodega),
+    constraint auditores_bodega_cuenta_fk foreign key (id_cuenta) references cuentas (id_cuenta) on delete cascade,
+    constraint auditores_bodega_bodega_fk foreign key (id_bodega) references bodegas (id_bodega) on delete cascade
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditores_bodega_bodega ON auditores_bodega (id_bodega);
+
+-- Revisión de cada respuesta y evidencia
+DO $$ BEGIN
+    CREATE TYPE estado_revision AS ENUM ('PENDIENTE_REVISION', 'APROBADA', 'OBSERVADA', 'RECHAZADA');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE respuestas ADD COLUMN IF NOT EXISTS estado_revision estado_revision not null default 'PENDIENTE_REVISION';
+ALTER TABLE respuestas ADD COLUMN IF NOT EXISTS comentario_revision text;
+ALTER TABLE respuestas ADD COLUMN IF NOT EXISTS id_cuenta_revision integer references cuentas (id_cuenta);
+ALTER TABLE respuestas ADD COLUMN IF NOT EXISTS fecha_revision timestamptz;
+
+ALTER TABLE evidencias ADD COLUMN IF NOT EXISTS estado_revision estado_revision not null default 'PENDIENTE_REVISION';
+ALTER TABLE evidencias ADD COLUMN IF NOT EXISTS comentario_revision text;
+ALTER TABLE evidencias ADD COLUMN IF NOT EXISTS id_cuenta_revision integer references cuentas (id_cuenta);
+ALTER TABLE evidencias ADD COLUMN IF NOT EXISTS fecha_revision timestamptz;
+
+-- Revisión de la autoevaluación (NULL: todavía no fue tomada por un auditor)
+DO $$ BEGIN
+    CREATE TYPE estado_revision_autoevaluacion AS ENUM ('EN_REVISION', 'VALIDADA', 'OBSERVADA');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS estado_revision estado_revision_autoevaluacion;
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS id_cuenta_revision integer references cuentas (id_cuenta);
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS fecha_inicio_revision timestamptz;
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS fecha_fin_revision timestamptz;
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS comentario_revision text;
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS puntaje_validado integer;
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS id_nivel_sostenibilidad_validado integer references niveles_sostenibilidad (id_nivel_sostenibilidad);
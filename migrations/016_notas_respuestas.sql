@@ -0,0 +1,7 @@
+-- Migración: Nota de justificación por respuesta
+-- La bodega puede explicar por qué eligió el nivel o en qué está trabajando.
+-- El texto se guarda ya saneado; el límite coincide con el que valida el servicio.
+
+ALTER TABLE respuestas ADD COLUMN IF NOT EXISTS nota text;
+ALTER TABLE respuestas DROP CONSTRAINT IF EXISTS respuestas_nota_check;
+ALTER TABLE respuestas ADD CONSTRAINT respuestas_nota_check CHECK (char_length(nota) <= 1000);
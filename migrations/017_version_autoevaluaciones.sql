@@ -0,0 +1,6 @@
+-- Migración: Control de concurrencia optimista de autoevaluaciones
+-- version aumenta con cada modificación de la bodega (respuestas, segmento, completar,
+-- cancelar) y con la reapertura; se expone como ETag y las modificaciones deben enviarla
+-- en If-Match.
+
+ALTER TABLE autoevaluaciones ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 1;
@@ -0,0 +1,35 @@
+-- Migración: Plan de acción de mejora
+-- La bodega registra acciones para llevar un indicador a un nivel de respuesta objetivo,
+-- con un responsable, una fecha límite y un estado que actualiza entre autoevaluaciones.
+-- Cada acción se verifica con la primera autoevaluación que la bodega inicia después de
+-- crearla: se considera lograda si la respuesta al indicador (asociado por su clave)
+-- alcanza la posición del nivel objetivo.
+
+DO $$ BEGIN
+    CREATE TYPE estado_accion_mejora AS ENUM ('PENDIENTE', 'EN_CURSO', 'COMPLETADA', 'DESCARTADA');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS acciones_mejora (
+    id_accion integer generated always as identity,
+    id_bodega integer not null,
+    id_indicador integer not null,
+    id_nivel_objetivo integer not null,
+    id_responsable integer,
+    id_autoevaluacion_origen integer,
+    descripcion text not null,
+    fecha_limite date not null,
+    estado estado_accion_mejora not null default 'PENDIENTE',
+    fecha_creacion timestamptz not null default now(),
+    fecha_actualizacion timestamptz not null default now(),
+    fecha_completada timestamptz,
+    constraint acciones_mejora_pk primary key (id_accion),
+    constraint acciones_mejora_bodega_fk foreign key (id_bodega) references bodegas (id_bodega) on delete cascade,
+    constraint acciones_mejora_indicador_fk foreign key (id_indicador) references indicadores (id_indicador),
+    constraint acciones_mejora_nivel_fk foreign key (id_nivel_objetivo) references niveles_respuesta (id_nivel_respuesta),
+    constraint acciones_mejora_responsable_fk foreign key (id_responsable) references responsables (id_responsable),
+    constraint acciones_mejora_autoevaluacion_fk foreign key (id_autoevaluacion_origen) references autoevaluaciones (id_autoevaluacion),
+    constraint acciones_mejora_descripcion_check check (char_length(descripcion) between 1 and 1000)
+);
+
+CREATE INDEX IF NOT EXISTS idx_acciones_mejora_bodega ON acciones_mejora (id_bodega, fecha_creacion);
@@ -0,0 +1,28 @@
+-- Migración: Clave de indicador única por versión de la guía
+-- Los indicadores se asocian entre versiones por su clave, así que no puede repetirse
+-- dentro de una misma versión. La versión se guarda también en el indicador (la mantiene
+-- un trigger a partir del capítulo) para poder exigirlo con un índice único.
+-- La clave vacía solo existe mientras se crea el indicador, antes de generarla a partir del ID.
+
+ALTER TABLE indicadores ADD COLUMN IF NOT EXISTS id_guia_version integer;
+UPDATE indicadores i SET id_guia_version = c.id_guia_version
+FROM capitulos c
+WHERE i.id_capitulo = c.id_capitulo AND i.id_guia_version IS DISTINCT FROM c.id_guia_version;
+ALTER TABLE indicadores ALTER COLUMN id_guia_version SET NOT NULL;
+
+ALTER TABLE indicadores DROP CONSTRAINT IF EXISTS indicadores_guia_version_fk;
+ALTER TABLE indicadores ADD CONSTRAINT indicadores_guia_version_fk foreign key (id_guia_version) references guia_versiones (id_guia_version);
+
+CREATE UNIQUE INDEX IF NOT EXISTS un_indicadores_version_clave ON indicadores (id_guia_version, clave) WHERE clave <> '';
+
+CREATE OR REPLACE FUNCTION indicadores_asignar_version() RETURNS trigger AS $$
+BEGIN
+    SELECT id_guia_version INTO NEW.id_guia_version FROM capitulos WHERE id_capitulo = NEW.id_capitulo;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS indicadores_version_tg ON indicadores;
+CREATE TRIGGER indicadores_version_tg
+    BEFORE INSERT OR UPDATE OF id_capitulo ON indicadores
+    FOR EACH ROW EXECUTE FUNCTION indicadores_asignar_version();
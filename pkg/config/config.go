@@ -4,16 +4,20 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 // Config contiene toda la configuración de la aplicación
 type Config struct {
-	Server   ServerConfig
-	Supabase SupabaseConfig
-	JWT      JWTConfig
-	App      AppConfig
+	Server           ServerConfig
+	Supabase         SupabaseConfig
+	JWT              JWTConfig
+	App              AppConfig
+	Autoevaluaciones AutoevaluacionesConfig
 }
 
 type ServerConfig struct {
@@ -35,6 +39,15 @@ type AppConfig struct {
 	Environment string
 }
 
+// AutoevaluacionesConfig define cuándo se considera abandonada una autoevaluación pendiente
+// y cuánto vale el nivel obtenido en una completada
+type AutoevaluacionesConfig struct {
+	DiasAvisoInactividad       int   // días sin actividad hasta avisar por email a la bodega
+	DiasCancelacionInactividad int   // días sin actividad hasta cancelarla
+	MesesVigencia              int   // vigencia de los niveles que no definen la suya
+	DiasRecordatorioVigencia   []int // días antes del vencimiento en que se recuerda reevaluar, de mayor a menor
+}
+
 // Load carga las variables de entorno desde .env
 func Load() (*Config, error) {
 	// Cargar .env (opcional)
@@ -60,6 +73,28 @@ func Load() (*Config, error) {
 		},
 	}
 
+	var err error
+	if cfg.Autoevaluaciones.DiasAvisoInactividad, err = getEnvInt("AUTOEVALUACION_DIAS_AVISO_INACTIVIDAD", 30); err != nil {
+		return nil, err
+	}
+	if cfg.Autoevaluaciones.DiasCancelacionInactividad, err = getEnvInt("AUTOEVALUACION_DIAS_CANCELACION_INACTIVIDAD", 90); err != nil {
+		return nil, err
+	}
+	if cfg.Autoevaluaciones.DiasAvisoInactividad <= 0 || cfg.Autoevaluaciones.DiasCancelacionInactividad <= cfg.Autoevaluaciones.DiasAvisoInactividad {
+		return nil, fmt.Errorf("AUTOEVALUACION_DIAS_CANCELACION_INACTIVIDAD debe ser mayor que AUTOEVALUACION_DIAS_AVISO_INACTIVIDAD y ambos positivos")
+	}
+
+	if cfg.Autoevaluaciones.MesesVigencia, err = getEnvInt("AUTOEVALUACION_MESES_VIGENCIA", 24); err != nil {
+		return nil, err
+	}
+	if cfg.Autoevaluaciones.MesesVigencia <= 0 {
+		return nil, fmt.Errorf("AUTOEVALUACION_MESES_VIGENCIA debe ser positivo")
+	}
+	if cfg.Autoevaluaciones.DiasRecordatorioVigencia, err = getEnvInts("AUTOEVALUACION_DIAS_RECORDATORIO_VIGENCIA", []int{60, 30, 7}); err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(cfg.Autoevaluaciones.DiasRecordatorioVigencia)))
+
 	// Validar variables críticas de Supabase
 	if cfg.Supabase.URL == "" || cfg.Supabase.Key == "" || cfg.Supabase.DBPassword == "" {
 		return nil, fmt.Errorf("SUPABASE_URL, SUPABASE_KEY y SUPABASE_DB_PASSWORD son requeridas")
@@ -74,3 +109,32 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s debe ser un número entero: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvInts lee una lista de enteros no negativos separados por comas
+func getEnvInts(key string, defaultValue []int) ([]int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	var valores []int
+	for _, parte := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(parte))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("%s debe ser una lista de enteros no negativos separados por comas", key)
+		}
+		valores = append(valores, n)
+	}
+	return valores, nil
+}
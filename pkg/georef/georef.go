@@ -0,0 +1,230 @@
+package georef
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Provincia según el catálogo oficial INDEC/Georef
+type Provincia struct {
+	ID     string
+	Nombre string
+}
+
+// Departamento según el catálogo oficial INDEC/Georef
+type Departamento struct {
+	ID          string
+	Nombre      string
+	IDProvincia string
+}
+
+// Localidad según el catálogo oficial INDEC/Georef
+type Localidad struct {
+	ID             string
+	Nombre         string
+	IDDepartamento string
+	IDProvincia    string
+}
+
+// Catalogo agrupa los tres niveles del catálogo de ubicaciones
+type Catalogo struct {
+	Provincias    []Provincia
+	Departamentos []Departamento
+	Localidades   []Localidad
+}
+
+// registro es la forma común de una entidad en los archivos JSON de Georef,
+// tanto en las descargas completas como en las respuestas de la API
+type registro struct {
+	ID           string      `json:"id"`
+	Nombre       string      `json:"nombre"`
+	Provincia    *referencia `json:"provincia"`
+	Departamento *referencia `json:"departamento"`
+}
+
+type referencia struct {
+	ID     string `json:"id"`
+	Nombre string `json:"nombre"`
+}
+
+// Fuente es el contenido de un archivo del catálogo junto con su nombre,
+// del que se toma la extensión para determinar el formato
+type Fuente struct {
+	Nombre string
+	Datos  io.Reader
+}
+
+// LeerCatalogo lee los archivos de provincias, departamentos y localidades.
+// El formato (JSON o CSV) se determina por la extensión de cada archivo.
+func LeerCatalogo(pathProvincias, pathDepartamentos, pathLocalidades string) (*Catalogo, error) {
+	paths := []string{pathProvincias, pathDepartamentos, pathLocalidades}
+	fuentes := make([]Fuente, 0, len(paths))
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("error abriendo archivo %s: %w", path, err)
+		}
+		defer f.Close()
+		fuentes = append(fuentes, Fuente{Nombre: path, Datos: f})
+	}
+
+	return LeerCatalogoDesde(fuentes[0], fuentes[1], fuentes[2])
+}
+
+// LeerCatalogoDesde interpreta el catálogo a partir de contenidos ya abiertos
+// (por ejemplo, archivos subidos por HTTP)
+func LeerCatalogoDesde(provincias, departamentos, localidades Fuente) (*Catalogo, error) {
+	regProvincias, err := leerFuente(provincias, "provincias")
+	if err != nil {
+		return nil, err
+	}
+	regDepartamentos, err := leerFuente(departamentos, "departamentos")
+	if err != nil {
+		return nil, err
+	}
+	regLocalidades, err := leerFuente(localidades, "localidades")
+	if err != nil {
+		return nil, err
+	}
+
+	catalogo := &Catalogo{
+		Provincias:    make([]Provincia, 0, len(regProvincias)),
+		Departamentos: make([]Departamento, 0, len(regDepartamentos)),
+		Localidades:   make([]Localidad, 0, len(regLocalidades)),
+	}
+
+	for _, r := range regProvincias {
+		catalogo.Provincias = append(catalogo.Provincias, Provincia{ID: r.ID, Nombre: r.Nombre})
+	}
+	for _, r := range regDepartamentos {
+		catalogo.Departamentos = append(catalogo.Departamentos, Departamento{
+			ID:          r.ID,
+			Nombre:      r.Nombre,
+			IDProvincia: idReferencia(r.Provincia),
+		})
+	}
+	for _, r := range regLocalidades {
+		catalogo.Localidades = append(catalogo.Localidades, Localidad{
+			ID:             r.ID,
+			Nombre:         r.Nombre,
+			IDDepartamento: idReferencia(r.Departamento),
+			IDProvincia:    idReferencia(r.Provincia),
+		})
+	}
+
+	return catalogo, nil
+}
+
+func idReferencia(ref *referencia) string {
+	if ref == nil {
+		return ""
+	}
+	return strings.TrimSpace(ref.ID)
+}
+
+func leerFuente(fuente Fuente, entidad string) ([]registro, error) {
+	var registros []registro
+	var err error
+	switch strings.ToLower(filepath.Ext(fuente.Nombre)) {
+	case ".json":
+		registros, err = leerJSON(fuente.Datos, entidad)
+	case ".csv":
+		registros, err = leerCSV(fuente.Datos)
+	default:
+		return nil, fmt.Errorf("formato no soportado para %s: se esperaba .json o .csv", fuente.Nombre)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error leyendo archivo de %s: %w", entidad, err)
+	}
+
+	for i := range registros {
+		registros[i].ID = strings.TrimSpace(registros[i].ID)
+		registros[i].Nombre = strings.TrimSpace(registros[i].Nombre)
+	}
+	return registros, nil
+}
+
+// leerJSON acepta tanto un arreglo de registros como un objeto con la clave
+// de la entidad (formato de las descargas y de la API de Georef)
+func leerJSON(r io.Reader, entidad string) ([]registro, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var registros []registro
+	if err := json.Unmarshal(data, &registros); err == nil {
+		return registros, nil
+	}
+
+	var envoltorio map[string]json.RawMessage
+	if err := json.Unmarshal(data, &envoltorio); err != nil {
+		return nil, err
+	}
+	contenido, ok := envoltorio[entidad]
+	if !ok {
+		return nil, fmt.Errorf("no se encontró la clave %q", entidad)
+	}
+	if err := json.Unmarshal(contenido, &registros); err != nil {
+		return nil, err
+	}
+	return registros, nil
+}
+
+// leerCSV interpreta los CSV oficiales usando las columnas id, nombre,
+// provincia_id y departamento_id; el resto de las columnas se ignora
+func leerCSV(r io.Reader) ([]registro, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	encabezado, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columnas := make(map[string]int)
+	for i, col := range encabezado {
+		columnas[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(col, "\ufeff")))] = i
+	}
+	if _, ok := columnas["id"]; !ok {
+		return nil, fmt.Errorf("falta la columna id")
+	}
+	if _, ok := columnas["nombre"]; !ok {
+		return nil, fmt.Errorf("falta la columna nombre")
+	}
+
+	valor := func(fila []string, col string) string {
+		i, ok := columnas[col]
+		if !ok || i >= len(fila) {
+			return ""
+		}
+		return fila[i]
+	}
+
+	var registros []registro
+	for {
+		fila, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		reg := registro{ID: valor(fila, "id"), Nombre: valor(fila, "nombre")}
+		if id := valor(fila, "provincia_id"); id != "" {
+			reg.Provincia = &referencia{ID: id}
+		}
+		if id := valor(fila, "departamento_id"); id != "" {
+			reg.Departamento = &referencia{ID: id}
+		}
+		registros = append(registros, reg)
+	}
+
+	return registros, nil
+}
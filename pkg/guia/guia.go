@@ -0,0 +1,160 @@
+package guia
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Documento es el cuestionario completo de la guía en un formato portable, pensado
+// para ser revisado por el comité técnico. No contiene IDs de base de datos:
+// capítulos e indicadores se identifican por su clave y los segmentos por su nombre.
+// El orden de capítulos, indicadores y niveles de respuesta es el de las listas.
+type Documento struct {
+	Version               int               `json:"version,omitempty" yaml:"version,omitempty"` // número de la versión exportada (informativo)
+	Descripcion           string            `json:"descripcion,omitempty" yaml:"descripcion,omitempty"`
+	EstrategiaPuntaje     string            `json:"estrategia_puntaje,omitempty" yaml:"estrategia_puntaje,omitempty"` // SUMA (por defecto) o PONDERADA
+	Capitulos             []Capitulo        `json:"capitulos" yaml:"capitulos"`
+	NivelesSostenibilidad []NivelesSegmento `json:"niveles_sostenibilidad" yaml:"niveles_sostenibilidad"`
+}
+
+// Los pesos omitidos valen 1
+type Capitulo struct {
+	Clave       string      `json:"clave" yaml:"clave"`
+	Nombre      string      `json:"nombre" yaml:"nombre"`
+	Descripcion string      `json:"descripcion" yaml:"descripcion"`
+	Peso        float64     `json:"peso,omitempty" yaml:"peso,omitempty"`
+	Indicadores []Indicador `json:"indicadores" yaml:"indicadores"`
+}
+
+type Indicador struct {
+	Clave            string           `json:"clave" yaml:"clave"`
+	Nombre           string           `json:"nombre" yaml:"nombre"`
+	Descripcion      string           `json:"descripcion" yaml:"descripcion"`
+	Peso             float64          `json:"peso,omitempty" yaml:"peso,omitempty"`
+	Segmentos        []string         `json:"segmentos" yaml:"segmentos"`
+	NivelesRespuesta []NivelRespuesta `json:"niveles_respuesta" yaml:"niveles_respuesta"`
+}
+
+type NivelRespuesta struct {
+	Nombre      string `json:"nombre" yaml:"nombre"`
+	Descripcion string `json:"descripcion" yaml:"descripcion"`
+	Puntos      int    `json:"puntos" yaml:"puntos"`
+}
+
+// NivelesSegmento son los rangos de puntaje de los niveles de sostenibilidad de un segmento
+type NivelesSegmento struct {
+	Segmento string                `json:"segmento" yaml:"segmento"`
+	Niveles  []NivelSostenibilidad `json:"niveles" yaml:"niveles"`
+}
+
+// MesesVigencia omitido usa la vigencia por defecto del sistema
+type NivelSostenibilidad struct {
+	Nombre        string              `json:"nombre" yaml:"nombre"`
+	MinPuntaje    int                 `json:"min_puntaje" yaml:"min_puntaje"`
+	MaxPuntaje    int                 `json:"max_puntaje" yaml:"max_puntaje"`
+	MesesVigencia int                 `json:"meses_vigencia,omitempty" yaml:"meses_vigencia,omitempty"`
+	Requisitos    []RequisitoCapitulo `json:"requisitos,omitempty" yaml:"requisitos,omitempty"`
+}
+
+// RequisitoCapitulo exige un porcentaje mínimo en el capítulo (por clave) para alcanzar el nivel
+type RequisitoCapitulo struct {
+	Capitulo      string  `json:"capitulo" yaml:"capitulo"`
+	MinPorcentaje float64 `json:"min_porcentaje" yaml:"min_porcentaje"`
+}
+
+type Formato string
+
+const (
+	FormatoJSON Formato = "json"
+	FormatoYAML Formato = "yaml"
+)
+
+// ParseFormato interpreta el nombre de un formato ("json", "yaml" o "yml")
+func ParseFormato(s string) (Formato, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "json":
+		return FormatoJSON, nil
+	case "yaml", "yml":
+		return FormatoYAML, nil
+	default:
+		return "", fmt.Errorf("formato no soportado %q: se esperaba json o yaml", s)
+	}
+}
+
+// FormatoDeArchivo determina el formato a partir de la extensión del archivo
+func FormatoDeArchivo(nombre string) (Formato, error) {
+	return ParseFormato(strings.TrimPrefix(filepath.Ext(nombre), "."))
+}
+
+// ContentType devuelve el tipo MIME del formato
+func (f Formato) ContentType() string {
+	if f == FormatoYAML {
+		return "application/yaml"
+	}
+	return "application/json"
+}
+
+// LeerArchivo lee un documento de la guía; el formato se determina por la extensión
+func LeerArchivo(path string) (*Documento, error) {
+	formato, err := FormatoDeArchivo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error abriendo archivo %s: %w", path, err)
+	}
+	defer f.Close()
+
+	return Leer(f, formato)
+}
+
+// Leer interpreta un documento de la guía. Los campos desconocidos se rechazan para
+// detectar errores de tipeo en documentos editados a mano.
+func Leer(r io.Reader, formato Formato) (*Documento, error) {
+	doc := &Documento{}
+	switch formato {
+	case FormatoJSON:
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(doc); err != nil {
+			return nil, fmt.Errorf("error leyendo documento JSON: %w", err)
+		}
+	case FormatoYAML:
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(doc); err != nil {
+			return nil, fmt.Errorf("error leyendo documento YAML: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("formato no soportado %q", formato)
+	}
+
+	return doc, nil
+}
+
+// Escribir serializa el documento en el formato indicado
+func Escribir(w io.Writer, doc *Documento, formato Formato) error {
+	switch formato {
+	case FormatoJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(doc)
+	case FormatoYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(doc); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("formato no soportado %q", formato)
+	}
+}
@@ -0,0 +1,71 @@
+package httputil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// JSONCacheable es una respuesta JSON ya serializada junto con su ETag, para los datos que
+// cambian tan poco que conviene calcularlos una sola vez y no en cada pedido
+type JSONCacheable struct {
+	cuerpo []byte
+	etag   string
+}
+
+// NuevoJSONCacheable serializa los datos y calcula su ETag fuerte
+func NuevoJSONCacheable(data interface{}) (*JSONCacheable, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	body = append(body, '\n')
+
+	sum := sha256.Sum256(body)
+	return &JSONCacheable{cuerpo: body, etag: `"` + hex.EncodeToString(sum[:16]) + `"`}, nil
+}
+
+// RespondJSONCacheable envía una respuesta JSON con ETag fuerte y Cache-Control.
+// Si el cliente envía If-None-Match con el mismo ETag responde 304 sin cuerpo.
+func RespondJSONCacheable(w http.ResponseWriter, r *http.Request, data interface{}, maxAgeSeconds int) {
+	cacheable, err := NuevoJSONCacheable(data)
+	if err != nil {
+		RespondError(w, http.StatusInternalServerError, "error interno del servidor")
+		return
+	}
+	RespondCacheable(w, r, cacheable, maxAgeSeconds)
+}
+
+// RespondCacheable envía una respuesta ya serializada con su ETag y Cache-Control, o 304
+// si el cliente ya la tiene
+func RespondCacheable(w http.ResponseWriter, r *http.Request, cacheable *JSONCacheable, maxAgeSeconds int) {
+	w.Header().Set("ETag", cacheable.etag)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, must-revalidate", maxAgeSeconds))
+
+	if etagCoincide(r.Header.Get("If-None-Match"), cacheable.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(cacheable.cuerpo)
+}
+
+// etagCoincide evalúa un encabezado If-None-Match (lista de ETags o "*").
+// Para GET la comparación es débil, por eso se ignora el prefijo W/.
+func etagCoincide(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidato := range strings.Split(ifNoneMatch, ",") {
+		candidato = strings.TrimPrefix(strings.TrimSpace(candidato), "W/")
+		if candidato == "*" || candidato == etag {
+			return true
+		}
+	}
+	return false
+}
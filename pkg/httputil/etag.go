@@ -0,0 +1,33 @@
+package httputil
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"coviar_backend/internal/domain"
+	"coviar_backend/pkg/validator"
+)
+
+// SetETag expone la versión de un recurso en el encabezado ETag
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// VersionIfMatch lee la versión enviada en el encabezado If-Match. "*" acepta cualquier
+// versión y devuelve nil; sin encabezado devuelve ErrVersionRequerida.
+func VersionIfMatch(r *http.Request) (*int, error) {
+	valor := strings.TrimSpace(r.Header.Get("If-Match"))
+	if valor == "" {
+		return nil, domain.ErrVersionRequerida
+	}
+	if valor == "*" {
+		return nil, nil
+	}
+
+	version, err := strconv.Atoi(strings.Trim(valor, `"`))
+	if err != nil || version < 1 {
+		return nil, validator.ValidationErrors{{Field: "If-Match", Message: "debe contener el ETag de la autoevaluación"}}
+	}
+	return &version, nil
+}
@@ -52,6 +52,34 @@ func HandleServiceError(w http.ResponseWriter, err error) {
 		return
 	}
 
+	var erroresRespuestas domain.ErroresRespuestas
+	if errors.As(err, &erroresRespuestas) {
+		RespondJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+			Error:   "respuestas inválidas",
+			Details: erroresRespuestas,
+		})
+		return
+	}
+
+	var desactualizada *domain.AutoevaluacionDesactualizada
+	if errors.As(err, &desactualizada) {
+		SetETag(w, desactualizada.Actual.Version)
+		RespondJSON(w, http.StatusPreconditionFailed, ErrorResponse{
+			Error:   desactualizada.Error(),
+			Details: desactualizada.Actual,
+		})
+		return
+	}
+
+	var cambioSegmento *domain.CambioSegmentoSinConfirmar
+	if errors.As(err, &cambioSegmento) {
+		RespondJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "el cambio de segmento requiere confirmación",
+			Details: cambioSegmento.Cambio,
+		})
+		return
+	}
+
 	// Errores de dominio
 	switch {
 	case errors.Is(err, domain.ErrNotFound):
@@ -66,6 +94,28 @@ func HandleServiceError(w http.ResponseWriter, err error) {
 		RespondError(w, http.StatusConflict, err.Error())
 	case errors.Is(err, domain.ErrResponsableYaDadoDeBaja):
 		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrAutoevaluacionNoCompletada):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrGuiaBorradorExistente):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrGuiaVersionNoEditable):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrAutoevaluacionNoPendiente):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrBodegaConPendiente):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrAuditorNoAsignado):
+		RespondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrRevisionNoDisponible):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrRevisionNoEnCurso):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrRevisionDeOtroAuditor):
+		RespondError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, domain.ErrAutoevaluacionNoReabrible):
+		RespondError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrVersionRequerida):
+		RespondError(w, http.StatusPreconditionRequired, err.Error())
 	case errors.Is(err, domain.ErrValidation):
 		RespondError(w, http.StatusBadRequest, "error de validación")
 	case errors.Is(err, domain.ErrInvalidCredentials):
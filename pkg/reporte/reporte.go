@@ -0,0 +1,220 @@
+package reporte
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"coviar_backend/internal/domain"
+)
+
+// Datos reúne la información necesaria para generar el reporte y el certificado
+type Datos struct {
+	Bodega     *domain.Bodega
+	Detalle    *domain.AutoevaluacionDetalle
+	Resultados *domain.ResultadosAutoevaluacion
+}
+
+const (
+	margen    = 15.0
+	altoLinea = 6.0
+)
+
+// Colores institucionales (bordó)
+var colorPrimario = [3]int{114, 28, 36}
+
+// documento envuelve gofpdf con la traducción de UTF-8 a la codificación de las fuentes base
+type documento struct {
+	pdf *gofpdf.Fpdf
+	tr  func(string) string
+}
+
+func nuevoDocumento(orientacion, titulo string) *documento {
+	pdf := gofpdf.New(orientacion, "mm", "A4", "")
+	pdf.SetMargins(margen, margen, margen)
+	pdf.SetAutoPageBreak(true, margen)
+	pdf.SetTitle(titulo, true)
+	pdf.SetAuthor("Coviar", true)
+	return &documento{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+}
+
+func (d *documento) texto(w, h float64, txt, borde string, ln int, alineacion string, relleno bool) {
+	d.pdf.CellFormat(w, h, d.tr(txt), borde, ln, alineacion, relleno, 0, "")
+}
+
+func (d *documento) parrafo(w, h float64, txt, alineacion string) {
+	d.pdf.MultiCell(w, h, d.tr(txt), "", alineacion, false)
+}
+
+func (d *documento) titulo(txt string) {
+	d.pdf.SetFont("Helvetica", "B", 13)
+	d.pdf.SetTextColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	d.texto(0, 9, txt, "", 1, "L", false)
+	d.pdf.SetTextColor(0, 0, 0)
+}
+
+func (d *documento) campo(etiqueta, valor string) {
+	d.pdf.SetFont("Helvetica", "B", 10)
+	d.texto(50, altoLinea, etiqueta, "", 0, "L", false)
+	d.pdf.SetFont("Helvetica", "", 10)
+	d.parrafo(0, altoLinea, valor, "L")
+}
+
+func (d *documento) escribir(w io.Writer) error {
+	if err := d.pdf.Error(); err != nil {
+		return fmt.Errorf("error generando PDF: %w", err)
+	}
+	return d.pdf.Output(w)
+}
+
+// GenerarReporte escribe el reporte completo de resultados: datos de la bodega,
+// resumen de puntaje y nivel, desglose por capítulo y respuestas con sus notas
+func GenerarReporte(w io.Writer, datos *Datos) error {
+	d := nuevoDocumento("P", "Reporte de autoevaluación")
+	pdf := d.pdf
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(120, 120, 120)
+		d.texto(0, 5, fmt.Sprintf("Autoevaluación N° %d - Página %d de {nb}", datos.Detalle.ID, pdf.PageNo()), "", 0, "C", false)
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetTextColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	d.texto(0, 10, "Reporte de Autoevaluación de Sostenibilidad", "", 1, "C", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	d.titulo("Bodega")
+	d.campo("Razón social:", datos.Bodega.RazonSocial)
+	d.campo("Nombre de fantasía:", datos.Bodega.NombreFantasia)
+	d.campo("CUIT:", datos.Bodega.CUIT)
+	d.campo("Dirección:", direccionBodega(datos.Bodega))
+	pdf.Ln(3)
+
+	d.titulo("Resultado")
+	d.campo("Segmento:", valorOGuion(datos.Detalle.Segmento))
+	d.campo("Fecha de inicio:", formatearFecha(&datos.Detalle.FechaInicio))
+	d.campo("Fecha de finalización:", formatearFecha(datos.Detalle.FechaFin))
+	d.campo("Puntaje:", fmt.Sprintf("%d de %d (%.2f%%)",
+		datos.Resultados.PuntajeTotal, datos.Resultados.PuntajeMaximo, datos.Resultados.Porcentaje))
+	d.campo("Nivel de sostenibilidad:", valorOGuion(datos.Detalle.NivelSostenibilidad))
+	pdf.Ln(3)
+
+	d.titulo("Puntaje por capítulo")
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetFillColor(235, 225, 226)
+	d.texto(110, 7, "Capítulo", "1", 0, "L", true)
+	d.texto(25, 7, "Obtenido", "1", 0, "C", true)
+	d.texto(25, 7, "Máximo", "1", 0, "C", true)
+	d.texto(20, 7, "%", "1", 1, "C", true)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, c := range datos.Resultados.Capitulos {
+		d.texto(110, 7, c.Capitulo, "1", 0, "L", false)
+		d.texto(25, 7, fmt.Sprintf("%d", c.PuntosObtenidos), "1", 0, "C", false)
+		d.texto(25, 7, fmt.Sprintf("%d", c.PuntosMaximos), "1", 0, "C", false)
+		d.texto(20, 7, fmt.Sprintf("%.1f", c.Porcentaje), "1", 1, "C", false)
+	}
+	pdf.Ln(4)
+
+	d.titulo("Respuestas")
+	capituloActual := -1
+	for _, r := range datos.Detalle.Respuestas {
+		if r.IDCapitulo != capituloActual {
+			capituloActual = r.IDCapitulo
+			pdf.Ln(2)
+			pdf.SetFont("Helvetica", "B", 11)
+			pdf.SetFillColor(235, 225, 226)
+			d.texto(0, 7, r.Capitulo, "", 1, "L", true)
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		d.parrafo(0, 5, r.Indicador, "L")
+		pdf.SetFont("Helvetica", "", 9)
+		d.parrafo(0, 5, fmt.Sprintf("Respuesta: %s (%d puntos)", r.NivelRespuesta, r.Puntos), "L")
+		if r.Nota != nil {
+			pdf.SetFont("Helvetica", "I", 9)
+			d.parrafo(0, 5, "Nota: "+*r.Nota, "L")
+		}
+		pdf.Ln(1)
+	}
+
+	return d.escribir(w)
+}
+
+// GenerarCertificado escribe un certificado de una página con el nivel alcanzado
+func GenerarCertificado(w io.Writer, datos *Datos) error {
+	d := nuevoDocumento("L", "Certificado de autoevaluación")
+	pdf := d.pdf
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	ancho, alto := pdf.GetPageSize()
+	pdf.SetDrawColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(10, 10, ancho-20, alto-20, "D")
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(14, 14, ancho-28, alto-28, "D")
+
+	anchoTexto := ancho - 2*margen
+	pdf.SetY(38)
+	pdf.SetFont("Helvetica", "B", 30)
+	pdf.SetTextColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	d.texto(anchoTexto, 14, "CERTIFICADO", "", 1, "C", false)
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.SetTextColor(60, 60, 60)
+	d.texto(anchoTexto, 8, "de Autoevaluación de Sostenibilidad Enoturística", "", 1, "C", false)
+	pdf.Ln(12)
+
+	pdf.SetFont("Helvetica", "", 13)
+	pdf.SetTextColor(0, 0, 0)
+	d.texto(anchoTexto, 8, "Se certifica que la bodega", "", 1, "C", false)
+	pdf.SetFont("Helvetica", "B", 22)
+	d.texto(anchoTexto, 12, datos.Bodega.NombreFantasia, "", 1, "C", false)
+	pdf.SetFont("Helvetica", "", 11)
+	d.texto(anchoTexto, 6, fmt.Sprintf("%s - CUIT %s", datos.Bodega.RazonSocial, datos.Bodega.CUIT), "", 1, "C", false)
+	pdf.Ln(8)
+
+	pdf.SetFont("Helvetica", "", 13)
+	d.texto(anchoTexto, 8, fmt.Sprintf("completó la autoevaluación para el segmento %s alcanzando el nivel", valorOGuion(datos.Detalle.Segmento)), "", 1, "C", false)
+	pdf.SetFont("Helvetica", "B", 24)
+	pdf.SetTextColor(colorPrimario[0], colorPrimario[1], colorPrimario[2])
+	d.texto(anchoTexto, 14, valorOGuion(datos.Detalle.NivelSostenibilidad), "", 1, "C", false)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "", 13)
+	d.texto(anchoTexto, 8, fmt.Sprintf("con un puntaje de %d sobre %d puntos.",
+		datos.Resultados.PuntajeTotal, datos.Resultados.PuntajeMaximo), "", 1, "C", false)
+
+	pdf.SetY(alto - 42)
+	pdf.SetFont("Helvetica", "", 11)
+	d.texto(anchoTexto, 6, "Fecha: "+formatearFecha(datos.Detalle.FechaFin), "", 1, "C", false)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.SetTextColor(120, 120, 120)
+	d.texto(anchoTexto, 6, fmt.Sprintf("Autoevaluación N° %d", datos.Detalle.ID), "", 1, "C", false)
+
+	return d.escribir(w)
+}
+
+func direccionBodega(b *domain.Bodega) string {
+	if b.Direccion != "" {
+		return b.Direccion
+	}
+	return b.Calle + " " + b.Numeracion
+}
+
+func valorOGuion(s *string) string {
+	if s == nil || *s == "" {
+		return "-"
+	}
+	return *s
+}
+
+func formatearFecha(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format("02/01/2006")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 )
 
 type ValidationError struct {
@@ -127,3 +128,24 @@ func NormalizarPuntero(s *string) *string {
 	normalizado := NormalizarTexto(*s)
 	return &normalizado
 }
+
+// LimpiarTextoLibre sanea un texto ingresado libremente: descarta los bytes que no son
+// UTF-8 válido y los caracteres de control (salvo saltos de línea y tabulaciones),
+// unifica los saltos de línea y quita los espacios de los extremos. No cambia mayúsculas.
+func LimpiarTextoLibre(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return r
+		}
+		if r == '\r' {
+			return '\n'
+		}
+		if unicode.IsControl(r) || r == '\u2028' || r == '\u2029' {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.TrimSpace(s)
+}